@@ -0,0 +1,29 @@
+//go:build binary
+// +build binary
+
+#include <gio/gio.h>
+#include "_cgo_export.h"
+
+// The files are given to Wahay as paths, or as URIs when
+// they don't have one, like the wahay: invitation links
+static void onOpen(GApplication *app, GFile **files, gint count, gchar *hint, gpointer data) {
+	char **invitations = g_new0(char *, count);
+
+	for (gint i = 0; i < count; i++) {
+		invitations[i] = g_file_get_path(files[i]);
+		if (invitations[i] == NULL) {
+			invitations[i] = g_file_get_uri(files[i]);
+		}
+	}
+
+	applicationOpened(invitations, count);
+
+	for (gint i = 0; i < count; i++) {
+		g_free(invitations[i]);
+	}
+	g_free(invitations);
+}
+
+void connectApplicationOpen(uintptr_t app) {
+	g_signal_connect((gpointer) app, "open", G_CALLBACK(onOpen), NULL);
+}
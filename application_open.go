@@ -0,0 +1,42 @@
+//go:build binary
+// +build binary
+
+package main
+
+// #cgo pkg-config: gio-2.0
+// #include <stdint.h>
+//
+// void connectApplicationOpen(uintptr_t app);
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/coyim/gotk3adapter/gtka"
+	"github.com/coyim/gotk3adapter/gtki"
+)
+
+// onApplicationOpen receives the invitations given to any process of
+// Wahay, since the open signal is only emitted in the primary instance
+var onApplicationOpen func([]string)
+
+//export applicationOpened
+func applicationOpened(invitations **C.char, count C.int) {
+	if onApplicationOpen == nil {
+		return
+	}
+
+	result := make([]string, 0, int(count))
+	for _, i := range unsafe.Slice(invitations, int(count)) {
+		result = append(result, C.GoString(i))
+	}
+
+	onApplicationOpen(result)
+}
+
+// connectApplicationOpen connects the open signal of the application,
+// which the adapters can't marshal since it receives an array of files
+func connectApplicationOpen(app gtki.Application, onOpen func([]string)) {
+	onApplicationOpen = onOpen
+	C.connectApplicationOpen(C.uintptr_t(gtka.UnwrapApplication(app).Native()))
+}
@@ -1,6 +1,9 @@
 package client
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 
@@ -9,51 +12,188 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/big"
 	"net"
 	"net/url"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	localExec "github.com/digitalautonomy/wahay/exec"
+	"github.com/digitalautonomy/wahay/hosting"
 	log "github.com/sirupsen/logrus"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 const certServerPort = 8181
 
-func (c *client) requestCertificate() error {
+func meetingMetadataURL(onion string) string {
+	q := url.Values{}
+	q.Set(hosting.MeetingMetadataVersionParameter, strconv.Itoa(hosting.MeetingMetadataVersion))
+
 	u := &url.URL{
-		Scheme: "http",
-		Host:   net.JoinHostPort(c.f.OnionAddr, strconv.Itoa(certServerPort)),
+		Scheme:   "http",
+		Host:     net.JoinHostPort(onion, strconv.Itoa(certServerPort)),
+		Path:     hosting.MeetingMetadataPath,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
+
+// parseMeetingMetadata understands both the JSON meeting metadata and the bare
+// PEM certificate served by hosts running versions of Wahay without metadata support
+func parseMeetingMetadata(content string) (*hosting.MeetingMetadata, error) {
+	m := &hosting.MeetingMetadata{}
+	err := json.Unmarshal([]byte(content), m)
+	if err == nil {
+		return m, nil
+	}
+
+	fingerprint, err := hosting.CertificateFingerprint([]byte(content))
+	if err != nil {
+		return nil, errInvalidMeetingMetadata
+	}
+
+	log.Debug("parseMeetingMetadata(): the host only provides its certificate")
+
+	return &hosting.MeetingMetadata{
+		Version:     hosting.MeetingMetadataVersion,
+		Certificate: content,
+		Fingerprint: fingerprint,
+	}, nil
+}
+
+var errInvalidMeetingMetadata = errors.New("invalid meeting metadata")
+
+func (c *client) requestMeetingMetadata(onion string) (*hosting.MeetingMetadata, error) {
+	c.metadataLock.Lock()
+	defer c.metadataLock.Unlock()
+
+	if m, ok := c.metadata[onion]; ok {
+		return m, nil
+	}
+
+	content, err := c.tor.HTTPrequest(meetingMetadataURL(onion))
+	if err != nil {
+		return nil, err
 	}
 
-	content, err := c.tor.HTTPrequest(u.String())
+	m, err := parseMeetingMetadata(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.metadata == nil {
+		c.metadata = map[string]*hosting.MeetingMetadata{}
+	}
+	c.metadata[onion] = m
+
+	return m, nil
+}
+
+// ErrCertificateMismatch is an error to return when the certificate served by
+// the meeting host is not the one included in the invitation
+var ErrCertificateMismatch = errors.New("the certificate of the meeting server doesn't match the one in the invitation")
+
+func verifyPinnedCertificate(m *hosting.MeetingMetadata, fingerprint string) error {
+	if len(fingerprint) == 0 {
+		return nil
+	}
+
+	// We don't trust the fingerprint announced in the metadata,
+	// it must be calculated from the served certificate
+	actual, err := hosting.CertificateFingerprint([]byte(m.Certificate))
+	if err != nil || !strings.EqualFold(actual, fingerprint) {
+		log.WithFields(log.Fields{
+			"expected": fingerprint,
+			"actual":   actual,
+		}).Error("verifyPinnedCertificate(): the meeting certificate doesn't match the pinned one")
+		return ErrCertificateMismatch
+	}
+
+	return nil
+}
+
+// verifyMeeting checks that the meeting server is the one the participant has been invited to
+func verifyMeeting(m *hosting.MeetingMetadata, data hosting.MeetingData) error {
+	err := verifyPinnedCertificate(m, data.Fingerprint)
 	if err != nil {
 		return err
 	}
 
-	cert := []byte(content)
+	if data.Invitation != nil && len(data.Invitation.Signature) != 0 {
+		err = data.Invitation.VerifySignature([]byte(m.Certificate))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"meetingID": data.MeetingID,
+			}).Errorf("verifyMeeting(): %s", err)
+			return hosting.ErrInvalidInvitationSignature
+		}
+	}
+
+	return nil
+}
+
+func (c *client) requestCertificate(data hosting.MeetingData) error {
+	fingerprint := data.Fingerprint
+
+	m, err := c.requestMeetingMetadata(c.f.OnionAddr)
+	if err != nil {
+		if len(fingerprint) == 0 {
+			return err
+		}
+
+		// We can't reach the certificate server but we already know
+		// which certificate the Mumble server should be using
+		log.WithFields(log.Fields{"url": c.f.OnionAddr}).Warnf("requestCertificate(): using the pinned certificate: %s", err)
+		err = c.storePinnedCertificate(c.f.LocalAddr, c.f.ListeningPort, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		c.fingerprint = strings.ToLower(fingerprint)
+		return nil
+	}
+
+	err = m.CheckCompatibility()
+	if err != nil {
+		return err
+	}
+
+	err = verifyMeeting(m, data)
+	if err != nil {
+		return err
+	}
+
+	cert := []byte(m.Certificate)
 	err = c.storeCertificate(c.f.LocalAddr, c.f.ListeningPort, cert)
 	if err != nil {
 		return err
 	}
 
-	return c.saveCertificateConfigFile()
+	err = c.saveCertificateConfigFile()
+	if err != nil {
+		return err
+	}
+
+	c.fingerprint, _ = hosting.CertificateFingerprint(cert)
+	return nil
 }
 
-func (c *client) storeCertificate(hostname string, port int, cert []byte) error {
-	if c.isTheCertificateInDB(hostname) {
-		return nil
+func (c *client) storePinnedCertificate(hostname string, port int, fingerprint string) error {
+	err := c.storeCertificateInDB(hostname, port, strings.ToLower(fingerprint))
+	if err != nil {
+		return err
 	}
 
+	return c.saveCertificateConfigFile()
+}
+
+func (c *client) storeCertificate(hostname string, port int, cert []byte) error {
 	block, _ := pem.Decode(cert)
 	if block == nil || block.Type != "CERTIFICATE" {
 		return errors.New("invalid certificate")
@@ -73,41 +213,26 @@ func (c *client) storeCertificate(hostname string, port int, cert []byte) error
 	return c.storeCertificateInDB(hostname, port, digest)
 }
 
-const (
-	defaultHostToReplace   = "111.1.1.1"
-	defaultPortToReplace   = 64738
-	defaultDigestToReplace = "AAABACADAFBABBBCBDBEBFCACBCCCDCECFDADBDC"
-)
-
-func (c *client) storeCertificateInDB(id string, port int, digest string) error {
+func (c *client) storeCertificateInDB(hostname string, port int, digest string) error {
 	db, err := c.db()
 	if err != nil {
 		return err
 	}
+	defer closeAndIgnore(db)
 
-	log.WithFields(log.Fields{
-		"defaultHost":   defaultHostToReplace,
-		"defaultPort":   defaultPortToReplace,
-		"defaultDigest": defaultDigestToReplace,
-		"newHost":       id,
-		"newPort":       port,
-		"newDigest":     digest,
-	}).Debug("Replacing content in Mumble sqlite database")
-
-	db.replaceString(defaultHostToReplace, id)
-	db.replaceString(defaultDigestToReplace, digest)
-	db.replaceInteger(uint16(defaultPortToReplace), uint16(port))
-
-	return db.write()
-}
-
-func (c *client) isTheCertificateInDB(hostname string) bool {
-	d, err := c.db()
-	if err != nil {
-		return false
+	stored, err := db.certificateDigest(hostname, port)
+	if err != nil || stored == digest {
+		return err
 	}
 
-	return d.exists(hostname)
+	log.WithFields(log.Fields{
+		"hostname":       hostname,
+		"port":           port,
+		"digest":         digest,
+		"previousDigest": stored,
+	}).Debug("Storing the certificate in Mumble sqlite database")
+
+	return db.storeCertificate(hostname, port, digest)
 }
 
 func digestForCertificate(cert []byte) (string, error) {
@@ -123,8 +248,58 @@ func digestForCertificate(cert []byte) (string, error) {
 	return fmt.Sprintf("%x", bs), nil
 }
 
-// openssl req -newkey rsa:2048 -nodes -keyout key.pem -x509 -days 365 -out certificate.pem
-func genCertInto(certFilename, keyFilename string) error {
+// certificateKey describes the key of the certificates
+// we generate for the Mumble client
+type certificateKey struct {
+	algorithm string
+	// bits is the size of the key for RSA, and the size of the curve for ECDSA
+	bits int
+}
+
+const (
+	keyAlgorithmRSA   = "RSA"
+	keyAlgorithmECDSA = "ECDSA"
+)
+
+// mumbleCertificateKey is the key of the certificate of the Mumble
+// client. RSA is the kind of key supported by every Mumble version
+var mumbleCertificateKey = certificateKey{algorithm: keyAlgorithmRSA, bits: 2048}
+
+var errUnsupportedCertificateKey = errors.New("unsupported key for the certificate")
+
+func (k certificateKey) generate() (crypto.Signer, error) {
+	switch k.algorithm {
+	case keyAlgorithmRSA:
+		if k.bits < 2048 {
+			return nil, errUnsupportedCertificateKey
+		}
+		return rsa.GenerateKey(rand.Reader, k.bits)
+	case keyAlgorithmECDSA:
+		switch k.bits {
+		case 256:
+			return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		case 384:
+			return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		case 521:
+			return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		}
+	}
+
+	return nil, errUnsupportedCertificateKey
+}
+
+// generateCertificate creates a self-signed certificate with a new key of the given kind
+func generateCertificate(k certificateKey) (*x509.Certificate, crypto.Signer, error) {
+	priv, err := k.generate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyUsage := x509.KeyUsageDigitalSignature
+	if k.algorithm == keyAlgorithmRSA {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
 	now := time.Now()
 	tmpl := &x509.Certificate{
 		SerialNumber: big.NewInt(0),
@@ -135,86 +310,39 @@ func genCertInto(certFilename, keyFilename string) error {
 		NotAfter:  now.Add(24 * time.Hour * 365),
 
 		SubjectKeyId: []byte{1, 2, 3, 4},
-		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		KeyUsage:     keyUsage,
 	}
 
-	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	certbuf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, priv.Public(), priv)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	certbuf, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	cert, err := x509.ParseCertificate(certbuf)
 	if err != nil {
-		return err
-	}
-	certblk := pem.Block{
-		Type:  "CERTIFICATE",
-		Bytes: certbuf,
-	}
-
-	keybuf := x509.MarshalPKCS1PrivateKey(priv)
-	keyblk := pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: keybuf,
+		return nil, nil, err
 	}
 
-	file, err := os.OpenFile(certFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
-	if err != nil {
-		return err
-	}
-	defer closeAndIgnore(file)
-	err = pem.Encode(file, &certblk)
-	if err != nil {
-		return err
-	}
+	return cert, priv, nil
+}
 
-	file, err = os.OpenFile(keyFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+// generateCertificateBundle will generate a certificate and private key
+// for the Mumble client and then format that in PKCS12
+func generateCertificateBundle() ([]byte, error) {
+	cert, priv, err := generateCertificate(mumbleCertificateKey)
 	if err != nil {
-		return err
-	}
-	defer closeAndIgnore(file)
-	err = pem.Encode(file, &keyblk)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	// Mumble imports the certificate without a password, and the modern
+	// encryption is the one used by default by OpenSSL 3 for PKCS12
+	return pkcs12.Modern.Encode(priv, cert, nil, "")
 }
 
-var ioutilTempDir = ioutil.TempDir
-var cmd exec.Cmd
-var cmdOutput = cmd.Output
-var osReadFile = os.ReadFile
-
-// generateTemporaryMumbleCertificate will generate a certificate and private key and
-// then format that in PKCS12, finally formatting it in the @ByteArray format that
-// Mumble configuration files use
-// This will fail if OpenSSL is not installed on the system.
+// generateTemporaryMumbleCertificate will generate a certificate bundle and
+// then format it in the @ByteArray format that Mumble configuration files use
 func generateTemporaryMumbleCertificate() (string, error) {
-	dir, err := ioutilTempDir("", "wahay_cert_generation")
-	if err != nil {
-		return "", err
-	}
-	defer os.RemoveAll(dir)
-
-	err = genCertInto(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
-	if err != nil {
-		return "", err
-	}
-
-	args := []string{"pkcs12", "-passout", "pass:", "-inkey", filepath.Join(dir, "key.pem"),
-		"-in", filepath.Join(dir, "cert.pem"), "-export", "-out", filepath.Join(dir, "transformed.p12")}
-	// This executes the openssl command. The args are completely under our control
-	/* #nosec G204 */
-	cmd = *execCommand("openssl", args...)
-	localExec.HideCommandWindow(&cmd)
-
-	_, err = cmdOutput()
-	if err != nil {
-		return "", err
-	}
-
-	data, err := osReadFile(filepath.Clean(filepath.Join(dir, "transformed.p12")))
+	data, err := generateCertificateBundle()
 	if err != nil {
 		return "", err
 	}
@@ -334,3 +462,83 @@ func byteArrayUnparse(bs []byte) string {
 
 	return strings.Join(result, "")
 }
+
+var errInvalidByteArray = errors.New("invalid @ByteArray value")
+
+func byteArrayParseSpecial(b byte) (byte, bool) {
+	switch b {
+	case 't':
+		return '\t', true
+	case 'r':
+		return '\r', true
+	case 'a':
+		return '\a', true
+	case 'b':
+		return '\b', true
+	case 'v':
+		return '\v', true
+	case 'f':
+		return '\f', true
+	case 'n':
+		return '\n', true
+	case '0':
+		return 0, true
+	case '"':
+		return '"', true
+	case '\\':
+		return '\\', true
+	default:
+		return 0, false
+	}
+}
+
+// byteArrayParse returns the bytes represented by a value
+// in the @ByteArray format, as written by byteArrayUnparse
+func byteArrayParse(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, byteArrayPrefix) || !strings.HasSuffix(s, byteArraySuffix) {
+		return nil, errInvalidByteArray
+	}
+
+	in := s[len(byteArrayPrefix) : len(s)-len(byteArraySuffix)]
+	result := make([]byte, 0, len(in))
+
+	for i := 0; i < len(in); i++ {
+		if in[i] != '\\' {
+			result = append(result, in[i])
+			continue
+		}
+
+		i++
+		if i == len(in) {
+			return nil, errInvalidByteArray
+		}
+
+		if b, ok := byteArrayParseSpecial(in[i]); ok {
+			result = append(result, b)
+			continue
+		}
+
+		if in[i] != 'x' {
+			return nil, errInvalidByteArray
+		}
+
+		digits := 0
+		for digits < 2 && i+1+digits < len(in) && byteArrayIsHex(in[i+1+digits]) {
+			digits++
+		}
+
+		if digits == 0 {
+			return nil, errInvalidByteArray
+		}
+
+		v, err := strconv.ParseUint(in[i+1:i+1+digits], 16, 8)
+		if err != nil {
+			return nil, errInvalidByteArray
+		}
+
+		result = append(result, byte(v))
+		i += digits
+	}
+
+	return result, nil
+}
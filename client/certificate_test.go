@@ -1,12 +1,18 @@
 package client
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
-	"os/exec"
+	"path/filepath"
+	"strings"
 
-	"github.com/prashantv/gostub"
-	"github.com/stretchr/testify/mock"
+	"github.com/digitalautonomy/wahay/hosting"
 	. "gopkg.in/check.v1"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 func (s *clientSuite) Test_storeCertificate_returnsAnErrorWhenBadCertificateHasBeenGiven(c *C) {
@@ -26,99 +32,176 @@ func (s *clientSuite) Test_storeCertificate_returnsAnErrorWhenBadCertificateHasB
 }
 
 func (s *clientSuite) Test_storeCertificate_returnsNoErrorWhenSuccesfullyStoresCertificateInDB(c *C) {
-	var mumbleDBContent []byte
+	tempDir := createTempDir(c)
+	defer removeTempDir(c, tempDir)
 
-	d := func() []byte {
-		return mumbleDBContent
-	}
-
-	cl := client{
-		databaseProvider: d,
-	}
+	cl := createFakeClient(readerMumbleDB(), tempDir)
 
 	cert := []byte(fakeCert)
 	err := cl.storeCertificate("test", 123, cert)
 	c.Assert(err, IsNil)
-}
 
-func (s *clientSuite) Test_generateTemporaryMumbleCertificate_returnsCertificateSuccessfully(c *C) {
-	mc := &mockCommand{}
-	defer gostub.New().Stub(&cmdOutput, mc.Output).Reset()
-	mc.On("Command", "openssl", mock.Anything).Return(&exec.Cmd{}).Once()
-	defer gostub.New().Stub(&execCommand, mc.Command).Reset()
-	mc.On("Output").Return([]byte("command output"), nil).Once()
+	block, _ := pem.Decode(cert)
+	digest, _ := digestForCertificate(block.Bytes)
 
-	mrf := &mockReadFile{}
-	defer gostub.New().Stub(&osReadFile, mrf.ReadFile).Reset()
-	mrf.On("ReadFile", mock.Anything).Return([]byte("data content"), nil).Once()
+	rows := readTable(c, filepath.Join(tempDir, configDBName), "SELECT hostname, port, digest FROM cert")
+	c.Assert(rows, DeepEquals, [][]string{{"test", "123", digest}})
+}
 
+func (s *clientSuite) Test_generateTemporaryMumbleCertificate_returnsAPKCS12BundleInTheByteArrayFormat(c *C) {
 	data, err := generateTemporaryMumbleCertificate()
 	c.Assert(err, IsNil)
-	c.Assert(data, NotNil)
-	c.Assert(data, Matches, `@ByteArray\(data content\)`)
+	c.Assert(data, Matches, `@ByteArray\(.*\)`)
 
-	mc.AssertExpectations(c)
-	mrf.AssertExpectations(c)
+	bundle, err := byteArrayParse(data)
+	c.Assert(err, IsNil)
+
+	key, cert, err := pkcs12.Decode(bundle, "")
+	c.Assert(err, IsNil)
+	c.Assert(cert.Subject.CommonName, Equals, "Wahay Autogenerated Certificate")
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	c.Assert(ok, Equals, true)
+	c.Assert(rsaKey.N.BitLen(), Equals, 2048)
+	c.Assert(rsaKey.PublicKey.Equal(cert.PublicKey), Equals, true)
 }
 
-func (s *clientSuite) Test_generateTemporaryMumbleCertificate_returnsAnErrorWhenFailsCreatingTempDir(c *C) {
-	mtd := &mockTempDir{}
-	defer gostub.New().Stub(&ioutilTempDir, mtd.tempDir).Reset()
+func (s *clientSuite) Test_generateCertificate_usesTheGivenKind(c *C) {
+	cert, key, err := generateCertificate(certificateKey{algorithm: keyAlgorithmECDSA, bits: 256})
+	c.Assert(err, IsNil)
 
-	expectedError := "Error creating the temp folder"
-	mtd.On("tempDir", "", "wahay_cert_generation").Return("", errors.New(expectedError)).Once()
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	c.Assert(ok, Equals, true)
+	c.Assert(ecKey.Curve, Equals, elliptic.P256())
+	c.Assert(cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature), IsNil)
+	c.Assert(cert.KeyUsage, Equals, x509.KeyUsageDigitalSignature)
 
-	data, err := generateTemporaryMumbleCertificate()
-	c.Assert(err, NotNil)
-	c.Assert(err, ErrorMatches, expectedError)
-	c.Assert(data, Equals, "")
+	_, _, err = generateCertificate(certificateKey{algorithm: keyAlgorithmRSA, bits: 1024})
+	c.Assert(err, Equals, errUnsupportedCertificateKey)
 
-	mtd.AssertExpectations(c)
+	_, _, err = generateCertificate(certificateKey{algorithm: "DSA", bits: 2048})
+	c.Assert(err, Equals, errUnsupportedCertificateKey)
 }
 
-func (s *clientSuite) Test_generateTemporaryMumbleCertificate_returnsAnErrorWhenFailsRunningTheOpensslCommand(c *C) {
-	mc := &mockCmd{}
-	defer gostub.New().Stub(&cmdOutput, mc.Output).Reset()
+func (s *clientSuite) Test_byteArrayParse_readsWhatByteArrayUnparseWrites(c *C) {
+	all := make([]byte, 0, 512)
+	for i := 0; i < 256; i++ {
+		all = append(all, byte(i))
+	}
+	// Hexadecimal digits after escaped bytes must be escaped too
+	all = append(all, 0, '1', 0xab, 'c', 0x05, 'f', '\\', 'x', '4')
 
-	expectedError := errors.New("OpenSSL is not installed on the system.")
-	mc.On("Output").Return([]byte(""), expectedError)
+	for _, bs := range [][]byte{{}, []byte("plain text"), all} {
+		parsed, err := byteArrayParse(byteArrayUnparse(bs))
+		c.Assert(err, IsNil)
+		c.Assert(parsed, DeepEquals, bs)
+	}
+}
 
-	data, err := generateTemporaryMumbleCertificate()
+func (s *clientSuite) Test_byteArrayParse_rejectsInvalidValues(c *C) {
+	for _, v := range []string{"", "plain", "@ByteArray(abc", `@ByteArray(\)`, `@ByteArray(\q)`, `@ByteArray(\xz)`} {
+		_, err := byteArrayParse(v)
+		c.Assert(err, Equals, errInvalidByteArray, Commentf("value: %s", v))
+	}
+}
 
-	c.Assert(data, Equals, "")
-	c.Assert(err, NotNil)
-	c.Assert(err, Equals, expectedError)
+type mockMetadataTorInstance struct {
+	MockTorInstance
+	requests []string
+	response string
+	err      error
+}
 
-	mc.AssertExpectations(c)
+func (m *mockMetadataTorInstance) HTTPrequest(url string) (string, error) {
+	m.requests = append(m.requests, url)
+	return m.response, m.err
 }
 
-type mockReadFile struct {
-	mock.Mock
+func (s *clientSuite) Test_meetingMetadataURL_includesTheMetadataVersion(c *C) {
+	u := meetingMetadataURL("test.onion")
+	c.Assert(u, Equals, "http://test.onion:8181/v1/meeting?version=1")
 }
 
-func (m *mockReadFile) ReadFile(name string) ([]byte, error) {
-	args := m.Called(name)
-	return args.Get(0).([]byte), args.Error(1)
+func (s *clientSuite) Test_parseMeetingMetadata_understandsTheJSONMetadata(c *C) {
+	m, err := parseMeetingMetadata(`{"version":1,"wahay_version":"v1.0.0","title":"A meeting","features":["connection-check"]}`)
+
+	c.Assert(err, IsNil)
+	c.Assert(m.Version, Equals, hosting.MeetingMetadataVersion)
+	c.Assert(m.WahayVersion, Equals, "v1.0.0")
+	c.Assert(m.Title, Equals, "A meeting")
+	c.Assert(m.HasFeature(hosting.FeatureConnectionCheck), Equals, true)
 }
 
-func (s *clientSuite) Test_generateTemporaryMumbleCertificate_returnsAnErrorWhenCantReadCertFile(c *C) {
-	mc := &mockCommand{}
-	defer gostub.New().Stub(&cmdOutput, mc.Output).Reset()
-	mc.On("Command", "openssl", mock.Anything).Return(&exec.Cmd{}).Once()
-	defer gostub.New().Stub(&execCommand, mc.Command).Reset()
-	mc.On("Output").Return([]byte("command output"), nil).Once()
+func (s *clientSuite) Test_parseMeetingMetadata_understandsTheBareCertificateServedByOldHosts(c *C) {
+	m, err := parseMeetingMetadata(fakeCert)
 
-	mrf := &mockReadFile{}
-	defer gostub.New().Stub(&osReadFile, mrf.ReadFile).Reset()
+	c.Assert(err, IsNil)
+	c.Assert(m.CheckCompatibility(), IsNil)
+	c.Assert(m.Certificate, Equals, fakeCert)
+	c.Assert(m.Fingerprint, HasLen, 40)
+	c.Assert(m.Features, HasLen, 0)
+}
 
-	mrf.On("ReadFile", mock.Anything).Return([]byte{}, errors.New("error reading certificate file")).Once()
+func (s *clientSuite) Test_parseMeetingMetadata_returnsAnErrorWhenTheContentIsInvalid(c *C) {
+	m, err := parseMeetingMetadata("mock response")
 
-	data, err := generateTemporaryMumbleCertificate()
+	c.Assert(err, Equals, errInvalidMeetingMetadata)
+	c.Assert(m, IsNil)
+}
 
-	c.Assert(data, Equals, "")
-	c.Assert(err, NotNil)
-	c.Assert(err, ErrorMatches, "error reading certificate file")
+func (s *clientSuite) Test_requestMeetingMetadata_requestsTheMetadataOnlyOnce(c *C) {
+	t := &mockMetadataTorInstance{response: `{"version":1,"title":"A meeting"}`}
+	cl := &client{tor: t}
+
+	m1, err := cl.requestMeetingMetadata("test.onion")
+	c.Assert(err, IsNil)
+	m2, err := cl.requestMeetingMetadata("test.onion")
+	c.Assert(err, IsNil)
+
+	c.Assert(m1.Title, Equals, "A meeting")
+	c.Assert(m2, Equals, m1)
+	c.Assert(t.requests, DeepEquals, []string{"http://test.onion:8181/v1/meeting?version=1"})
+}
+
+func (s *clientSuite) Test_requestMeetingMetadata_returnsAnErrorWhenTheRequestFails(c *C) {
+	t := &mockMetadataTorInstance{err: errors.New("invalid request")}
+	cl := &client{tor: t}
+
+	m, err := cl.requestMeetingMetadata("test.onion")
+
+	c.Assert(err, ErrorMatches, "invalid request")
+	c.Assert(m, IsNil)
+	c.Assert(cl.metadata, HasLen, 0)
+}
+
+func (s *clientSuite) Test_verifyPinnedCertificate_acceptsAnyCertificateWithoutFingerprint(c *C) {
+	m := &hosting.MeetingMetadata{Certificate: fakeCert}
+	c.Assert(verifyPinnedCertificate(m, ""), IsNil)
+}
+
+func (s *clientSuite) Test_verifyPinnedCertificate_checksTheServedCertificate(c *C) {
+	fingerprint, _ := hosting.CertificateFingerprint([]byte(fakeCert))
+
+	m := &hosting.MeetingMetadata{Certificate: fakeCert}
+	c.Assert(verifyPinnedCertificate(m, strings.ToUpper(fingerprint)), IsNil)
+
+	// The announced fingerprint is ignored
+	m = &hosting.MeetingMetadata{Certificate: "dummy cert", Fingerprint: fingerprint}
+	c.Assert(verifyPinnedCertificate(m, fingerprint), Equals, ErrCertificateMismatch)
+
+	m = &hosting.MeetingMetadata{Certificate: fakeCert}
+	c.Assert(verifyPinnedCertificate(m, "0123456789abcdef0123456789abcdef01234567"), Equals, ErrCertificateMismatch)
+}
+
+func (s *clientSuite) Test_MeetingMetadata_returnsAnErrorWhenTheCertificateDoesNotMatchTheFingerprint(c *C) {
+	t := &mockMetadataTorInstance{response: fakeCert}
+	cl := &client{tor: t}
+
+	m, err := cl.MeetingMetadata(hosting.MeetingData{
+		MeetingID:   "test.onion",
+		Fingerprint: "0123456789abcdef0123456789abcdef01234567",
+	})
 
-	mc.AssertExpectations(c)
-	mrf.AssertExpectations(c)
+	c.Assert(err, Equals, ErrCertificateMismatch)
+	c.Assert(m, IsNil)
 }
@@ -2,7 +2,6 @@ package client
 
 import (
 	"errors"
-	"io/ioutil"
 	"os/exec"
 	"sync"
 
@@ -28,6 +27,18 @@ type Instance interface {
 	// based on the given url.
 	Launch(data hosting.MeetingData, onClose func()) (tor.Service, error)
 
+	// MeetingMetadata requests the information the host shares about the meeting
+	// before joining it. The result is kept for the next calls with the same meeting.
+	// If the meeting data includes a certificate fingerprint, ErrCertificateMismatch
+	// is returned when the host serves a different certificate, and if it includes a
+	// signed invitation, the signature is verified with the served certificate.
+	MeetingMetadata(data hosting.MeetingData) (*hosting.MeetingMetadata, error)
+
+	// CertificateFingerprint returns the fingerprint of the server certificate
+	// trusted when the last meeting was launched, or an empty string if
+	// no certificate could be obtained
+	CertificateFingerprint() string
+
 	Destroy()
 }
 
@@ -40,11 +51,15 @@ type client struct {
 	configContentProvider mumbleIniProvider
 	configJSONProvider    mumbleJSONProvider
 	databaseProvider      databaseProvider
+	identityProvider      identityProvider
 	err                   error
 	torCmdModifier        tor.ModifyCommand
 	tor                   tor.Instance
 	f                     *forwarder.Forwarder
 	runningCount          *sync.WaitGroup
+	metadata              map[string]*hosting.MeetingMetadata
+	metadataLock          sync.Mutex
+	fingerprint           string
 }
 
 func newMumbleClient(p mumbleIniProvider, j mumbleJSONProvider, d databaseProvider, t tor.Instance) *client {
@@ -58,6 +73,7 @@ func newMumbleClient(p mumbleIniProvider, j mumbleJSONProvider, d databaseProvid
 		tor:                   t,
 		configFiles:           map[string]struct{}{},
 		runningCount:          &sync.WaitGroup{},
+		metadata:              map[string]*hosting.MeetingMetadata{},
 	}
 
 	return c
@@ -74,6 +90,11 @@ var ErrBinaryUnavailable = errors.New("a valid Mumble binary is not available on
 
 func InitSystem(conf *config.ApplicationConfig, tor tor.Instance) Instance {
 	i := newMumbleClient(readerMumbleIniConfig, readerMumbleJSONConfig, readerMumbleDB, tor)
+	i.identityProvider = conf.UseParticipantIdentity
+
+	if tor != nil {
+		tor.OnRestart(i.onTorRestarted)
+	}
 
 	b, err := searchBinary(conf)
 	if err != nil {
@@ -123,10 +144,16 @@ func invalidInstance(err error) Instance {
 
 func (c *client) Launch(data hosting.MeetingData, onClose func()) (tor.Service, error) {
 	c.f = forwarder.NewForwarder(data)
+	c.f.SetSocksAddress(c.tor.SocksAddress())
+	c.fingerprint = ""
 
 	// First, we load the certificate from the remote server and if a
 	// valid certificate is found then we execute the client through Tor
-	err := c.requestCertificate()
+	err := c.requestCertificate(data)
+	if isVerificationError(err) {
+		return nil, err
+	}
+
 	if err != nil {
 		log.WithFields(log.Fields{"url": c.f.OnionAddr}).Errorf("Launch() client: %s", err.Error())
 	}
@@ -134,11 +161,75 @@ func (c *client) Launch(data hosting.MeetingData, onClose func()) (tor.Service,
 	return c.execute(data, onClose)
 }
 
+func (c *client) MeetingMetadata(data hosting.MeetingData) (*hosting.MeetingMetadata, error) {
+	m, err := c.requestMeetingMetadata(data.MeetingID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyMeeting(m, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// isVerificationError returns a boolean indicating if the error means that
+// joining the meeting is not safe, or not possible at all
+func isVerificationError(err error) bool {
+	switch err {
+	case hosting.ErrIncompatibleMeetingVersion, ErrCertificateMismatch, hosting.ErrInvalidInvitationSignature:
+		return true
+	}
+	return false
+}
+
+// onTorRestarted makes the forwarder of the current meeting use
+// the Tor instance again, since its address could have changed
+func (c *client) onTorRestarted(t tor.Instance) {
+	if c.f != nil {
+		c.f.SetSocksAddress(t.SocksAddress())
+	}
+}
+
+// meetingFavouriteName is the name of the meeting
+// in the servers of the Mumble connection dialog
+const meetingFavouriteName = "Wahay meeting"
+
+// addMeetingToFavourites adds the meeting to the servers of the Mumble connection
+// dialog, so it's easy to connect again after leaving the meeting by mistake.
+// The password is not kept, since the database is written to the disk
+func (c *client) addMeetingToFavourites(data hosting.MeetingData) {
+	db, err := c.db()
+	if err != nil {
+		log.Errorf("addMeetingToFavourites(): %s", err)
+		return
+	}
+	defer closeAndIgnore(db)
+
+	err = db.storeFavourite(favouriteServer{
+		name:     meetingFavouriteName,
+		hostname: c.f.LocalAddr,
+		port:     c.f.ListeningPort,
+		username: data.Username,
+	})
+	if err != nil {
+		log.Errorf("addMeetingToFavourites(): %s", err)
+	}
+}
+
+func (c *client) CertificateFingerprint() string {
+	return c.fingerprint
+}
+
 func (c *client) execute(data hosting.MeetingData, onClose func()) (tor.Service, error) {
-	if !data.IsHost {
+	if data.UsesForwarder() {
 		go c.f.StartForwarder()
 	}
 
+	c.addMeetingToFavourites(data)
+
 	s, err := c.tor.NewService(c.pathToBinary(), []string{c.f.GenerateURL()}, c.torCommandModifier())
 	if err != nil {
 		log.Errorf("Mumble client execute(): %s", err.Error())
@@ -153,7 +244,7 @@ func (c *client) execute(data hosting.MeetingData, onClose func()) (tor.Service,
 			log.Errorf("Mumble client Destroy(): %s", err.Error())
 		}
 
-		if !data.IsHost {
+		if data.UsesForwarder() {
 			c.f.StopForwarder()
 		}
 
@@ -240,7 +331,7 @@ func (c *client) Destroy() {
 	log.Debug("Destroy(): temporary files deleted")
 }
 
-var tempDir = ioutil.TempDir
+var tempDir = config.TempDir
 
 func tempFolder() (string, error) {
 	dir, err := tempDir("", "mumble")
@@ -189,7 +189,21 @@ func (m *MockTorInstance) NewService(a string, b []string, c tor.ModifyCommand)
 	return nil, nil
 }
 
-func (m *MockTorInstance) NewOnionServiceWithMultiplePorts(ports []tor.OnionPort) (tor.Onion, error) {
+func (m *MockTorInstance) NewOnionServiceWithMultiplePorts(ports []tor.OnionPort, options tor.OnionOptions) (tor.Onion, error) {
+	return nil, nil
+}
+
+func (m *MockTorInstance) SocksAddress() string {
+	return "127.0.0.1:9050"
+}
+
+func (m *MockTorInstance) UsesUnixSockets() bool {
+	return false
+}
+
+func (m *MockTorInstance) OnRestart(f func(tor.Instance)) {}
+
+func (m *MockTorInstance) DiagnoseCircuits(meetingID string, onChange func()) (tor.CircuitDiagnostics, error) {
 	return nil, nil
 }
 
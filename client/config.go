@@ -230,10 +230,10 @@ func (c *client) writeConfigToFile(fileName string, path string, template func()
 }
 
 func (c *client) saveCertificateConfigFile() error {
-	tmc, err := generateTemporaryMumbleCertificate()
+	tmc, err := c.mumbleCertificate()
 	if err != nil {
-		log.Debugf("Error generating temporary mumble certificate: %v, assigning empty string", err)
-		tmc = ""
+		log.Errorf("Error generating mumble certificate: %v", err)
+		return err
 	}
 
 	for configFile, _ := range c.configFiles {
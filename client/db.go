@@ -1,17 +1,27 @@
 package client
 
 import (
-	"bytes"
-	b "encoding/binary"
+	"database/sql"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
+
+	// The pure Go SQLite driver, so no C compiler is needed to build Wahay
+	_ "modernc.org/sqlite"
+)
+
+const sqliteDriver = "sqlite"
+
+// The certificate included in the database we give to Mumble, which is
+// only there as an example of the content of the table
+const (
+	templateCertificateHost   = "111.1.1.1"
+	templateCertificateDigest = "AAABACADAFBABBBCBDBEBFCACBCCCDCECFDADBDC"
 )
 
-func (c *client) db() (*dbData, error) {
-	sqlFile := filepath.Join(c.configDir, ".mumble.sqlite")
+func (c *client) db() (*mumbleDB, error) {
+	sqlFile := filepath.Join(c.configDir, configDBName)
 
 	if !pathExists(sqlFile) {
 		log.WithFields(log.Fields{
@@ -25,78 +35,146 @@ func (c *client) db() (*dbData, error) {
 		}
 	}
 
-	d, err := loadDBFromFile(sqlFile)
+	return openMumbleDB(sqlFile)
+}
+
+// mumbleDB is the database where the Mumble client keeps, among other
+// things, the certificates it trusts and the favourite servers
+type mumbleDB struct {
+	db *sql.DB
+}
+
+func openMumbleDB(filename string) (*mumbleDB, error) {
+	log.WithFields(log.Fields{
+		"filepath": filename,
+	}).Debug("Opening Mumble sqlite database")
+
+	db, err := sql.Open(sqliteDriver, filename)
 	if err != nil {
 		return nil, err
 	}
 
-	return d, nil
+	// Opening the database doesn't read it, so we make sure
+	// it's a valid Mumble database before using it
+	err = db.QueryRow("SELECT COUNT(*) FROM `cert`").Scan(new(int))
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &mumbleDB{db: db}, nil
 }
 
-type dbData struct {
-	filename string
-	content  []byte
+func (d *mumbleDB) Close() error {
+	return d.db.Close()
 }
 
-func (d *dbData) exists(k string) bool {
-	return bytes.Contains(d.content, []byte(k))
+// storedCertificate is a row of the table where Mumble keeps the
+// digest of the certificate it trusts for each server
+type storedCertificate struct {
+	hostname string
+	port     int
+	digest   string
 }
 
-func (d *dbData) replaceString(find, replace string) {
-	newContent := bytes.Replace(d.content, []byte(find), []byte(replace), -1)
-	d.content = newContent
+func (d *mumbleDB) certificates() ([]storedCertificate, error) {
+	rows, err := d.db.Query("SELECT `hostname`, `port`, `digest` FROM `cert` ORDER BY `id`")
+	if err != nil {
+		return nil, err
+	}
+	defer closeAndIgnore(rows)
+
+	result := []storedCertificate{}
+	for rows.Next() {
+		sc := storedCertificate{}
+		err = rows.Scan(&sc.hostname, &sc.port, &sc.digest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sc)
+	}
+
+	return result, rows.Err()
 }
 
-func (d *dbData) replaceInteger(find, replace uint16) {
-	d.replaceString(
-		intToStringToSearch(find),
-		intToStringToSearch(replace),
-	)
+// certificateDigest returns the digest of the certificate trusted for the
+// given server, or an empty string if there is none
+func (d *mumbleDB) certificateDigest(hostname string, port int) (string, error) {
+	var digest string
+
+	err := d.db.QueryRow("SELECT `digest` FROM `cert` WHERE `hostname` = ? AND `port` = ?", hostname, port).Scan(&digest)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return digest, err
 }
 
-func (d *dbData) write() error {
-	err := ioutil.WriteFile(d.filename, d.content, 0)
+// storeCertificate makes Mumble trust the certificate with the given digest
+// for the given server, replacing the one it trusted before, if any
+func (d *mumbleDB) storeCertificate(hostname string, port int, digest string) error {
+	_, err := d.db.Exec("DELETE FROM `cert` WHERE `hostname` = ? AND `digest` = ?",
+		templateCertificateHost, templateCertificateDigest)
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func intToStringToSearch(n uint16) string {
-	n1 := n >> 8
-	n2 := n % 256
 
-	buf := new(bytes.Buffer)
-	_ = b.Write(buf, b.LittleEndian, uint8(n1))
-	_ = b.Write(buf, b.LittleEndian, uint8(n2))
+	_, err = d.db.Exec("INSERT INTO `cert` (`hostname`, `port`, `digest`) VALUES (?, ?, ?) "+
+		"ON CONFLICT (`hostname`, `port`) DO UPDATE SET `digest` = excluded.`digest`",
+		hostname, port, digest)
 
-	return buf.String()
+	return err
 }
 
-func loadDBFromFile(filename string) (*dbData, error) {
-	log.WithFields(log.Fields{
-		"filepath": filename,
-	}).Debug("Loading Mumble sqlite database")
+// favouriteServer is a row of the table where Mumble keeps the
+// servers shown in its connection dialog
+type favouriteServer struct {
+	name     string
+	hostname string
+	port     int
+	username string
+	password string
+	url      string
+}
 
-	content, err := readBinaryContent(filename)
+func (d *mumbleDB) favourites() ([]favouriteServer, error) {
+	rows, err := d.db.Query("SELECT `name`, `hostname`, `port`, `username`, " +
+		"IFNULL(`password`, ''), IFNULL(`url`, '') FROM `servers` ORDER BY `id`")
 	if err != nil {
 		return nil, err
 	}
+	defer closeAndIgnore(rows)
 
-	d := &dbData{
-		filename: filename,
-		content:  content,
+	result := []favouriteServer{}
+	for rows.Next() {
+		f := favouriteServer{}
+		err = rows.Scan(&f.name, &f.hostname, &f.port, &f.username, &f.password, &f.url)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, f)
 	}
 
-	return d, nil
+	return result, rows.Err()
 }
 
-func readBinaryContent(filename string) ([]byte, error) {
-	file, err := os.Open(filepath.Clean(filename))
+// storeFavourite adds the given server to the favourites of Mumble, or
+// updates the entry with the same server and username if there is one
+func (d *mumbleDB) storeFavourite(f favouriteServer) error {
+	r, err := d.db.Exec("UPDATE `servers` SET `name` = ?, `password` = ?, `url` = ? "+
+		"WHERE `hostname` = ? AND `port` = ? AND `username` = ?",
+		f.name, f.password, f.url, f.hostname, f.port, f.username)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer closeAndIgnore(file)
 
-	return ioutil.ReadAll(file)
+	updated, err := r.RowsAffected()
+	if err != nil || updated > 0 {
+		return err
+	}
+
+	_, err = d.db.Exec("INSERT INTO `servers` (`name`, `hostname`, `port`, `username`, `password`, `url`) "+
+		"VALUES (?, ?, ?, ?, ?, ?)", f.name, f.hostname, f.port, f.username, f.password, f.url)
+
+	return err
 }
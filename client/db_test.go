@@ -1,6 +1,7 @@
 package client
 
 import (
+	"database/sql"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -9,10 +10,8 @@ import (
 )
 
 // helper functions
-func createFakeClient(content string, tempDir string) *client {
-
-	fakeDBContent := []byte(content)
-	fakeDBProvider := func() []byte { return fakeDBContent }
+func createFakeClient(content []byte, tempDir string) *client {
+	fakeDBProvider := func() []byte { return content }
 
 	fakeClient := &client{
 		databaseProvider: fakeDBProvider,
@@ -22,12 +21,6 @@ func createFakeClient(content string, tempDir string) *client {
 	return fakeClient
 }
 
-func createTempDirAndFile(c *C, filename, content string) (string, string) {
-	tempDir := createTempDir(c)
-	tempFile := createTempFile(c, tempDir, filename, content)
-	return tempDir, tempFile
-}
-
 func createTempDir(c *C) string {
 	tempDir, err := ioutil.TempDir("", "test")
 	c.Assert(err, IsNil)
@@ -46,150 +39,122 @@ func removeTempDir(c *C, dir string) {
 	c.Assert(err, IsNil)
 }
 
-// tests
-func (s *clientSuite) Test_write_writesDBContent(c *C) {
-	tempDir := createTempDir(c)
-	tempFile := createTempFile(c, tempDir, "testfile.txt", "")
-	defer removeTempDir(c, tempDir)
-
-	content := []byte("example content")
-
-	db := &dbData{
-		filename: tempFile,
-		content:  content,
-	}
-
-	err := db.write()
+// readTable opens the database the same way any SQLite reader would,
+// returning the rows of the given query as strings
+func readTable(c *C, filename, query string) [][]string {
+	db, err := sql.Open(sqliteDriver, filename)
 	c.Assert(err, IsNil)
+	defer db.Close()
 
-	readContent, err := ioutil.ReadFile(tempFile)
-
+	rows, err := db.Query(query)
 	c.Assert(err, IsNil)
-	c.Assert(readContent, DeepEquals, content)
-}
+	defer rows.Close()
 
-func (s *clientSuite) Test_exists_returnsTrueIfStringExistsInContent(c *C) {
-	db := &dbData{
-		content: []byte("this is true"),
-	}
-
-	exampleString := "true"
-
-	res := db.exists(exampleString)
-
-	c.Assert(res, Equals, true)
-}
+	columns, err := rows.Columns()
+	c.Assert(err, IsNil)
 
-func (s *clientSuite) Test_exists_returnsFalseIfStringDoesntExistInContent(c *C) {
-	db := &dbData{
-		content: []byte("this is false"),
+	result := [][]string{}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		c.Assert(rows.Scan(pointers...), IsNil)
+
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = v.String
+		}
+		result = append(result, row)
 	}
+	c.Assert(rows.Err(), IsNil)
 
-	exampleString := "true"
-
-	res := db.exists(exampleString)
-
-	c.Assert(res, Equals, false)
+	return result
 }
 
-func (s *clientSuite) Test_replaceString_findsAndReplacesContentInDB(c *C) {
-	db := &dbData{
-		content: []byte("this is true"),
-	}
-
-	find := "true"
-	replace := "false"
-	expectedContent := []byte("this is false")
-
-	db.replaceString(find, replace)
-
-	c.Assert(db.content, DeepEquals, expectedContent)
-}
-
-func (s *clientSuite) Test_readBinaryContent_readsFileContent(c *C) {
-	content := "example binary content"
-
-	tempDir, tempFile := createTempDirAndFile(c, "testfile.bin", content)
+// tests
+func (s *clientSuite) Test_db_createsTheDatabaseFromTheTemplate(c *C) {
+	tempDir := createTempDir(c)
 	defer removeTempDir(c, tempDir)
 
-	expectedContent := []byte(content)
-
-	readContent, err := readBinaryContent(tempFile)
-
+	db, err := createFakeClient(readerMumbleDB(), tempDir).db()
 	c.Assert(err, IsNil)
-	c.Assert(readContent, DeepEquals, expectedContent)
-}
+	defer db.Close()
 
-func (s *clientSuite) Test_readBinaryContent_handlesFileNotFoundError(c *C) {
-	nonExistentFile := "/path/to/nonexistent/file.bin"
-
-	_, err := readBinaryContent(nonExistentFile)
-	c.Assert(err, NotNil)
-	c.Assert(os.IsNotExist(err), Equals, true)
+	certificates, err := db.certificates()
+	c.Assert(err, IsNil)
+	c.Assert(certificates, DeepEquals, []storedCertificate{
+		{hostname: templateCertificateHost, port: 64738, digest: templateCertificateDigest},
+	})
 }
 
-func (s *clientSuite) Test_loadDBFromFile_loadsDatabaseSuccessfully(c *C) {
-	content := "example database content"
-
-	tempDir, tempFile := createTempDirAndFile(c, "testfile.db", content)
+func (s *clientSuite) Test_db_usesTheExistingDatabase(c *C) {
+	tempDir := createTempDir(c)
 	defer removeTempDir(c, tempDir)
 
-	expectedContent := []byte(content)
+	cl := createFakeClient(readerMumbleDB(), tempDir)
+	c.Assert(cl.storeCertificateInDB("127.0.0.1", 31337, "0123456789abcdef"), IsNil)
 
-	db, err := loadDBFromFile(tempFile)
+	cl.databaseProvider = func() []byte { return []byte("not a database") }
 
+	db, err := cl.db()
 	c.Assert(err, IsNil)
-	c.Assert(db, NotNil)
-	c.Assert(db.filename, Equals, tempFile)
-	c.Assert(db.content, DeepEquals, expectedContent)
+	defer db.Close()
+
+	digest, err := db.certificateDigest("127.0.0.1", 31337)
+	c.Assert(err, IsNil)
+	c.Assert(digest, Equals, "0123456789abcdef")
 }
 
-func (s *clientSuite) Test_loadDBFromFile_handlesFileNotFoundError(c *C) {
-	nonExistentFile := "/path/to/nonexistent/file.db"
+func (s *clientSuite) Test_db_failsWithSomethingThatIsNotAMumbleDatabase(c *C) {
+	tempDir := createTempDir(c)
+	defer removeTempDir(c, tempDir)
 
-	db, err := loadDBFromFile(nonExistentFile)
+	createTempFile(c, tempDir, configDBName, "database example content")
 
+	_, err := createFakeClient(readerMumbleDB(), tempDir).db()
 	c.Assert(err, NotNil)
-	c.Assert(os.IsNotExist(err), Equals, true)
-	c.Assert(db, IsNil)
 }
 
-func (s *clientSuite) Test_db_createsDatabase(c *C) {
-	tempDir, _ := createTempDirAndFile(c, "config.yaml", "configuration data")
+func (s *clientSuite) Test_storeCertificateInDB_keepsACertificateForEachServer(c *C) {
+	tempDir := createTempDir(c)
 	defer removeTempDir(c, tempDir)
 
-	content := "database example content"
-	fakeDBContent := []byte(content)
-
-	fakeClient := createFakeClient(content, tempDir)
-
-	db, err := fakeClient.db()
-	c.Assert(err, IsNil)
-	c.Assert(db, NotNil)
+	cl := createFakeClient(readerMumbleDB(), tempDir)
 
-	sqlFile := filepath.Join(tempDir, ".mumble.sqlite")
-	_, err = os.Stat(sqlFile)
-	c.Assert(err, IsNil)
+	c.Assert(cl.storeCertificateInDB("127.0.0.1", 31337, "aaaa"), IsNil)
+	c.Assert(cl.storeCertificateInDB("127.0.0.1", 31338, "bbbb"), IsNil)
+	c.Assert(cl.storeCertificateInDB("127.0.0.1", 31337, "cccc"), IsNil)
+	c.Assert(cl.storeCertificateInDB("127.0.0.1", 31338, "bbbb"), IsNil)
 
-	c.Assert(db.filename, Equals, sqlFile)
-	c.Assert(db.content, DeepEquals, fakeDBContent)
+	rows := readTable(c, filepath.Join(tempDir, configDBName), "SELECT hostname, port, digest FROM cert ORDER BY port")
+	c.Assert(rows, DeepEquals, [][]string{
+		{"127.0.0.1", "31337", "cccc"},
+		{"127.0.0.1", "31338", "bbbb"},
+	})
 }
 
-func (s *clientSuite) Test_db_loadsExistingDatabase(c *C) {
-	content := "database example content"
-	fakeClientContent := "fake client content"
+func (s *clientSuite) Test_storeFavourite_addsAndUpdatesTheServersOfMumble(c *C) {
+	tempDir := createTempDir(c)
+	defer removeTempDir(c, tempDir)
 
-	tempDir, _ := createTempDirAndFile(c, "config.yaml", "configuration data")
-	sqlFile := createTempFile(c, tempDir, ".mumble.sqlite", content)
+	db, err := createFakeClient(readerMumbleDB(), tempDir).db()
+	c.Assert(err, IsNil)
+	defer db.Close()
 
-	defer removeTempDir(c, tempDir)
+	c.Assert(db.storeFavourite(favouriteServer{name: "one", hostname: "127.0.0.1", port: 31337, username: "alice"}), IsNil)
+	c.Assert(db.storeFavourite(favouriteServer{name: "two", hostname: "127.0.0.1", port: 31337, username: "bob"}), IsNil)
+	c.Assert(db.storeFavourite(favouriteServer{name: "three", hostname: "127.0.0.1", port: 31337, username: "alice", password: "secret"}), IsNil)
 
-	fakeClient := createFakeClient(fakeClientContent, tempDir)
-	fakeDBContent := []byte(content)
+	rows := readTable(c, filepath.Join(tempDir, configDBName), "SELECT name, hostname, port, username, password FROM servers ORDER BY id")
+	c.Assert(rows, DeepEquals, [][]string{
+		{"three", "127.0.0.1", "31337", "alice", "secret"},
+		{"two", "127.0.0.1", "31337", "bob", ""},
+	})
 
-	db, err := fakeClient.db()
+	favourites, err := db.favourites()
 	c.Assert(err, IsNil)
-	c.Assert(db.filename, Equals, sqlFile)
-	c.Assert(db.content, DeepEquals, fakeDBContent)
-	c.Assert(db.content, Not(DeepEquals), []byte(fakeClientContent))
+	c.Assert(favourites, HasLen, 2)
+	c.Assert(favourites[1], DeepEquals, favouriteServer{name: "two", hostname: "127.0.0.1", port: 31337, username: "bob"})
 }
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// identityProvider returns the PKCS12 bundle the participant identifies
+// with in every meeting, or nil when a new identity must be used
+type identityProvider func() []byte
+
+// ErrInvalidIdentity is an error to be trown when the imported
+// file doesn't contain a certificate and a private key
+var ErrInvalidIdentity = errors.New("the file doesn't contain a valid identity")
+
+// ErrIdentityWithPassword is an error to be trown when the imported
+// identity is protected with a password, which Mumble can't use
+var ErrIdentityWithPassword = errors.New("the identity is protected with a password")
+
+// GenerateIdentity creates a new identity for the participant, as a PKCS12
+// bundle with a certificate and a private key that Mumble can import
+func GenerateIdentity() ([]byte, error) {
+	return generateCertificateBundle()
+}
+
+// ImportIdentity reads the identity in the given PKCS12 bundle, like the ones
+// exported by Wahay or by the certificate wizard of Mumble, and returns it
+// in the format used to join the meetings
+func ImportIdentity(content []byte) ([]byte, error) {
+	priv, cert, caCerts, err := pkcs12.DecodeChain(content, "")
+	if err == pkcs12.ErrIncorrectPassword {
+		return nil, ErrIdentityWithPassword
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Debug("ImportIdentity(): the identity can't be decoded")
+		return nil, ErrInvalidIdentity
+	}
+
+	switch priv.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil, ErrInvalidIdentity
+	}
+
+	return pkcs12.Modern.Encode(priv, cert, caCerts, "")
+}
+
+// IdentityFingerprint returns the fingerprint of the certificate in the given
+// identity, which is the one the hosts see for the participant in Mumble
+func IdentityFingerprint(identity []byte) (string, error) {
+	_, cert, _, err := pkcs12.DecodeChain(identity, "")
+	if err != nil {
+		return "", ErrInvalidIdentity
+	}
+
+	return digestForCertificate(cert.Raw)
+}
+
+// mumbleCertificate returns the certificate for the Mumble client in the
+// @ByteArray format. Unless the participant chose to keep the same identity,
+// a new certificate is generated every time, so the hosts can't recognise
+// the participants that join their meetings again
+func (c *client) mumbleCertificate() (string, error) {
+	if c.identityProvider != nil {
+		if identity := c.identityProvider(); len(identity) != 0 {
+			return byteArrayUnparse(identity), nil
+		}
+	}
+
+	return generateTemporaryMumbleCertificate()
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+
+	. "gopkg.in/check.v1"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func (s *clientSuite) Test_mumbleCertificate_generatesANewCertificateByDefault(c *C) {
+	cl := &client{}
+
+	first, err := cl.mumbleCertificate()
+	c.Assert(err, IsNil)
+
+	second, err := cl.mumbleCertificate()
+	c.Assert(err, IsNil)
+	c.Assert(first, Not(Equals), second)
+
+	cl.identityProvider = func() []byte { return nil }
+
+	third, err := cl.mumbleCertificate()
+	c.Assert(err, IsNil)
+	c.Assert(third, Not(Equals), second)
+}
+
+func (s *clientSuite) Test_mumbleCertificate_usesThePersistentIdentity(c *C) {
+	identity, err := GenerateIdentity()
+	c.Assert(err, IsNil)
+
+	cl := &client{identityProvider: func() []byte { return identity }}
+
+	data, err := cl.mumbleCertificate()
+	c.Assert(err, IsNil)
+
+	bundle, err := byteArrayParse(data)
+	c.Assert(err, IsNil)
+	c.Assert(bundle, DeepEquals, identity)
+}
+
+func (s *clientSuite) Test_ImportIdentity_keepsTheCertificateAndTheKey(c *C) {
+	cert, key, err := generateCertificate(mumbleCertificateKey)
+	c.Assert(err, IsNil)
+
+	exported, err := pkcs12.LegacyRC2.Encode(key, cert, nil, "")
+	c.Assert(err, IsNil)
+
+	identity, err := ImportIdentity(exported)
+	c.Assert(err, IsNil)
+
+	importedKey, importedCert, err := pkcs12.Decode(identity, "")
+	c.Assert(err, IsNil)
+	c.Assert(importedCert.Equal(cert), Equals, true)
+	rsaKey, ok := importedKey.(*rsa.PrivateKey)
+	c.Assert(ok, Equals, true)
+	c.Assert(rsaKey.Equal(key), Equals, true)
+
+	expected, _ := digestForCertificate(cert.Raw)
+	fingerprint, err := IdentityFingerprint(identity)
+	c.Assert(err, IsNil)
+	c.Assert(fingerprint, Equals, expected)
+}
+
+func (s *clientSuite) Test_ImportIdentity_rejectsWhatMumbleCantUse(c *C) {
+	cert, key, err := generateCertificate(mumbleCertificateKey)
+	c.Assert(err, IsNil)
+
+	withPassword, err := pkcs12.Encode(rand.Reader, key, cert, nil, "secret")
+	c.Assert(err, IsNil)
+
+	_, err = ImportIdentity(withPassword)
+	c.Assert(err, Equals, ErrIdentityWithPassword)
+
+	_, err = ImportIdentity([]byte("not an identity"))
+	c.Assert(err, Equals, ErrInvalidIdentity)
+
+	_, err = IdentityFingerprint([]byte("not an identity"))
+	c.Assert(err, Equals, ErrInvalidIdentity)
+}
@@ -22,6 +22,12 @@ var (
 	TorRoutePort = flag.Int("tor-route-port", DefaultRoutePort, "the route port for Tor")
 	// TorControlPassword contains the command line argument given for the Tor control port password
 	TorControlPassword = flag.String("tor-password", "", "the password for controlling Tor - can not be empty")
+	// TorControlSocket contains the command line argument given for the Tor control port socket
+	TorControlSocket = flag.String("tor-control-socket", "", "the path to the Unix domain socket of the Tor control port")
+	// TorAuth contains the command line argument given for the Tor control port authentication method
+	TorAuth = flag.String("tor-auth", TorAuthAutomatic, "the authentication method for controlling Tor: none, cookie or password")
+	// TorCookieFile contains the command line argument given for the Tor authentication cookie path
+	TorCookieFile = flag.String("tor-cookie-file", "", "the path to the authentication cookie for controlling Tor")
 	// Debug contains the command line argument given for debugging
 	Debug = flag.Bool("debug", false, "start Wahay in debugging mode")
 	// Trace contains the command line argument given for debugging
@@ -37,3 +43,9 @@ var (
 func ProcessCommandLineArguments() {
 	flag.Parse()
 }
+
+// InvitationArguments returns the wahay: URIs or the paths to the
+// invitation files given as arguments when starting Wahay
+func InvitationArguments() []string {
+	return flag.Args()
+}
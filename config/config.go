@@ -25,15 +25,29 @@ type ApplicationConfig struct {
 	encryptionParams *EncryptionParameters
 
 	// The fields to save as the JSON representation of the configuration
-	UniqueConfigurationID string
-	AsSuperUser           bool
-	AutoJoin              bool
-	PathTor               string
-	LogsEnabled           bool
-	RawLogFile            string
-	PathMumble            string
-	PortMumble            string
-	ColorScheme           string
+	UniqueConfigurationID   string
+	AsSuperUser             bool
+	AutoJoin                bool
+	PathTor                 string
+	LogsEnabled             bool
+	RawLogFile              string
+	PathMumble              string
+	PortMumble              string
+	ColorScheme             string
+	BridgesEnabled          bool
+	BridgeLines             []string
+	TorrcLines              []string
+	TorEndpoint             *TorEndpoint
+	TorProxy                *TorProxy
+	TorProxyFromEnvironment bool
+	TorCacheEnabled         bool
+	TorCacheConsented       bool
+	MeetingIsolationEnabled bool
+	MeetingIsolationLimit   int
+	SingleHopHostingEnabled bool
+	HostingProtection       *HostingProtection
+	PersistentIdentity      bool
+	ParticipantIdentity     []byte
 }
 
 var (
@@ -423,3 +437,140 @@ func (a *ApplicationConfig) GetColorScheme() string {
 func (a *ApplicationConfig) SetColorScheme(scheme string) {
 	a.ColorScheme = scheme
 }
+
+// AreBridgesEnabled returns a boolean indicating if Tor should
+// connect to the network through the configured bridges
+func (a *ApplicationConfig) AreBridgesEnabled() bool {
+	return a.BridgesEnabled
+}
+
+// EnableBridges sets the value for enabling or disabling bridges
+func (a *ApplicationConfig) EnableBridges(v bool) {
+	a.BridgesEnabled = v
+}
+
+// GetBridgeLines returns the configured bridge lines
+func (a *ApplicationConfig) GetBridgeLines() []string {
+	return a.BridgeLines
+}
+
+// SetBridgeLines sets the bridge lines Tor will use when bridges are enabled
+func (a *ApplicationConfig) SetBridgeLines(v []string) {
+	a.BridgeLines = v
+}
+
+// GetTorrcLines returns the additional lines configured by the user for
+// the configuration file of the Tor instance started by Wahay
+func (a *ApplicationConfig) GetTorrcLines() []string {
+	return a.TorrcLines
+}
+
+// SetTorrcLines sets the additional lines for the Tor configuration file
+func (a *ApplicationConfig) SetTorrcLines(v []string) {
+	a.TorrcLines = v
+}
+
+// DefaultMeetingIsolationLimit is the number of Tor instances that can be
+// started for the hosted meetings when no other limit is configured
+const DefaultMeetingIsolationLimit = 3
+
+// IsMeetingIsolationEnabled returns a boolean indicating if each hosted
+// meeting should use its own Tor instance
+func (a *ApplicationConfig) IsMeetingIsolationEnabled() bool {
+	return a.MeetingIsolationEnabled
+}
+
+// EnableMeetingIsolation sets the value for hosting
+// each meeting on its own Tor instance
+func (a *ApplicationConfig) EnableMeetingIsolation(v bool) {
+	a.MeetingIsolationEnabled = v
+}
+
+// GetMeetingIsolationLimit returns the maximum number of
+// Tor instances that can be started for the hosted meetings
+func (a *ApplicationConfig) GetMeetingIsolationLimit() int {
+	if a.MeetingIsolationLimit <= 0 {
+		return DefaultMeetingIsolationLimit
+	}
+	return a.MeetingIsolationLimit
+}
+
+// SetMeetingIsolationLimit sets the maximum number of
+// Tor instances that can be started for the hosted meetings
+func (a *ApplicationConfig) SetMeetingIsolationLimit(v int) {
+	a.MeetingIsolationLimit = v
+}
+
+// IsSingleHopHostingEnabled returns a boolean indicating if the meetings
+// should be hosted as single onion services, which don't hide the
+// location of the host but have less latency
+func (a *ApplicationConfig) IsSingleHopHostingEnabled() bool {
+	return a.SingleHopHostingEnabled
+}
+
+// EnableSingleHopHosting sets the value for hosting
+// the meetings as single onion services
+func (a *ApplicationConfig) EnableSingleHopHosting(v bool) {
+	a.SingleHopHostingEnabled = v
+}
+
+// IsPersistentIdentityEnabled returns a boolean indicating if the participant
+// joins every meeting with the same identity, instead of a new one each time
+func (a *ApplicationConfig) IsPersistentIdentityEnabled() bool {
+	return a.PersistentIdentity
+}
+
+// EnablePersistentIdentity sets the value for joining every meeting with
+// the same identity. Disabling it keeps the identity, so it can be used again
+func (a *ApplicationConfig) EnablePersistentIdentity(v bool) {
+	a.PersistentIdentity = v
+}
+
+// GetParticipantIdentity returns the PKCS12 bundle with the certificate
+// and the private key the participant identifies with in the meetings
+func (a *ApplicationConfig) GetParticipantIdentity() []byte {
+	return a.ParticipantIdentity
+}
+
+// SetParticipantIdentity sets the PKCS12 bundle with the certificate
+// and the private key the participant identifies with in the meetings
+func (a *ApplicationConfig) SetParticipantIdentity(v []byte) {
+	a.ParticipantIdentity = v
+}
+
+// UseParticipantIdentity returns the identity to join the meetings with, or nil
+// when a new identity must be used for each meeting, which is the default
+func (a *ApplicationConfig) UseParticipantIdentity() []byte {
+	if !a.PersistentIdentity || len(a.ParticipantIdentity) == 0 {
+		return nil
+	}
+	return a.ParticipantIdentity
+}
+
+// IsTorCacheEnabled returns a boolean indicating if the data of the Tor
+// instance started by Wahay should be kept between executions
+func (a *ApplicationConfig) IsTorCacheEnabled() bool {
+	return a.TorCacheEnabled
+}
+
+// EnableTorCache sets the value for keeping the Tor cache. Disabling
+// it also withdraws the consent to keep it unencrypted
+func (a *ApplicationConfig) EnableTorCache(v bool) {
+	a.TorCacheEnabled = v
+	if !v {
+		a.TorCacheConsented = false
+	}
+}
+
+// ConsentToTorCache records that the user agreed to keep the Tor cache
+// even if it can't be encrypted like the configuration file
+func (a *ApplicationConfig) ConsentToTorCache(v bool) {
+	a.TorCacheConsented = v
+}
+
+// UseTorCache returns a boolean indicating if the Tor cache must be used.
+// Since it can't be encrypted, the user must have consented to it when
+// the configuration file is encrypted
+func (a *ApplicationConfig) UseTorCache() bool {
+	return a.TorCacheEnabled && (!a.ShouldEncrypt() || a.TorCacheConsented)
+}
@@ -499,3 +499,49 @@ func NewMockKeySupplier(t interface {
 
 	return mock
 }
+
+func (cs *ConfigSuite) Test_UseTorCache_requiresConsentWhenTheConfigurationIsEncrypted(c *C) {
+	a := New()
+	c.Assert(a.UseTorCache(), Equals, false)
+
+	a.EnableTorCache(true)
+	c.Assert(a.UseTorCache(), Equals, true)
+
+	a.SetShouldEncrypt(true)
+	c.Assert(a.UseTorCache(), Equals, false)
+
+	a.ConsentToTorCache(true)
+	c.Assert(a.UseTorCache(), Equals, true)
+
+	a.EnableTorCache(false)
+	c.Assert(a.UseTorCache(), Equals, false)
+
+	a.EnableTorCache(true)
+	c.Assert(a.UseTorCache(), Equals, false)
+}
+
+func (cs *ConfigSuite) Test_GetMeetingIsolationLimit_usesTheDefaultWhenNoneIsConfigured(c *C) {
+	a := New()
+	c.Assert(a.GetMeetingIsolationLimit(), Equals, DefaultMeetingIsolationLimit)
+
+	a.SetMeetingIsolationLimit(5)
+	c.Assert(a.GetMeetingIsolationLimit(), Equals, 5)
+
+	a.SetMeetingIsolationLimit(-1)
+	c.Assert(a.GetMeetingIsolationLimit(), Equals, DefaultMeetingIsolationLimit)
+}
+
+func (cs *ConfigSuite) Test_UseParticipantIdentity_onlyReturnsTheIdentityWhenItIsEnabled(c *C) {
+	a := New()
+	c.Assert(a.UseParticipantIdentity(), IsNil)
+
+	a.SetParticipantIdentity([]byte("identity"))
+	c.Assert(a.UseParticipantIdentity(), IsNil)
+
+	a.EnablePersistentIdentity(true)
+	c.Assert(a.UseParticipantIdentity(), DeepEquals, []byte("identity"))
+
+	a.EnablePersistentIdentity(false)
+	c.Assert(a.UseParticipantIdentity(), IsNil)
+	c.Assert(a.GetParticipantIdentity(), DeepEquals, []byte("identity"))
+}
@@ -4,6 +4,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -27,10 +32,105 @@ func EnsureFilesAndDir() {
 // CreateTempDir creates a temp dir inside Wahay's data dir
 func CreateTempDir(dir string) string {
 	EnsureFilesAndDir()
-	d, _ := ioutil.TempDir(wahayDataDir, dir)
+	d, _ := TempDir(wahayDataDir, dir)
 	return d
 }
 
+// tempDirOwnerFile is the file where the temporary directories created
+// by Wahay keep the process ID of the execution of Wahay that owns them
+const tempDirOwnerFile = ".wahay-owner"
+
+// TempDir creates a new temporary directory in the same way as ioutil.TempDir,
+// recording that it belongs to this execution of Wahay. Only the directories
+// created this way can be removed by RemoveStaleTempDirs
+func TempDir(dir, pattern string) (string, error) {
+	d, err := ioutil.TempDir(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	owner := []byte(strconv.Itoa(os.Getpid()))
+	err = ioutil.WriteFile(filepath.Join(d, tempDirOwnerFile), owner, 0600)
+	if err != nil {
+		_ = os.RemoveAll(d)
+		return "", err
+	}
+
+	return d, nil
+}
+
+// staleTempDirPattern matches the names of the temporary directories created by
+// Wahay for Tor, the meeting servers and Mumble. They all end with the random
+// number added by ioutil.TempDir
+var staleTempDirPattern = regexp.MustCompile(`^(tor|wahay|mumble)[0-9]+$`)
+
+// RemoveStaleTempDirs removes the temporary directories left behind by previous
+// executions of Wahay that crashed or were killed. The directories of other
+// executions of Wahay that are still running, and the ones that weren't
+// created by Wahay, are kept
+func RemoveStaleTempDirs() {
+	removeStaleTempDirsIn(wahayDataDir, os.TempDir())
+}
+
+// processIsRunning returns a boolean indicating if
+// the process with the given ID is still running
+var processIsRunning = isProcessRunning
+
+// IsProcessRunning returns a boolean indicating if
+// the process with the given ID is still running
+func IsProcessRunning(pid int) bool {
+	return processIsRunning(pid)
+}
+
+func removeStaleTempDirsIn(dirs ...string) {
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			if !e.IsDir() || !staleTempDirPattern.MatchString(e.Name()) {
+				continue
+			}
+
+			candidate := filepath.Join(dir, e.Name())
+			if !isStaleTempDir(candidate) {
+				continue
+			}
+
+			log.Debugf("Removing stale temp dir: %s", candidate)
+			err := os.RemoveAll(candidate)
+			if err != nil {
+				log.Debug(err)
+			}
+		}
+	}
+}
+
+// isStaleTempDir returns a boolean indicating if the given directory was
+// created by an execution of Wahay that is not running anymore
+func isStaleTempDir(dir string) bool {
+	pid, ok := ownerOf(dir)
+	return ok && pid != os.Getpid() && !processIsRunning(pid)
+}
+
+// ownerOf returns the process ID of the execution of Wahay
+// recorded as the owner of the given directory, if any
+func ownerOf(dir string) (int, bool) {
+	content, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, tempDirOwnerFile)))
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+
+	return pid, true
+}
+
 // FileExists check if a specific file exists
 func FileExists(filename string) bool {
 	_, err := os.Stat(filename)
@@ -78,6 +178,120 @@ func Dir() string {
 	return filepath.Join(SystemConfigDir(), "wahay")
 }
 
+// TorCacheDir returns the directory where the data of the Tor instance
+// started by Wahay is kept between executions, when it's allowed
+func TorCacheDir() string {
+	return filepath.Join(wahayDataDir, "tor-cache")
+}
+
+// torCacheClearFile is the file that records, in the Tor cache directory,
+// that the cache must be cleared before a Tor instance uses it again
+const torCacheClearFile = ".wahay-clear"
+
+// AcquireTorCache records that the Tor instance of this execution of Wahay
+// uses the Tor cache. Tor doesn't allow two instances to use the same data
+// directory, so false is returned when another execution of Wahay that is
+// still running uses it. A clear requested while the cache was in use is
+// done here, before any Tor instance uses the cache again
+func AcquireTorCache() bool {
+	dir := TorCacheDir()
+	EnsureDir(dir, 0700)
+
+	if pid, ok := ownerOf(dir); ok && pid == os.Getpid() {
+		return true
+	}
+
+	owner := filepath.Join(dir, tempDirOwnerFile)
+
+	// The owner file is only replaced when the execution that
+	// created it isn't running anymore, so one more attempt is enough
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(filepath.Clean(owner), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			if e := f.Close(); err == nil {
+				err = e
+			}
+			if err != nil {
+				_ = os.Remove(owner)
+				return false
+			}
+
+			if FileExists(filepath.Join(dir, torCacheClearFile)) {
+				err = clearDir(dir, tempDirOwnerFile)
+				if err != nil {
+					log.Errorf("AcquireTorCache(): the Tor cache can't be cleared: %s", err)
+				}
+			}
+
+			return true
+		}
+
+		if !os.IsExist(err) || !isStaleTempDir(dir) {
+			return false
+		}
+
+		_ = os.Remove(owner)
+	}
+
+	return false
+}
+
+// ReleaseTorCache records that the Tor instance of this
+// execution of Wahay doesn't use the Tor cache anymore
+func ReleaseTorCache() {
+	dir := TorCacheDir()
+	if pid, ok := ownerOf(dir); ok && pid == os.Getpid() {
+		_ = os.Remove(filepath.Join(dir, tempDirOwnerFile))
+	}
+}
+
+// ClearTorCache removes the content of the Tor cache directory. When a
+// Tor instance is using the cache, its files can't be removed, so the
+// cache is cleared the next time it's acquired instead. The returned
+// boolean indicates if the cache has been cleared now
+func ClearTorCache() (bool, error) {
+	dir := TorCacheDir()
+
+	pid, ok := ownerOf(dir)
+	if (ok && pid == os.Getpid()) || !AcquireTorCache() {
+		return false, ioutil.WriteFile(filepath.Join(dir, torCacheClearFile), nil, 0600)
+	}
+	defer ReleaseTorCache()
+
+	return true, clearDir(dir, tempDirOwnerFile)
+}
+
+// clearDir removes the content of the given directory,
+// except the entries with the given names
+func clearDir(dir string, keep ...string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := map[string]bool{}
+	for _, k := range keep {
+		kept[k] = true
+	}
+
+	for _, e := range entries {
+		if kept[e.Name()] {
+			continue
+		}
+
+		err := os.RemoveAll(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // TorDir returns the directory path for Tor
 func TorDir() string {
 	return filepath.Join(Dir(), "tor")
@@ -4,7 +4,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 
+	"github.com/prashantv/gostub"
 	. "gopkg.in/check.v1"
 )
 
@@ -88,3 +90,147 @@ func (cs *ConfigSuite) Test_SafeWrite_writesContentOnTempFile(c *C) {
 	_, err = os.Stat(fileName)
 	c.Assert(err, IsNil)
 }
+
+func (cs *ConfigSuite) Test_removeStaleTempDirsIn_removesOnlyTheTempDirsOfWahayThatAreNotRunning(c *C) {
+	dir := c.MkDir()
+
+	const deadProcess, runningProcess = 1001, 1002
+	origProcessIsRunning := processIsRunning
+	defer func() { processIsRunning = origProcessIsRunning }()
+	processIsRunning = func(pid int) bool {
+		return pid == runningProcess
+	}
+
+	owners := map[string]string{
+		"tor123":    strconv.Itoa(deadProcess),
+		"wahay456":  strconv.Itoa(deadProcess),
+		"mumble789": strconv.Itoa(runningProcess),
+		"tor42":     strconv.Itoa(os.Getpid()),
+		"wahay43":   "not a process",
+		"other123":  strconv.Itoa(deadProcess),
+	}
+
+	for name, owner := range owners {
+		c.Assert(os.Mkdir(filepath.Join(dir, name), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name, tempDirOwnerFile), []byte(owner), 0600), IsNil)
+	}
+	for _, name := range []string{"tor", "torrc", "mumble44"} {
+		c.Assert(os.Mkdir(filepath.Join(dir, name), 0700), IsNil)
+	}
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "mumble1"), []byte{}, 0600), IsNil)
+
+	removeStaleTempDirsIn(dir)
+
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, IsNil)
+
+	remaining := []string{}
+	for _, e := range entries {
+		remaining = append(remaining, e.Name())
+	}
+
+	c.Assert(remaining, DeepEquals, []string{"mumble1", "mumble44", "mumble789", "other123", "tor", "tor42", "torrc", "wahay43"})
+}
+
+func (cs *ConfigSuite) Test_TempDir_recordsTheProcessThatOwnsTheDirectory(c *C) {
+	d, err := TempDir(c.MkDir(), "tor")
+	c.Assert(err, IsNil)
+
+	owner, err := ioutil.ReadFile(filepath.Join(d, tempDirOwnerFile))
+	c.Assert(err, IsNil)
+	c.Assert(string(owner), Equals, strconv.Itoa(os.Getpid()))
+	c.Assert(isStaleTempDir(d), Equals, false)
+}
+
+func (cs *ConfigSuite) Test_isProcessRunning_findsThisProcess(c *C) {
+	c.Assert(isProcessRunning(os.Getpid()), Equals, true)
+}
+
+func (cs *ConfigSuite) Test_clearDir_removesTheContentButKeepsTheDirectory(c *C) {
+	dir := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(dir, "keys"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "cached-microdesc-consensus"), []byte("consensus"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "keys", "secret_id_key"), []byte("key"), 0600), IsNil)
+
+	c.Assert(clearDir(dir), IsNil)
+
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+
+	c.Assert(clearDir(filepath.Join(dir, "non-existent")), IsNil)
+}
+
+func writeTorCacheFiles(c *C, dir string) {
+	c.Assert(os.MkdirAll(filepath.Join(dir, "keys"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "cached-microdesc-consensus"), []byte("consensus"), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "lock"), []byte{}, 0600), IsNil)
+}
+
+func torCacheEntries(c *C) []string {
+	entries, err := os.ReadDir(TorCacheDir())
+	c.Assert(err, IsNil)
+
+	result := []string{}
+	for _, e := range entries {
+		result = append(result, e.Name())
+	}
+	return result
+}
+
+func (cs *ConfigSuite) Test_AcquireTorCache_failsWhileAnotherRunningExecutionUsesIt(c *C) {
+	defer gostub.Stub(&wahayDataDir, c.MkDir()).Reset()
+
+	runningProcess := os.Getpid() + 1
+	defer gostub.Stub(&processIsRunning, func(pid int) bool {
+		return pid == runningProcess
+	}).Reset()
+
+	owner := filepath.Join(TorCacheDir(), tempDirOwnerFile)
+	c.Assert(os.MkdirAll(TorCacheDir(), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(owner, []byte(strconv.Itoa(runningProcess)), 0600), IsNil)
+
+	c.Assert(AcquireTorCache(), Equals, false)
+
+	// The owner isn't running anymore
+	runningProcess = 0
+
+	c.Assert(AcquireTorCache(), Equals, true)
+	c.Assert(AcquireTorCache(), Equals, true)
+
+	content, err := ioutil.ReadFile(owner)
+	c.Assert(err, IsNil)
+	c.Assert(string(content), Equals, strconv.Itoa(os.Getpid()))
+
+	ReleaseTorCache()
+	c.Assert(FileExists(owner), Equals, false)
+}
+
+func (cs *ConfigSuite) Test_ClearTorCache_clearsTheCacheRightAwayWhenItIsNotInUse(c *C) {
+	defer gostub.Stub(&wahayDataDir, c.MkDir()).Reset()
+	writeTorCacheFiles(c, TorCacheDir())
+
+	cleared, err := ClearTorCache()
+
+	c.Assert(err, IsNil)
+	c.Assert(cleared, Equals, true)
+	c.Assert(torCacheEntries(c), HasLen, 0)
+}
+
+func (cs *ConfigSuite) Test_ClearTorCache_waitsUntilTheCacheIsAcquiredAgainWhenItIsInUse(c *C) {
+	defer gostub.Stub(&wahayDataDir, c.MkDir()).Reset()
+	writeTorCacheFiles(c, TorCacheDir())
+	c.Assert(AcquireTorCache(), Equals, true)
+
+	cleared, err := ClearTorCache()
+
+	c.Assert(err, IsNil)
+	c.Assert(cleared, Equals, false)
+	c.Assert(torCacheEntries(c), DeepEquals, []string{torCacheClearFile, tempDirOwnerFile, "cached-microdesc-consensus", "keys", "lock"})
+
+	ReleaseTorCache()
+	c.Assert(AcquireTorCache(), Equals, true)
+
+	c.Assert(torCacheEntries(c), DeepEquals, []string{tempDirOwnerFile})
+	ReleaseTorCache()
+}
@@ -0,0 +1,27 @@
+package config
+
+// HostingProtection contains the limits that protect the meetings hosted by
+// Wahay against connection floods, when they're not the default ones
+type HostingProtection struct {
+	// MaxStreams is the number of streams a circuit can open to the onion
+	// service of a meeting before Tor closes it. Zero disables the limit
+	MaxStreams int
+	// ProofOfWork enables the proof-of-work defenses of the onion
+	// services, when the version of Tor supports them
+	ProofOfWork bool
+	// RequestsPerSecond and RequestsBurst limit the requests accepted through
+	// each connection to the servers of a meeting. Zero disables the limit
+	RequestsPerSecond int
+	RequestsBurst     int
+}
+
+// GetHostingProtection returns the protection configured in the settings, if any
+func (a *ApplicationConfig) GetHostingProtection() *HostingProtection {
+	return a.HostingProtection
+}
+
+// SetHostingProtection sets the protection for the hosted meetings, or removes
+// it from the settings when nil is given, so the default one is used
+func (a *ApplicationConfig) SetHostingProtection(p *HostingProtection) {
+	a.HostingProtection = p
+}
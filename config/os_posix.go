@@ -3,7 +3,10 @@
 
 package config
 
-import "os/user"
+import (
+	"os/user"
+	"syscall"
+)
 
 // IsWindows returns true if this is running under windows
 func IsWindows() bool {
@@ -23,3 +26,10 @@ func localHome() string {
 	}
 	return ""
 }
+
+func isProcessRunning(pid int) bool {
+	// The signal 0 only checks if the process exists. It can belong
+	// to another user, in which case we are not allowed to signal it
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -49,3 +49,13 @@ func firstEnvironmentVariable(vs ...string) string {
 func localHome() string {
 	return firstEnvironmentVariable("HOMEPATH", "USERPROFILE")
 }
+
+func isProcessRunning(pid int) bool {
+	// On Windows, finding a process opens it, which fails if it doesn't exist
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// The authentication methods that can be used with the Tor control port
+const (
+	TorAuthAutomatic = ""
+	TorAuthNone      = "none"
+	TorAuthCookie    = "cookie"
+	TorAuthPassword  = "password"
+)
+
+// TorEndpoint describes a Tor instance running outside of Wahay, for example
+// in another container, to be used instead of looking for one or starting ours
+type TorEndpoint struct {
+	Host string
+	// ControlSocket is the path to the Unix domain socket of the control
+	// port. When it's given, it's used instead of ControlPort
+	ControlPort   int
+	ControlSocket string
+	SocksPort     int
+	// AuthMethod is one of the TorAuth constants. When it's automatic,
+	// every authentication method is tried
+	AuthMethod string
+	Password   string
+	// CookieFile is the path to the authentication cookie, for when the
+	// path reported by Tor is not the same in this computer
+	CookieFile string
+}
+
+// TorEndpointFromCommandLine returns the Tor endpoint given in the command
+// line, or nil when none of the arguments describing it was given
+func TorEndpointFromCommandLine() *TorEndpoint {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, "tor-") {
+			given = true
+		}
+	})
+
+	if !given {
+		return nil
+	}
+
+	auth := *TorAuth
+	if auth == TorAuthAutomatic && *TorControlPassword != "" {
+		auth = TorAuthPassword
+	}
+
+	return &TorEndpoint{
+		Host:          *TorHost,
+		ControlPort:   *TorPort,
+		ControlSocket: *TorControlSocket,
+		SocksPort:     *TorRoutePort,
+		AuthMethod:    auth,
+		Password:      *TorControlPassword,
+		CookieFile:    *TorCookieFile,
+	}
+}
+
+// GetTorEndpoint returns the Tor instance configured in the settings, if any
+func (a *ApplicationConfig) GetTorEndpoint() *TorEndpoint {
+	return a.TorEndpoint
+}
+
+// SetTorEndpoint sets the Tor instance to use, or
+// removes it from the settings when nil is given
+func (a *ApplicationConfig) SetTorEndpoint(e *TorEndpoint) {
+	a.TorEndpoint = e
+}
+
+// TorEndpointToUse returns the Tor instance that must be used, if any. The one
+// given in the command line takes precedence over the one in the settings
+func (a *ApplicationConfig) TorEndpointToUse() *TorEndpoint {
+	if e := TorEndpointFromCommandLine(); e != nil {
+		return e
+	}
+	return a.GetTorEndpoint()
+}
@@ -0,0 +1,44 @@
+package config
+
+// The types of proxy the Tor instance started by Wahay can connect through
+const (
+	TorProxyHTTPS  = "https"
+	TorProxySocks4 = "socks4"
+	TorProxySocks5 = "socks5"
+)
+
+// TorProxy describes the proxy that the Tor instance started by Wahay must
+// use to connect to the Tor network, for networks that only allow outbound
+// traffic through a proxy
+type TorProxy struct {
+	// Type is one of the TorProxy constants
+	Type    string
+	Address string
+	// Username and Password are only used when the proxy requires
+	// authentication. SOCKS 4 proxies don't support them
+	Username string
+	Password string
+}
+
+// GetTorProxy returns the proxy configured in the settings, if any
+func (a *ApplicationConfig) GetTorProxy() *TorProxy {
+	return a.TorProxy
+}
+
+// SetTorProxy sets the proxy for Tor to use, or
+// removes it from the settings when nil is given
+func (a *ApplicationConfig) SetTorProxy(p *TorProxy) {
+	a.TorProxy = p
+}
+
+// IsTorProxyFromEnvironment returns a boolean indicating if the proxy for
+// Tor should be taken from the environment variables, when none is configured
+func (a *ApplicationConfig) IsTorProxyFromEnvironment() bool {
+	return a.TorProxyFromEnvironment
+}
+
+// EnableTorProxyFromEnvironment sets the value for taking the
+// proxy for Tor from the environment variables
+func (a *ApplicationConfig) EnableTorProxyFromEnvironment(v bool) {
+	a.TorProxyFromEnvironment = v
+}
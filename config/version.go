@@ -0,0 +1,16 @@
+package config
+
+var applicationVersion = "UNKNOWN"
+
+// SetApplicationVersion sets the Wahay version that is announced
+// to other parties, for example to the participants of a hosted meeting
+func SetApplicationVersion(v string) {
+	if len(v) != 0 {
+		applicationVersion = v
+	}
+}
+
+// ApplicationVersion returns the Wahay version
+func ApplicationVersion() string {
+	return applicationVersion
+}
@@ -6,11 +6,13 @@ import (
 	"io"
 	"net"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/digitalautonomy/wahay/config"
 	"github.com/digitalautonomy/wahay/hosting"
+	"github.com/digitalautonomy/wahay/tor"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/proxy"
 )
@@ -20,6 +22,7 @@ type Forwarder struct {
 	mumblePort    int
 	ListeningPort int
 	LocalAddr     string
+	socksAddress  string
 	data          hosting.MeetingData
 	ctx           context.Context
 	cancel        context.CancelFunc
@@ -38,6 +41,7 @@ func NewForwarder(data hosting.MeetingData) *Forwarder {
 		mumblePort:    data.Port,
 		LocalAddr:     "127.0.0.1",
 		ListeningPort: assignPort(data),
+		socksAddress:  net.JoinHostPort("127.0.0.1", strconv.Itoa(config.DefaultRoutePort)),
 		data:          data,
 		pausing:       newPausing(),
 	}
@@ -96,15 +100,32 @@ func (f *Forwarder) setupListener() error {
 	return nil
 }
 
+// SetSocksAddress changes the address used to connect to Tor, for example
+// when the Tor instance has been restarted on a different port
+func (f *Forwarder) SetSocksAddress(addr string) {
+	f.pauseLock.Lock()
+	defer f.pauseLock.Unlock()
+
+	f.socksAddress = addr
+
+	if !f.isRunning {
+		return
+	}
+
+	if err := f.setupSocks5Dialer(); err != nil {
+		log.Errorf("Failed to set up socks5 dialer: %v", err)
+	}
+}
+
 func (f *Forwarder) setupSocks5Dialer() error {
-	socks5Addr := fmt.Sprintf("%s:%d", f.LocalAddr, config.DefaultRoutePort)
+	network, socks5Addr := tor.SplitAddress(f.socksAddress)
 	var err error
 
 	customDialer := &net.Dialer{
 		Timeout: 10 * time.Second,
 	}
 
-	f.dialer, err = proxy.SOCKS5("tcp", socks5Addr, nil, customDialer)
+	f.dialer, err = proxy.SOCKS5(network, socks5Addr, nil, customDialer)
 	if err != nil {
 		return fmt.Errorf("failed to create socks5 dialer: %v", err)
 	}
@@ -122,26 +143,50 @@ func (f *Forwarder) shutdownListener() {
 }
 
 func assignPort(data hosting.MeetingData) int {
-	if !data.IsHost {
+	if data.UsesForwarder() {
 		return config.GetRandomPort()
 	}
 	return data.Port
 }
 
+// isLocal returns a boolean indicating if the meeting server is in this
+// computer, so the connections are forwarded to it without going through Tor
+func (f *Forwarder) isLocal() bool {
+	return f.data.IsHost && f.data.ServerSocket != ""
+}
+
 func (f *Forwarder) HandleConnection(clientConn net.Conn) {
+	if f.isLocal() {
+		serverConn, err := net.Dial("unix", f.data.ServerSocket)
+		if err != nil {
+			log.Errorf("Failed to connect to the local Mumble server: %v\n", err)
+			_ = clientConn.Close()
+			return
+		}
+
+		f.forwardTraffic(clientConn, serverConn)
+		return
+	}
+
 	serverConn, err := f.dialer.Dial("tcp", fmt.Sprintf("%s:%d", f.OnionAddr, f.mumblePort))
 	if err != nil {
 		log.Errorf("Failed to connect to Mumble server via SOCKS5: %v\n", err)
 		return
 	}
 
-	tcpClientConn, _ := clientConn.(*net.TCPConn)
-	tcpServerConn, _ := serverConn.(*net.TCPConn)
+	f.forwardTraffic(clientConn, serverConn)
+}
 
-	f.forwardTraffic(tcpClientConn, tcpServerConn)
+// halfCloser is a connection that can stop sending while it keeps receiving,
+// as the TCP connections and the connections to Unix domain sockets
+type halfCloser interface {
+	net.Conn
+	CloseWrite() error
 }
 
-func (f *Forwarder) forwardTraffic(conn1, conn2 *net.TCPConn) {
+func (f *Forwarder) forwardTraffic(clientConn, serverConn net.Conn) {
+	conn1, _ := clientConn.(halfCloser)
+	conn2, _ := serverConn.(halfCloser)
 	if conn1 == nil || conn2 == nil {
 		return
 	}
@@ -150,7 +195,7 @@ func (f *Forwarder) forwardTraffic(conn1, conn2 *net.TCPConn) {
 
 	wg.Add(2)
 
-	copyConn := func(dst, src *net.TCPConn) {
+	copyConn := func(dst, src halfCloser) {
 		defer wg.Done()
 		defer dst.CloseWrite()
 		io.Copy(dst, src)
@@ -0,0 +1,149 @@
+package gui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/tor"
+	log "github.com/sirupsen/logrus"
+)
+
+// circuitDiagnosticsRefreshInterval is how often the age of the
+// circuits is updated while the diagnostics window is open
+const circuitDiagnosticsRefreshInterval = 5 * time.Second
+
+// openCircuitDiagnosticsWindow shows the Tor circuits carrying the traffic of
+// the given meeting, through the given Tor instance, and lets the user ask
+// for new circuits when the current ones seem to be the cause of a bad quality
+func (u *gtkUI) openCircuitDiagnosticsWindow(t tor.Instance, meetingID string, parent gtki.Window) {
+	if t == nil {
+		u.reportError(circuitDiagnosticsErrorMessage(tor.ErrCircuitDiagnosticsUnavailable))
+		return
+	}
+
+	builder := u.g.uiBuilderFor("CircuitDiagnostics")
+
+	builder.i18nProperties(
+		"title", "circuitDiagnosticsWindow",
+		"label", "lblCircuitsDescription",
+		"button", "btnNewCircuits",
+		"tooltip", "btnNewCircuits",
+		"button", "btnCloseCircuitDiagnostics",
+	)
+
+	win := builder.get("circuitDiagnosticsWindow").(gtki.Window)
+	lblCircuits := builder.get("lblCircuits").(gtki.Label)
+	lblFailures := builder.get("lblCircuitFailures").(gtki.Label)
+	lblMessage := builder.get("lblNewCircuitsMessage").(gtki.Label)
+
+	// Both the diagnostics and the state of the window
+	// are only used from the UI thread
+	var diagnostics tor.CircuitDiagnostics
+	closed := false
+	stop := make(chan bool)
+
+	refresh := func() {
+		u.doInUIThread(func() {
+			if diagnostics == nil || closed {
+				return
+			}
+			lblCircuits.SetText(circuitsDescription(diagnostics.Circuits()))
+			lblFailures.SetText(i18n().Sprintf("Circuits that couldn't be built: %d", diagnostics.Failures()))
+		})
+	}
+
+	diagnostics, err := t.DiagnoseCircuits(meetingID, refresh)
+	if err != nil {
+		u.reportError(circuitDiagnosticsErrorMessage(err))
+		return
+	}
+
+	closeWindow := func() {
+		if closed {
+			return
+		}
+		closed = true
+		close(stop)
+		diagnostics.Close()
+		win.Destroy()
+	}
+
+	builder.ConnectSignals(map[string]interface{}{
+		"on_close_window_signal": closeWindow,
+		"on_close":               closeWindow,
+		"on_new_circuits": func() {
+			lblMessage.Hide()
+			go func() {
+				err := diagnostics.NewCircuits()
+				if err != nil {
+					log.WithFields(log.Fields{
+						"error": err,
+					}).Error("openCircuitDiagnosticsWindow(): new circuits can't be requested")
+					u.doInUIThread(func() {
+						lblMessage.SetText(i18n().Sprintf("Tor didn't accept the request for new circuits."))
+						lblMessage.Show()
+					})
+				}
+			}()
+		},
+	})
+
+	go func() {
+		ticker := time.NewTicker(circuitDiagnosticsRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+
+	refresh()
+
+	if parent != nil {
+		win.SetTransientFor(parent)
+	}
+	win.Show()
+}
+
+// circuitsDescription returns the text describing the given circuits,
+// one line for each of them
+func circuitsDescription(circuits []tor.Circuit) string {
+	if len(circuits) == 0 {
+		return i18n().Sprintf("There are no circuits for this meeting right now.")
+	}
+
+	lines := make([]string, 0, len(circuits))
+	for _, c := range circuits {
+		lines = append(lines, circuitDescription(c))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func circuitDescription(c tor.Circuit) string {
+	age := c.Age().Round(time.Second)
+
+	switch {
+	case c.Status != tor.CircuitBuilt:
+		return i18n().Sprintf("Circuit %s: being built, %d hops so far, for %s", c.ID, c.Hops(), age)
+	case c.BuildTime > 0:
+		return i18n().Sprintf("Circuit %s: %d hops, open for %s, built in %s", c.ID, c.Hops(), age,
+			c.BuildTime.Round(10*time.Millisecond))
+	}
+
+	return i18n().Sprintf("Circuit %s: %d hops, open for %s", c.ID, c.Hops(), age)
+}
+
+func circuitDiagnosticsErrorMessage(err error) string {
+	if err == tor.ErrCircuitDiagnosticsUnavailable {
+		return i18n().Sprintf("The Tor circuits of the meeting can't be shown, because the Tor " +
+			"control port doesn't allow following them.")
+	}
+	return i18n().Sprintf("Something went wrong: %s", err)
+}
@@ -3,12 +3,14 @@ package gui
 import (
 	"fmt"
 	"math/rand"
+	"net/url"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/config"
 	"github.com/digitalautonomy/wahay/hosting"
 	"github.com/digitalautonomy/wahay/tor"
 )
@@ -17,11 +19,14 @@ type hostData struct {
 	u                 *gtkUI
 	mumble            tor.Service
 	service           hosting.Service
+	tor               tor.Instance
+	singleHop         bool
 	asSuperUser       bool
 	superUserPassword string
 	autoJoin          bool
 	meetingUsername   string
 	meetingPassword   string
+	includePassword   bool
 	currentWindow     gtki.Window
 	next              func()
 }
@@ -62,7 +67,7 @@ func (u *gtkUI) realHostMeetingHandler() {
 
 	if err != nil {
 		// TODO: we should check if u.servers !== nil to reset it
-		h.u.reportError(i18n().Sprintf("Something went wrong: %s", err))
+		h.u.reportError(hostingErrorMessage(err))
 		u.switchToMainWindow()
 		return
 	}
@@ -95,7 +100,10 @@ func (h *hostData) showMeetingControls() {
 		"button", "btnCopyMeetingID",
 		"tooltip", "btnJoinMeeting",
 		"tooltip", "btnInviteOthers",
-		"tooltip", "btnFinishMeeting")
+		"tooltip", "btnFinishMeeting",
+		"label", "lblSingleHopMode")
+
+	builder.get("lblSingleHopMode").(gtki.Label).SetVisible(h.singleHop)
 
 	builder.ConnectSignals(map[string]interface{}{
 		"on_close_window_signal": h.finishMeetingReal,
@@ -153,8 +161,10 @@ func (h *hostData) joinMeetingHostHelper(validOpChannel chan bool) {
 			}
 			return h.meetingPassword
 		}(),
-		Username: h.meetingUsername,
-		IsHost:   true,
+		Username:     h.meetingUsername,
+		Fingerprint:  h.service.Fingerprint(),
+		IsHost:       true,
+		ServerSocket: h.service.ServerSocket(),
 	}
 
 	var err error
@@ -208,6 +218,10 @@ func (u *gtkUI) getCurrentHostMeetingWindow() *uiBuilder {
 		"tooltip", "btnLeaveMeeting",
 		"button", "btnInviteOthers",
 		"label", "lblTipPush",
+		"tooltip", "lblAuthenticationString",
+		"label", "lblSingleHopMode",
+		"button", "btnCircuitDiagnostics",
+		"tooltip", "btnCircuitDiagnostics",
 	)
 
 	return builder
@@ -218,6 +232,9 @@ func (h *hostData) openHostJoinMeetingWindow() {
 
 	builder := h.u.getCurrentHostMeetingWindow()
 	win := builder.get("hostMeetingWindow").(gtki.ApplicationWindow)
+
+	showAuthenticationString(builder, h.service.ID(), h.service.Fingerprint())
+	builder.get("lblSingleHopMode").(gtki.Label).SetVisible(h.singleHop)
 	onInviteOpen := func(d gtki.Window) {
 		h.currentWindow = d
 		// Hide the current window because we don't want
@@ -241,6 +258,9 @@ func (h *hostData) openHostJoinMeetingWindow() {
 		"on_invite_others": func() {
 			h.onInviteParticipants(onInviteOpen, onInviteClose)
 		},
+		"on_circuit_diagnostics": func() {
+			h.u.openCircuitDiagnosticsWindow(h.meetingTor(), h.service.ID(), win)
+		},
 	})
 
 	h.u.connectShortcutsCurrentHostMeetingWindow(win, h)
@@ -266,9 +286,23 @@ func (h *hostData) createNewService(err chan error) {
 	}
 
 	h.u.waitForTorInstance(func(t tor.Instance) {
+		singleHop := h.u.config.IsSingleHopHostingEnabled()
+		if singleHop || h.u.config.IsMeetingIsolationEnabled() {
+			mt, e := h.acquireTor(singleHop)
+			if e != nil {
+				log.Errorf("createNewService(): %s", e)
+				err <- e
+				return
+			}
+			t = mt
+		}
+
+		h.u.servers.SetProtection(meetingProtection(h.u.config.GetHostingProtection()))
+
 		s, e := h.u.servers.NewService(port, t)
 		if e != nil {
 			log.Errorf("createNewService(): %s", e)
+			h.releaseTor()
 			err <- e
 			return
 		}
@@ -281,6 +315,88 @@ func (h *hostData) createNewService(err chan error) {
 	})
 }
 
+// meetingProtection returns the protection for a new meeting, with the
+// limits configured in the settings or the default ones
+func meetingProtection(c *config.HostingProtection) hosting.Protection {
+	p := hosting.DefaultProtection()
+	if c == nil {
+		return p
+	}
+
+	p.Onion.MaxStreams = c.MaxStreams
+	p.Onion.ProofOfWork = c.ProofOfWork
+	p.RequestsPerSecond = float64(c.RequestsPerSecond)
+	p.RequestsBurst = c.RequestsBurst
+
+	return p
+}
+
+// hostingProtectionSettings returns the limits of the given
+// protection, as they're shown and saved in the settings
+func hostingProtectionSettings(p hosting.Protection) *config.HostingProtection {
+	return &config.HostingProtection{
+		MaxStreams:        p.Onion.MaxStreams,
+		ProofOfWork:       p.Onion.ProofOfWork,
+		RequestsPerSecond: int(p.RequestsPerSecond),
+		RequestsBurst:     p.RequestsBurst,
+	}
+}
+
+// acquireTor starts the Tor instance dedicated to the meeting,
+// showing its progress in the loading window. A single-hop instance
+// is always dedicated, since it can't be shared with our client side
+func (h *hostData) acquireTor(singleHop bool) (tor.Instance, error) {
+	if h.u.torPool == nil {
+		return nil, tor.ErrMeetingIsolationUnavailable
+	}
+
+	acquire := h.u.torPool.Acquire
+	if singleHop {
+		acquire = h.u.torPool.AcquireSingleHop
+	}
+
+	t, err := acquire(h.u.onTorBootstrapProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	h.tor = t
+	h.singleHop = singleHop
+
+	return t, nil
+}
+
+// meetingTor returns the Tor instance hosting the onion service of the meeting
+func (h *hostData) meetingTor() tor.Instance {
+	if h.tor != nil {
+		return h.tor
+	}
+	return h.u.tor
+}
+
+// releaseTor stops the Tor instance dedicated to the meeting, if any
+func (h *hostData) releaseTor() {
+	if h.tor != nil {
+		h.u.torPool.Release(h.tor)
+		h.tor = nil
+		h.singleHop = false
+	}
+}
+
+func hostingErrorMessage(err error) string {
+	switch err {
+	case tor.ErrMeetingIsolationUnavailable:
+		return i18n().Sprintf("The meeting can't have its own Tor instance, because Wahay is using " +
+			"a Tor instance it didn't start.\n\nPlease disable the options to host meetings on their own Tor " +
+			"instance, or in single-hop mode, in the settings to host the meeting.")
+	case tor.ErrTooManyTorInstances:
+		return i18n().Sprintf("The meeting can't have its own Tor instance, because the maximum number " +
+			"of Tor instances for meetings is running.\n\nPlease finish another meeting, or increase " +
+			"the maximum in the settings.")
+	}
+	return i18n().Sprintf("Something went wrong: %s", err)
+}
+
 func (h *hostData) createNewConferenceRoom(complete chan bool) {
 	var su hosting.SuperUserData
 	if h.asSuperUser {
@@ -311,6 +427,8 @@ func (h *hostData) finishMeetingReal() {
 		h.u.reportError(i18n().Sprintf("The meeting can't be closed: %s", err))
 	}
 
+	h.releaseTor()
+
 	if h.currentWindow != nil {
 		h.currentWindow.Destroy()
 		h.currentWindow = nil
@@ -344,7 +462,7 @@ func (h *hostData) leaveHostMeeting() {
 }
 
 func (h *hostData) copyMeetingIDToClipboard(builder *uiBuilder, label string) {
-	err := h.u.copyToClipboard(h.service.URL())
+	err := h.u.copyToClipboard(h.service.PinnedURL())
 	if err != nil {
 		h.u.reportError(err.Error())
 		return
@@ -389,41 +507,54 @@ func (h *hostData) sendInvitationByEmail(builder *uiBuilder) {
 
 func (h *hostData) getInvitationEmailURI() string {
 	subject := h.getInvitationSubject()
-	body := h.getInvitationText()
+	body := encodeInvitationForURI(h.getInvitationText())
 	uri := fmt.Sprintf("mailto:?subject=%s&body=%s", subject, body)
 	return uri
 }
 
 func (h *hostData) getInvitationGmailURI() string {
 	subject := h.getInvitationSubject()
-	body := h.getInvitationText()
+	body := encodeInvitationForURI(h.getInvitationText())
 	uri := fmt.Sprintf("%s?view=cm&fs=1&tf=1&to=&su=%s&body=%s", gmailURL, subject, body)
 	return uri
 }
 
 func (h *hostData) getInvitationYahooURI() string {
 	subject := h.getInvitationSubject()
-	body := h.getInvitationText()
+	body := encodeInvitationForURI(h.getInvitationText())
 	uri := fmt.Sprintf("%s?To=&Subj=%s&Body=%s", yahooURL, subject, body)
 	return uri
 }
 
 func (h *hostData) getInvitationMicrosoftURI() string {
 	subject := h.getInvitationSubject()
-	body := h.getInvitationText()
+	body := encodeInvitationForURI(h.getInvitationText())
 	uri := fmt.Sprintf("%s?rru=compose&subject=%s&body=%s&to=#page=Compose", outlookURL, subject, body)
 	return uri
 }
 
+// encodeInvitationForURI encodes the invitation text to be used inside an URI,
+// otherwise the fingerprint separator of the meeting ID or the invitation
+// link would cut the content
+func encodeInvitationForURI(body string) string {
+	return strings.ReplaceAll(url.QueryEscape(body), "+", "%20")
+}
+
 func (h *hostData) getInvitationSubject() string {
 	return i18n().Sprintf("Join Wahay Meeting")
 }
 
 func (h *hostData) getInvitationText() string {
-	it := i18n().Sprintf("Please join the Wahay meeting with the following details:") + "%0D%0A%0D%0A"
+	it := i18n().Sprintf("Please join the Wahay meeting with the following details:") + "\n\n"
 	if h.service.URL() != "" {
-		it = i18n().Sprintf("%sMeeting ID: %s", it, h.service.URL())
+		it = i18n().Sprintf("%sMeeting ID: %s", it, h.service.PinnedURL())
+	}
+
+	link := h.getInvitationLink()
+	if link != "" {
+		it = i18n().Sprintf("%s\n\nIf you have Wahay installed, you can also join using this link: %s", it, link)
 	}
+
 	return it
 }
 
@@ -454,8 +585,10 @@ func (u *gtkUI) getConfigureMeetingWindow() *uiBuilder {
 		"label", "labelUsername",
 		"label", "lblMessage",
 		"label", "labelMeetingPassword",
+		"label", "labelMeetingTitle",
 		"placeholder", "inpMeetingUsername",
 		"placeholder", "inpMeetingPassword",
+		"placeholder", "inpMeetingTitle",
 		"checkbox", "chkAutoJoin",
 		"checkbox", "chkAutoJoinSuperUser",
 		"tooltip", "chkAutoJoin",
@@ -520,7 +653,7 @@ func (h *hostData) showMeetingConfiguration() {
 	if err != nil {
 		log.Printf("meeting id error: %s", err)
 	}
-	_ = meetingID.SetProperty("label", h.service.URL())
+	_ = meetingID.SetProperty("label", h.service.PinnedURL())
 
 	h.u.switchToWindow(win)
 }
@@ -528,8 +661,9 @@ func (h *hostData) showMeetingConfiguration() {
 func (h *hostData) handleOnStartMeeting(b *uiBuilder) {
 	username := b.get("inpMeetingUsername").(gtki.Entry)
 	password := b.get("inpMeetingPassword").(gtki.Entry)
+	title := b.get("inpMeetingTitle").(gtki.Entry)
 
-	h.handlerOnStartMeeting(username, password)
+	h.handlerOnStartMeeting(username, password, title)
 }
 
 func (h *hostData) seti18nProperties(b *uiBuilder) {
@@ -537,6 +671,7 @@ func (h *hostData) seti18nProperties(b *uiBuilder) {
 		"label", "labelMeetingID",
 		"label", "labelUsername",
 		"label", "labelMeetingPassword",
+		"label", "labelMeetingTitle",
 		"label", "lblMessage")
 }
 
@@ -558,10 +693,13 @@ func (h *hostData) handlerOnAutoJoinToggled(ch gtki.CheckButton, b gtki.Button)
 	h.changeStartButtonText(b)
 }
 
-func (h *hostData) handlerOnStartMeeting(u, p gtki.Entry) {
+func (h *hostData) handlerOnStartMeeting(u, p, t gtki.Entry) {
 	h.meetingUsername, _ = u.GetText()
 	h.meetingPassword, _ = p.GetText()
 
+	title, _ := t.GetText()
+	h.service.SetTitle(strings.TrimSpace(title))
+
 	if h.meetingUsername == "" {
 		h.meetingUsername = getRandomName()
 	}
@@ -624,18 +762,32 @@ func (h *hostData) getInvitePeopleBuilder() *uiBuilder {
 		"label", "lblGmail",
 		"label", "lblYahoo",
 		"label", "lblOutlook",
+		"label", "lblMeetingWordsTitle",
+		"label", "lblQRCodeTitle",
+		"checkbox", "chkIncludePassword",
+		"tooltip", "chkIncludePassword",
 		"button", "btnCopyMeetingID",
-		"button", "btnCopyInvitation")
+		"button", "btnCopyInvitation",
+		"button", "btnCopyInvitationLink",
+		"button", "btnSaveInvitationFile",
+		"button", "btnExportQRCode")
+
+	btnCopyInvitationLink := builder.get("btnCopyInvitationLink").(gtki.Button)
+	btnCopyInvitationLink.SetVisible(h.u.isCopyToClipboardSupported())
+
+	chkIncludePassword := builder.get("chkIncludePassword").(gtki.CheckButton)
+	chkIncludePassword.SetActive(h.includePassword)
+	chkIncludePassword.SetVisible(h.meetingPassword != "")
+
+	h.showMeetingIDAsWords(builder)
+	h.showInvitationQRCode(builder)
 
 	btnEmail := builder.get("btnEmail").(gtki.LinkButton)
 	btnGmail := builder.get("btnGmail").(gtki.LinkButton)
 	btnYahoo := builder.get("btnYahoo").(gtki.LinkButton)
 	btnOutlook := builder.get("btnMicrosoft").(gtki.LinkButton)
 
-	_ = btnEmail.SetProperty("uri", h.getInvitationEmailURI())
-	_ = btnGmail.SetProperty("uri", h.getInvitationGmailURI())
-	_ = btnYahoo.SetProperty("uri", h.getInvitationYahooURI())
-	_ = btnOutlook.SetProperty("uri", h.getInvitationMicrosoftURI())
+	h.updateInvitationEmailLinks(builder)
 
 	imagePixBuf, _ := h.u.g.getImagePixbufForSize("email.png", 100)
 	widgetImage, _ := h.u.g.gtk.ImageNewFromPixbuf(imagePixBuf)
@@ -656,6 +808,32 @@ func (h *hostData) getInvitePeopleBuilder() *uiBuilder {
 	return builder
 }
 
+func (h *hostData) showMeetingIDAsWords(builder *uiBuilder) {
+	words, err := hosting.MeetingIDAsWords(h.service.URL())
+	if err != nil {
+		log.Warnf("showMeetingIDAsWords(): %s", err)
+		return
+	}
+
+	lblMeetingWords := builder.get("lblMeetingWords").(gtki.Label)
+	lblMeetingWords.SetText(words)
+
+	box := builder.get("boxMeetingWords").(gtki.Box)
+	box.SetVisible(true)
+}
+
+func (h *hostData) updateInvitationEmailLinks(builder *uiBuilder) {
+	btnEmail := builder.get("btnEmail").(gtki.LinkButton)
+	btnGmail := builder.get("btnGmail").(gtki.LinkButton)
+	btnYahoo := builder.get("btnYahoo").(gtki.LinkButton)
+	btnOutlook := builder.get("btnMicrosoft").(gtki.LinkButton)
+
+	_ = btnEmail.SetProperty("uri", h.getInvitationEmailURI())
+	_ = btnGmail.SetProperty("uri", h.getInvitationGmailURI())
+	_ = btnYahoo.SetProperty("uri", h.getInvitationYahooURI())
+	_ = btnOutlook.SetProperty("uri", h.getInvitationMicrosoftURI())
+}
+
 // TODO: review this function and make a more pretty solution
 func (h *hostData) onInviteParticipants(onOpen func(d gtki.Window), onClose func(d gtki.Window)) {
 	builder := h.getInvitePeopleBuilder()
@@ -682,6 +860,20 @@ func (h *hostData) onInviteParticipants(onOpen func(d gtki.Window), onClose func
 		"on_copy_invitation": func() {
 			h.copyInvitationToClipboard(builder)
 		},
+		"on_copy_invitation_link": func() {
+			h.copyInvitationLinkToClipboard(builder)
+		},
+		"on_save_invitation_file": func() {
+			h.saveInvitationFile(builder)
+		},
+		"on_export_qr_code": func() {
+			h.exportInvitationQRCode(builder)
+		},
+		"on_include_password_toggled": func() {
+			h.includePassword = builder.get("chkIncludePassword").(gtki.CheckButton).GetActive()
+			h.updateInvitationEmailLinks(builder)
+			h.showInvitationQRCode(builder)
+		},
 	})
 
 	if onOpen == nil {
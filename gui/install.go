@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -38,6 +39,7 @@ func (u *gtkUI) ensureInstallation() {
 
 	i.ensureApplicationIcons()
 	i.ensureApplicationDesktop()
+	i.ensureInvitationHandler()
 }
 
 var iconSizes = []int{16, 32, 48, 128, 256}
@@ -96,6 +98,48 @@ func (i *installation) ensureApplicationDesktop() {
 	}
 }
 
+// ensureInvitationHandler registers the invitation files mime type and
+// makes the desktop environment aware of the wahay:// URI handler
+func (i *installation) ensureInvitationHandler() {
+	mimeDir := filepath.Join(i.dataHome, "mime")
+	dir := filepath.Join(mimeDir, "packages")
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"mimeDir": dir,
+		}).Errorf("ensureInvitationHandler(): %s", err.Error())
+		return
+	}
+
+	fileName := filepath.Join(dir, "wahay-invitation.xml")
+	err = ioutil.WriteFile(fileName, []byte(getConfigFileFor("wahay-invitation", ".xml")), 0600)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"mimeFileName": fileName,
+		}).Errorf("ensureInvitationHandler(): %s", err.Error())
+		return
+	}
+
+	runIfAvailable("update-mime-database", mimeDir)
+	runIfAvailable("update-desktop-database", filepath.Join(i.dataHome, "applications"))
+}
+
+func runIfAvailable(command string, args ...string) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		log.Debugf("runIfAvailable(): %s is not available", command)
+		return
+	}
+
+	/* #nosec G204 */
+	err = exec.Command(path, args...).Run()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"command": command,
+		}).Debugf("runIfAvailable(): %s", err.Error())
+	}
+}
+
 func (i *installation) generateDesktopFile() string {
 	path, _ := osext.Executable()
 	icon := i.iconProvider.fileNameNoExt()
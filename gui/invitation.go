@@ -0,0 +1,204 @@
+package gui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/hosting"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (h *hostData) getInvitation() *hosting.Invitation {
+	i, err := h.service.Invitation(h.includePassword)
+	if err != nil {
+		log.Errorf("getInvitation(): %s", err)
+		return nil
+	}
+	return i
+}
+
+func (h *hostData) getInvitationLink() string {
+	i := h.getInvitation()
+	if i == nil {
+		return ""
+	}
+	return i.URI()
+}
+
+func (h *hostData) copyInvitationLinkToClipboard(builder *uiBuilder) {
+	link := h.getInvitationLink()
+	if link == "" {
+		h.u.reportError(i18n().Sprintf("The invitation link can't be created"))
+		return
+	}
+
+	err := h.u.copyToClipboard(link)
+	if err != nil {
+		h.u.reportError(err.Error())
+		return
+	}
+
+	lblMessage := builder.get("lblMessage").(gtki.Label)
+	_ = lblMessage.SetProperty("visible", false)
+
+	go func() {
+		h.u.messageToLabel(lblMessage, i18n().Sprintf("The invitation link has been copied to the clipboard"), 5)
+	}()
+}
+
+func (h *hostData) saveInvitationFile(builder *uiBuilder) {
+	i := h.getInvitation()
+	if i == nil {
+		h.u.reportError(i18n().Sprintf("The invitation file can't be created"))
+		return
+	}
+
+	content, err := i.File()
+	if err != nil {
+		h.u.reportError(i18n().Sprintf("The invitation file can't be created"))
+		return
+	}
+
+	go func() {
+		ok, fileName := h.u.getSaveFilePath(invitationFileName(i))
+		if !ok {
+			return
+		}
+
+		if !strings.HasSuffix(fileName, hosting.InvitationFileExtension) {
+			fileName = fileName + hosting.InvitationFileExtension
+		}
+
+		err := os.WriteFile(fileName, content, 0600)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"fileName": fileName,
+			}).Errorf("saveInvitationFile(): %s", err)
+			h.u.doInUIThread(func() {
+				h.u.reportError(i18n().Sprintf("The invitation file can't be saved: %s", err))
+			})
+			return
+		}
+
+		lblMessage := builder.get("lblMessage").(gtki.Label)
+		h.u.messageToLabel(lblMessage, i18n().Sprintf("The invitation file has been saved"), 5)
+	}()
+}
+
+func invitationFileName(i *hosting.Invitation) string {
+	name := "meeting"
+	if i != nil && len(i.Title) != 0 {
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`/\:*?"<>|`, r) {
+				return '_'
+			}
+			return r
+		}, i.Title)
+	}
+	return name + hosting.InvitationFileExtension
+}
+
+func (u *gtkUI) getSaveFilePath(suggestedName string) (ok bool, path string) {
+	channel := make(chan string)
+	errChannel := make(chan bool)
+	go u.showSaveFilePathDialog(suggestedName, channel, errChannel)
+	select {
+	case v := <-channel:
+		return true, v
+	case <-errChannel:
+		return false, ""
+	}
+}
+
+func (u *gtkUI) showSaveFilePathDialog(suggestedName string, channel chan string, errChannel chan bool) {
+	u.doInUIThread(func() {
+		dialog, err := u.g.gtk.FileChooserDialogNewWith2Buttons(
+			i18n().Sprintf("Save file"),
+			u.currentWindow,
+			gtki.FILE_CHOOSER_ACTION_SAVE,
+			i18n().Sprintf("Cancel"),
+			gtki.RESPONSE_CANCEL,
+			i18n().Sprintf("Save"),
+			gtki.RESPONSE_ACCEPT)
+
+		if err != nil {
+			errChannel <- true
+			return
+		}
+
+		chooser := (dialog).(gtki.FileChooser)
+		chooser.SetDoOverwriteConfirmation(true)
+		chooser.SetCurrentName(suggestedName)
+
+		if u.currentWindow != nil {
+			dialog.SetTransientFor(u.currentWindow)
+		}
+
+		dialog.Present()
+
+		res := dialog.Run()
+
+		if gtki.ResponseType(res) == gtki.RESPONSE_ACCEPT {
+			channel <- dialog.GetFilename()
+		} else {
+			errChannel <- true
+		}
+
+		dialog.Destroy()
+	})
+}
+
+func invitationErrorMessage(err error) string {
+	switch err {
+	case hosting.ErrInvitationExpired:
+		return i18n().Sprintf("This invitation has expired. Please ask the meeting host for a new one.")
+	case hosting.ErrIncompatibleInvitation:
+		return i18n().Sprintf("This invitation has been created with an incompatible version of Wahay.")
+	case hosting.ErrInvalidInvitationSignature:
+		return i18n().Sprintf("The invitation hasn't been signed by the meeting server. " +
+			"Someone could have modified it, so Wahay won't join this meeting.")
+	}
+	return i18n().Sprintf("The invitation is not valid.")
+}
+
+// onOpen is called with the invitations given when starting Wahay, for
+// example when clicking a wahay: link, even if Wahay was already running
+func (u *gtkUI) onOpen(invitations []string) {
+	u.pendingInvitations = append(u.pendingInvitations, invitations...)
+
+	if u.mainWindow == nil {
+		u.onActivate()
+		return
+	}
+
+	u.openPendingInvitations()
+}
+
+// openPendingInvitations opens the join window with the last invitation
+// received, since only one meeting can be joined at a time
+func (u *gtkUI) openPendingInvitations() {
+	if len(u.pendingInvitations) == 0 {
+		return
+	}
+
+	last := u.pendingInvitations[len(u.pendingInvitations)-1]
+	u.pendingInvitations = nil
+
+	u.openInvitation(last)
+}
+
+func (u *gtkUI) openInvitation(uriOrPath string) {
+	i, err := hosting.ReadInvitation(uriOrPath)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"invitation": uriOrPath,
+		}).Errorf("openInvitation(): %s", err)
+		u.reportError(invitationErrorMessage(err))
+		return
+	}
+
+	u.hideMainWindow()
+	u.openJoinWindowWithInvitation(i)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/client"
 	"github.com/digitalautonomy/wahay/hosting"
 	"github.com/digitalautonomy/wahay/tor"
 
@@ -32,7 +33,8 @@ func (u *gtkUI) getInviteCodeEntities() (gtki.Window, *uiBuilder) {
 		"placeholder", "entMeetingPassword",
 		"button", "btnCancel",
 		"button", "btnJoin",
-		"tooltip", "btnJoin")
+		"tooltip", "btnJoin",
+		"button", "btnReadQRCode")
 
 	win := builder.get("inviteWindow").(gtki.ApplicationWindow)
 
@@ -50,12 +52,15 @@ func (u *gtkUI) getCurrentMeetingWindow() *uiBuilder {
 		"button", "btnLeaveMeeting",
 		"tooltip", "btnLeaveMeeting",
 		"label", "lblTipPush",
+		"tooltip", "lblAuthenticationString",
+		"button", "btnCircuitDiagnostics",
+		"tooltip", "btnCircuitDiagnostics",
 	)
 
 	return builder
 }
 
-func (u *gtkUI) openCurrentMeetingWindow(m tor.Service) {
+func (u *gtkUI) openCurrentMeetingWindow(m tor.Service, data hosting.MeetingData) {
 	if m.IsClosed() {
 		u.reportError(i18n().Sprintf("The Mumble process is down"))
 	}
@@ -65,6 +70,8 @@ func (u *gtkUI) openCurrentMeetingWindow(m tor.Service) {
 	builder := u.getCurrentMeetingWindow()
 	win := builder.get("currentMeetingWindow").(gtki.ApplicationWindow)
 
+	showAuthenticationString(builder, data.MeetingID, u.client.CertificateFingerprint())
+
 	builder.ConnectSignals(map[string]interface{}{
 		"on_close_window_signal": func() {
 			u.leaveMeeting(m)
@@ -73,6 +80,9 @@ func (u *gtkUI) openCurrentMeetingWindow(m tor.Service) {
 		"on_leave_meeting": func() {
 			u.leaveMeeting(m)
 		},
+		"on_circuit_diagnostics": func() {
+			u.openCircuitDiagnosticsWindow(u.tor, data.MeetingID, win)
+		},
 	})
 
 	u.connectShortcutsCurrentMeetingWindow(win, m)
@@ -80,6 +90,23 @@ func (u *gtkUI) openCurrentMeetingWindow(m tor.Service) {
 	u.switchToWindow(win)
 }
 
+// showAuthenticationString displays the words participants can read aloud to
+// confirm they are connected to the same meeting server
+func showAuthenticationString(builder *uiBuilder, meetingID, fingerprint string) {
+	words, err := hosting.ShortAuthenticationString(meetingID, fingerprint)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"meetingID":   meetingID,
+			"fingerprint": fingerprint,
+		}).Warnf("showAuthenticationString(): %s", err)
+		return
+	}
+
+	lbl := builder.get("lblAuthenticationString").(gtki.Label)
+	lbl.SetText(i18n().Sprintf("Security words: %s", strings.Join(words, " ")))
+	lbl.SetVisible(true)
+}
+
 func (u *gtkUI) joinMeetingHandler(data hosting.MeetingData) {
 	if len(data.MeetingID) == 0 {
 		u.openErrorDialog(i18n().Sprintf("The Meeting ID cannot be blank"))
@@ -94,6 +121,69 @@ func (u *gtkUI) joinMeetingHandler(data hosting.MeetingData) {
 	u.hideCurrentWindow()
 	u.displayLoadingWindow()
 
+	m, err := u.requestMeetingMetadata(data)
+
+	u.hideLoadingWindow()
+
+	if err == client.ErrCertificateMismatch {
+		u.openErrorDialog(certificateMismatchMessage())
+		u.showMainWindow()
+		return
+	}
+
+	if err == hosting.ErrInvalidInvitationSignature {
+		u.openErrorDialog(invitationErrorMessage(err))
+		u.showMainWindow()
+		return
+	}
+
+	if err == nil {
+		err = m.CheckCompatibility()
+		if err != nil {
+			u.openErrorDialog(i18n().Sprintf("The meeting can't be joined because it was created "+
+				"with an incompatible version of Wahay (%s)", m.WahayVersion))
+			u.showMainWindow()
+			return
+		}
+
+		if len(m.Title) != 0 {
+			u.doInUIThread(func() {
+				u.showConfirmation(func(op bool) {
+					if !op {
+						u.showMainWindow()
+						return
+					}
+					go u.launchMeetingClient(data)
+				}, i18n().Sprintf("You are about to join the meeting \"%s\". Do you want to continue?", m.Title))
+			})
+			return
+		}
+	}
+
+	u.launchMeetingClient(data)
+}
+
+// requestMeetingMetadata returns the information the host shares about the meeting.
+// Any error here is not fatal, since hosts running old versions of Wahay
+// don't provide it and the certificate will be requested again when launching Mumble
+func (u *gtkUI) requestMeetingMetadata(data hosting.MeetingData) (*hosting.MeetingMetadata, error) {
+	if u.client == nil || !u.client.IsValid() {
+		return nil, errors.New("error: no client to run")
+	}
+
+	m, err := u.client.MeetingMetadata(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"meetingID": data.MeetingID,
+		}).Errorf("requestMeetingMetadata(): %s", err)
+	}
+
+	return m, err
+}
+
+func (u *gtkUI) launchMeetingClient(data hosting.MeetingData) {
+	u.displayLoadingWindow()
+
 	var mumble tor.Service
 	var err error
 
@@ -112,27 +202,59 @@ func (u *gtkUI) joinMeetingHandler(data hosting.MeetingData) {
 
 	u.hideLoadingWindow()
 
+	if err == client.ErrCertificateMismatch {
+		u.openErrorDialog(certificateMismatchMessage())
+		u.showMainWindow()
+		return
+	}
+
+	if err == hosting.ErrInvalidInvitationSignature {
+		u.openErrorDialog(invitationErrorMessage(err))
+		u.showMainWindow()
+		return
+	}
+
 	if err != nil {
 		u.openErrorDialog(i18n().Sprintf("An error occurred\n\n%s", err.Error()))
 		u.showMainWindow()
 		return
 	}
 
-	u.openCurrentMeetingWindow(mumble)
+	u.openCurrentMeetingWindow(mumble, data)
 }
 
-func (u *gtkUI) handleOnJoinMeeting(b *uiBuilder) {
+func (u *gtkUI) handleOnJoinMeeting(b *uiBuilder, invitation *hosting.Invitation) {
 	entMeetingID, _ := b.get("entMeetingID").(gtki.Entry)
 	entScreenName, _ := b.get("entScreenName").(gtki.Entry)
 	entMeetingPassword, _ := b.get("entMeetingPassword").(gtki.Entry)
 
-	url, _ := entMeetingID.GetText()
+	id, _ := entMeetingID.GetText()
 	username, _ := entScreenName.GetText()
 	if username == "" {
 		username = getRandomName()
 	}
 	password, _ := entMeetingPassword.GetText()
 
+	url, fingerprint, err := hosting.SplitPinnedMeetingID(strings.TrimSpace(id))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"meetingID": id,
+		}).Error("Invalid certificate fingerprint provided")
+		u.reportError(i18n().Sprintf("The certificate fingerprint included in the meeting ID is not valid"))
+		return
+	}
+
+	if hosting.LooksLikeMeetingWords(url) {
+		url, err = hosting.MeetingIDFromWords(url)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"meetingID": id,
+			}).Error("Invalid meeting words provided")
+			u.reportError(i18n().Sprintf("The words don't represent a valid meeting ID. Please check that all of them have been written correctly."))
+			return
+		}
+	}
+
 	// TODO: remove this if we show a custom input field to enter
 	// the SERVICE URL and the PORT
 	meetingID, port, err := extractMeetingIDandPort(url)
@@ -147,10 +269,17 @@ func (u *gtkUI) handleOnJoinMeeting(b *uiBuilder) {
 	}
 
 	data := hosting.MeetingData{
-		MeetingID: meetingID,
-		Port:      port,
-		Username:  username,
-		Password:  password,
+		MeetingID:   meetingID,
+		Port:        port,
+		Username:    username,
+		Password:    password,
+		Fingerprint: fingerprint,
+	}
+
+	// The invitation is only taken into account if the participant
+	// hasn't changed the meeting ID it contains
+	if invitation != nil && invitation.Onion == meetingID {
+		data.Invitation = invitation
 	}
 
 	go u.joinMeetingHandler(data)
@@ -159,27 +288,59 @@ func (u *gtkUI) handleOnJoinMeeting(b *uiBuilder) {
 // Test Onion that can be used:
 // qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid.onion
 func (u *gtkUI) openJoinWindow() {
+	u.openJoinWindowWithInvitation(nil)
+}
+
+func (u *gtkUI) openJoinWindowWithInvitation(invitation *hosting.Invitation) {
 	win, builder := u.getInviteCodeEntities()
 
+	if invitation != nil {
+		fillJoinWindowWithInvitation(builder, invitation)
+	}
+
 	cleanup := func() {
 		win.Destroy()
 		u.switchToMainWindow()
 	}
 
+	onJoin := func() {
+		u.handleOnJoinMeeting(builder, invitation)
+	}
+
 	builder.ConnectSignals(map[string]interface{}{
-		"on_join": func() {
-			u.handleOnJoinMeeting(builder)
+		"on_join": onJoin,
+		"on_read_qr_code": func() {
+			u.readQRCodeImage(func(i *hosting.Invitation) {
+				invitation = i
+				fillJoinWindowWithInvitation(builder, i)
+			}, func(meetingID string) {
+				entMeetingID, _ := builder.get("entMeetingID").(gtki.Entry)
+				entMeetingID.SetText(meetingID)
+			})
 		},
 		"on_cancel": cleanup,
 		"on_close":  cleanup,
 	})
 
-	u.connectShortcutsInviteMeetingWindow(win, builder)
+	u.connectShortcutsInviteMeetingWindow(win, onJoin)
 
 	win.Show()
 	u.setCurrentWindow(win)
 }
 
+func fillJoinWindowWithInvitation(builder *uiBuilder, invitation *hosting.Invitation) {
+	entMeetingID, _ := builder.get("entMeetingID").(gtki.Entry)
+	entMeetingPassword, _ := builder.get("entMeetingPassword").(gtki.Entry)
+
+	entMeetingID.SetText(hosting.PinnedMeetingID(invitation.MeetingID(), invitation.Fingerprint))
+	entMeetingPassword.SetText(invitation.Password)
+}
+
+func certificateMismatchMessage() string {
+	return i18n().Sprintf("The meeting server is not using the certificate included in the invitation. " +
+		"Someone could be impersonating the meeting host, so Wahay won't join this meeting.")
+}
+
 var errInvalidMeetingAddr = errors.New("invalid meeting address")
 
 func extractMeetingIDandPort(meetingURL string) (meetingID string, port int, err error) {
@@ -17,6 +17,7 @@ func (u *gtkUI) hideLoadingWindow() {
 
 	u.doInUIThread(u.loadingWindow.Hide)
 	u.loadingWindow = nil
+	u.loadingBuilder = nil
 }
 
 func (u *gtkUI) displayLoadingWindowHelper(cb func()) {
@@ -39,5 +40,6 @@ func (u *gtkUI) displayLoadingWindowHelper(cb func()) {
 
 	win.SetApplication(u.app)
 	u.loadingWindow = win
+	u.loadingBuilder = builder
 	u.doInUIThread(win.Show)
 }
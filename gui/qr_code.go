@@ -0,0 +1,127 @@
+package gui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/hosting"
+	"github.com/digitalautonomy/wahay/qr"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const qrCodeExtension = ".png"
+
+// invitationQRCodeContent returns what the QR code of the meeting contains. The signed
+// invitation link is preferred, since it carries everything needed to join
+func (h *hostData) invitationQRCodeContent(i *hosting.Invitation) string {
+	if i != nil {
+		return i.URI()
+	}
+	return h.service.PinnedURL()
+}
+
+func (h *hostData) showInvitationQRCode(builder *uiBuilder) {
+	content, err := qr.Encode(h.invitationQRCodeContent(h.getInvitation()), qr.DefaultSize)
+	if err != nil {
+		log.Warnf("showInvitationQRCode(): %s", err)
+		return
+	}
+
+	pixbuf, err := h.u.g.getPixbufFromBytes(content, qr.DefaultSize)
+	if err != nil {
+		log.Warnf("showInvitationQRCode(): %s", err)
+		return
+	}
+
+	img := builder.get("imgQRCode").(gtki.Image)
+	img.SetFromPixbuf(pixbuf)
+
+	box := builder.get("boxQRCode").(gtki.Box)
+	box.SetVisible(true)
+}
+
+func (h *hostData) exportInvitationQRCode(builder *uiBuilder) {
+	// The invitation is signed with a new expiration every time it's created,
+	// so the same one is used both for the QR code and for the file name
+	i := h.getInvitation()
+
+	content, err := qr.Encode(h.invitationQRCodeContent(i), qr.DefaultSize)
+	if err != nil {
+		log.Errorf("exportInvitationQRCode(): %s", err)
+		h.u.reportError(i18n().Sprintf("The QR code can't be created"))
+		return
+	}
+
+	go func() {
+		name := strings.TrimSuffix(invitationFileName(i), hosting.InvitationFileExtension)
+		ok, fileName := h.u.getSaveFilePath(name + qrCodeExtension)
+		if !ok {
+			return
+		}
+
+		if !strings.HasSuffix(strings.ToLower(fileName), qrCodeExtension) {
+			fileName = fileName + qrCodeExtension
+		}
+
+		err := os.WriteFile(fileName, content, 0600)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"fileName": fileName,
+			}).Errorf("exportInvitationQRCode(): %s", err)
+			h.u.doInUIThread(func() {
+				h.u.reportError(i18n().Sprintf("The QR code can't be saved: %s", err))
+			})
+			return
+		}
+
+		lblMessage := builder.get("lblMessage").(gtki.Label)
+		h.u.messageToLabel(lblMessage, i18n().Sprintf("The QR code has been saved"), 5)
+	}()
+}
+
+// readQRCodeImage lets the participant choose an image containing the QR code of
+// a meeting. Invitation links are given to onInvitation, while anything else is
+// considered a meeting ID and is given to onMeetingID
+func (u *gtkUI) readQRCodeImage(onInvitation func(*hosting.Invitation), onMeetingID func(string)) {
+	go func() {
+		ok, fileName := u.getCustomFilePath()
+		if !ok {
+			return
+		}
+
+		content, err := qr.DecodeFile(fileName)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"fileName": fileName,
+			}).Errorf("readQRCodeImage(): %s", err)
+			u.doInUIThread(func() {
+				u.reportError(i18n().Sprintf("No QR code could be found in the selected image"))
+			})
+			return
+		}
+
+		content = strings.TrimSpace(content)
+
+		if !strings.HasPrefix(strings.ToLower(content), hosting.InvitationScheme+":") {
+			u.doInUIThread(func() {
+				onMeetingID(content)
+			})
+			return
+		}
+
+		i, err := hosting.ReadInvitation(content)
+		if err != nil {
+			log.Errorf("readQRCodeImage(): %s", err)
+			u.doInUIThread(func() {
+				u.reportError(invitationErrorMessage(err))
+			})
+			return
+		}
+
+		u.doInUIThread(func() {
+			onInvitation(i)
+		})
+	}()
+}
@@ -1,14 +1,21 @@
 package gui
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/coyim/gotk3adapter/gtki"
+	"github.com/digitalautonomy/wahay/client"
 	"github.com/digitalautonomy/wahay/config"
 	"github.com/digitalautonomy/wahay/gui/placeholders"
+	"github.com/digitalautonomy/wahay/hosting"
+	"github.com/digitalautonomy/wahay/tor"
 )
 
 type settings struct {
@@ -16,27 +23,72 @@ type settings struct {
 	b      *uiBuilder
 	dialog gtki.Window
 
-	chkAutojoin                gtki.CheckButton
-	chkPersistentConfiguration gtki.CheckButton
-	chkEncryptFile             gtki.CheckButton
-	lblMessage                 gtki.Label
-	chkEnableLogging           gtki.CheckButton
-	rawLogFile                 gtki.Entry
-	btnRawLogFile              gtki.Button
-	mumbleBinaryLocation       gtki.Entry
-	mumblePort                 gtki.Entry
-	lblPortMumbleMessage       gtki.Label
-	torBinaryLocation          gtki.Entry
-	cmbBoxColorScheme          gtki.ComboBoxText
-
-	autoJoinOriginalValue          bool
-	persistConfigFileOriginalValue bool
-	encryptFileOriginalValue       bool
-	logOriginalValue               bool
-	rawLogFileOriginalValue        string
-	mumbleBinaryOriginalValue      string
-	mumblePortOriginalValue        string
-	torBinaryOriginalValue         string
+	chkAutojoin                 gtki.CheckButton
+	chkMeetingIsolation         gtki.CheckButton
+	boxMeetingIsolationLimit    gtki.Box
+	meetingIsolationLimit       gtki.Entry
+	lblMeetingIsolationMessage  gtki.Label
+	chkSingleHopHosting         gtki.CheckButton
+	chkHostingProofOfWork       gtki.CheckButton
+	hostingMaxStreams           gtki.Entry
+	hostingRequestsPerSecond    gtki.Entry
+	hostingRequestsBurst        gtki.Entry
+	lblHostingProtectionMessage gtki.Label
+	chkPersistentConfiguration  gtki.CheckButton
+	chkEncryptFile              gtki.CheckButton
+	chkPersistentIdentity       gtki.CheckButton
+	lblIdentityFingerprint      gtki.Label
+	btnExportIdentity           gtki.Button
+	lblIdentityMessage          gtki.Label
+	lblMessage                  gtki.Label
+	chkEnableLogging            gtki.CheckButton
+	rawLogFile                  gtki.Entry
+	btnRawLogFile               gtki.Button
+	mumbleBinaryLocation        gtki.Entry
+	mumblePort                  gtki.Entry
+	lblPortMumbleMessage        gtki.Label
+	torBinaryLocation           gtki.Entry
+	cmbBoxColorScheme           gtki.ComboBoxText
+	chkUseBridges               gtki.CheckButton
+	txtBridges                  gtki.TextView
+	lblBridgesMessage           gtki.Label
+	btnImportBridges            gtki.Button
+	txtTorrcLines               gtki.TextView
+	lblTorrcLinesMessage        gtki.Label
+	chkUseTorEndpoint           gtki.CheckButton
+	gridTorEndpoint             gtki.Grid
+	torEndpointHost             gtki.Entry
+	torEndpointControl          gtki.Entry
+	torEndpointSocksPort        gtki.Entry
+	cmbTorEndpointAuth          gtki.ComboBoxText
+	torEndpointPassword         gtki.Entry
+	torEndpointCookieFile       gtki.Entry
+	lblTorEndpointMessage       gtki.Label
+	chkUseTorProxy              gtki.CheckButton
+	gridTorProxy                gtki.Grid
+	cmbTorProxyType             gtki.ComboBoxText
+	torProxyAddress             gtki.Entry
+	torProxyUsername            gtki.Entry
+	torProxyPassword            gtki.Entry
+	chkTorProxyFromEnvironment  gtki.CheckButton
+	lblTorProxyMessage          gtki.Label
+	chkUseTorCache              gtki.CheckButton
+	lblTorCacheMessage          gtki.Label
+
+	autoJoinOriginalValue           bool
+	persistConfigFileOriginalValue  bool
+	encryptFileOriginalValue        bool
+	logOriginalValue                bool
+	rawLogFileOriginalValue         string
+	mumbleBinaryOriginalValue       string
+	mumblePortOriginalValue         string
+	torBinaryOriginalValue          string
+	bridgesOriginalValue            bool
+	torEndpointOriginalValue        bool
+	torProxyOriginalValue           bool
+	singleHopOriginalValue          bool
+	torCacheOriginalValue           bool
+	persistentIdentityOriginalValue bool
 }
 
 func createSettings(u *gtkUI) *settings {
@@ -51,8 +103,22 @@ func createSettings(u *gtkUI) *settings {
 
 	s.b.getItems(
 		"chkAutojoin", &s.chkAutojoin,
+		"chkMeetingIsolation", &s.chkMeetingIsolation,
+		"boxMeetingIsolationLimit", &s.boxMeetingIsolationLimit,
+		"meetingIsolationLimit", &s.meetingIsolationLimit,
+		"lblMeetingIsolationMessage", &s.lblMeetingIsolationMessage,
+		"chkSingleHopHosting", &s.chkSingleHopHosting,
+		"chkHostingProofOfWork", &s.chkHostingProofOfWork,
+		"hostingMaxStreams", &s.hostingMaxStreams,
+		"hostingRequestsPerSecond", &s.hostingRequestsPerSecond,
+		"hostingRequestsBurst", &s.hostingRequestsBurst,
+		"lblHostingProtectionMessage", &s.lblHostingProtectionMessage,
 		"chkPersistentConfiguration", &s.chkPersistentConfiguration,
 		"chkEncryptFile", &s.chkEncryptFile,
+		"chkPersistentIdentity", &s.chkPersistentIdentity,
+		"lblIdentityFingerprint", &s.lblIdentityFingerprint,
+		"btnExportIdentity", &s.btnExportIdentity,
+		"lblIdentityMessage", &s.lblIdentityMessage,
 		"lblMessage", &s.lblMessage,
 		"chkEnableLogging", &s.chkEnableLogging,
 		"rawLogFile", &s.rawLogFile,
@@ -62,6 +128,31 @@ func createSettings(u *gtkUI) *settings {
 		"lblPortMumbleMessage", &s.lblPortMumbleMessage,
 		"torBinaryLocation", &s.torBinaryLocation,
 		"cmbBoxColorScheme", &s.cmbBoxColorScheme,
+		"chkUseBridges", &s.chkUseBridges,
+		"txtBridges", &s.txtBridges,
+		"lblBridgesMessage", &s.lblBridgesMessage,
+		"btnImportBridges", &s.btnImportBridges,
+		"txtTorrcLines", &s.txtTorrcLines,
+		"lblTorrcLinesMessage", &s.lblTorrcLinesMessage,
+		"chkUseTorEndpoint", &s.chkUseTorEndpoint,
+		"gridTorEndpoint", &s.gridTorEndpoint,
+		"torEndpointHost", &s.torEndpointHost,
+		"torEndpointControl", &s.torEndpointControl,
+		"torEndpointSocksPort", &s.torEndpointSocksPort,
+		"cmbTorEndpointAuth", &s.cmbTorEndpointAuth,
+		"torEndpointPassword", &s.torEndpointPassword,
+		"torEndpointCookieFile", &s.torEndpointCookieFile,
+		"lblTorEndpointMessage", &s.lblTorEndpointMessage,
+		"chkUseTorProxy", &s.chkUseTorProxy,
+		"gridTorProxy", &s.gridTorProxy,
+		"cmbTorProxyType", &s.cmbTorProxyType,
+		"torProxyAddress", &s.torProxyAddress,
+		"torProxyUsername", &s.torProxyUsername,
+		"torProxyPassword", &s.torProxyPassword,
+		"chkTorProxyFromEnvironment", &s.chkTorProxyFromEnvironment,
+		"lblTorProxyMessage", &s.lblTorProxyMessage,
+		"chkUseTorCache", &s.chkUseTorCache,
+		"lblTorCacheMessage", &s.lblTorCacheMessage,
 	)
 
 	s.init()
@@ -75,6 +166,15 @@ func (s *settings) init() {
 	s.autoJoinOriginalValue = conf.GetAutoJoin()
 	s.chkAutojoin.SetActive(s.autoJoinOriginalValue)
 
+	s.chkMeetingIsolation.SetActive(conf.IsMeetingIsolationEnabled())
+	s.meetingIsolationLimit.SetText(strconv.Itoa(conf.GetMeetingIsolationLimit()))
+	s.boxMeetingIsolationLimit.SetSensitive(conf.IsMeetingIsolationEnabled())
+
+	s.singleHopOriginalValue = conf.IsSingleHopHostingEnabled()
+	s.chkSingleHopHosting.SetActive(s.singleHopOriginalValue)
+
+	s.initHostingProtection(conf.GetHostingProtection())
+
 	s.persistConfigFileOriginalValue = conf.IsPersistentConfiguration()
 	s.chkPersistentConfiguration.SetActive(s.persistConfigFileOriginalValue)
 	s.lblMessage.SetVisible(!s.persistConfigFileOriginalValue)
@@ -83,6 +183,10 @@ func (s *settings) init() {
 	s.chkEncryptFile.SetActive(s.encryptFileOriginalValue)
 	s.chkEncryptFile.SetSensitive(s.persistConfigFileOriginalValue)
 
+	s.persistentIdentityOriginalValue = conf.IsPersistentIdentityEnabled()
+	s.chkPersistentIdentity.SetActive(s.persistentIdentityOriginalValue)
+	s.refreshIdentity()
+
 	s.logOriginalValue = conf.IsLogsEnabled()
 	s.chkEnableLogging.SetActive(s.logOriginalValue)
 	s.rawLogFileOriginalValue = conf.GetRawLogFile()
@@ -99,6 +203,22 @@ func (s *settings) init() {
 	s.torBinaryLocation.SetText(s.torBinaryOriginalValue)
 	s.torBinaryLocation.SetPlaceholderText(placeholders.GetPlaceholderConfigTor())
 
+	s.bridgesOriginalValue = conf.AreBridgesEnabled()
+	s.chkUseBridges.SetActive(s.bridgesOriginalValue)
+	s.setBridgesText(strings.Join(conf.GetBridgeLines(), "\n"))
+	s.txtBridges.SetSensitive(s.bridgesOriginalValue)
+	s.btnImportBridges.SetSensitive(s.bridgesOriginalValue)
+
+	setTextViewText(s.txtTorrcLines, strings.Join(conf.GetTorrcLines(), "\n"))
+
+	s.initTorProxy(conf.GetTorProxy())
+	s.chkTorProxyFromEnvironment.SetActive(conf.IsTorProxyFromEnvironment())
+
+	s.initTorEndpoint(conf.GetTorEndpoint())
+
+	s.torCacheOriginalValue = conf.UseTorCache()
+	s.chkUseTorCache.SetActive(s.torCacheOriginalValue)
+
 	// Set color scheme combo box based on config
 	colorScheme := conf.GetColorScheme()
 	switch colorScheme {
@@ -116,13 +236,40 @@ func (u *gtkUI) getSettingsBuilder() *uiBuilder {
 
 	builder.i18nProperties(
 		"checkbox", "chkAutojoin",
+		"checkbox", "chkMeetingIsolation",
+		"checkbox", "chkSingleHopHosting",
+		"checkbox", "chkHostingProofOfWork",
 		"checkbox", "chkPersistentConfiguration",
 		"checkbox", "chkEncryptFile",
+		"checkbox", "chkPersistentIdentity",
 		"checkbox", "chkEnableLogging",
+		"checkbox", "chkUseBridges",
+		"checkbox", "chkUseTorProxy",
+		"checkbox", "chkTorProxyFromEnvironment",
+		"checkbox", "chkUseTorEndpoint",
+		"checkbox", "chkUseTorCache",
 		"tooltip", "chkAutojoin",
+		"tooltip", "chkMeetingIsolation",
+		"tooltip", "chkSingleHopHosting",
+		"tooltip", "chkHostingProofOfWork",
 		"tooltip", "chkPersistentConfiguration",
+		"tooltip", "chkPersistentIdentity",
 		"tooltip", "chkEnableLogging",
+		"tooltip", "chkUseBridges",
+		"tooltip", "chkUseTorProxy",
+		"tooltip", "chkTorProxyFromEnvironment",
+		"tooltip", "chkUseTorEndpoint",
+		"tooltip", "chkUseTorCache",
 		"label", "lblAutojoin",
+		"label", "lblMeetingIsolation",
+		"label", "lblMeetingIsolationLimit",
+		"label", "lblMeetingIsolationMessage",
+		"label", "lblSingleHopHosting",
+		"label", "lblHostingProtection",
+		"label", "lblHostingMaxStreams",
+		"label", "lblHostingRequestsPerSecond",
+		"label", "lblHostingRequestsBurst",
+		"label", "lblHostingProtectionMessage",
 		"label", "lblHostingGroup",
 		"label", "tabGeneral",
 		"label", "tabSecurity",
@@ -130,6 +277,7 @@ func (u *gtkUI) getSettingsBuilder() *uiBuilder {
 		"label", "tabMumble",
 		"label", "tabTor",
 		"label", "lblStoreConfigDescription",
+		"label", "lblPersistentIdentityDescription",
 		"label", "lblDebugWarning",
 		"label", "lblDebugLogFile",
 		"label", "lblDebugLogFileDescription",
@@ -143,6 +291,27 @@ func (u *gtkUI) getSettingsBuilder() *uiBuilder {
 		"label", "lblTorLocation",
 		"label", "lblTorBinaryDescription",
 		"label", "lblTorBinaryBrowse",
+		"label", "lblBridgesDescription",
+		"label", "lblBridgesMessage",
+		"label", "lblTorrcLines",
+		"label", "lblTorrcLinesDescription",
+		"label", "lblTorrcLinesMessage",
+		"label", "lblTorProxyDescription",
+		"label", "lblTorProxyType",
+		"label", "lblTorProxyAddress",
+		"label", "lblTorProxyUsername",
+		"label", "lblTorProxyPassword",
+		"label", "lblTorProxyMessage",
+		"label", "lblTorEndpointDescription",
+		"label", "lblTorEndpointHost",
+		"label", "lblTorEndpointControl",
+		"label", "lblTorEndpointSocksPort",
+		"label", "lblTorEndpointAuth",
+		"label", "lblTorEndpointPassword",
+		"label", "lblTorEndpointCookieFile",
+		"label", "lblTorEndpointMessage",
+		"label", "lblTorCacheDescription",
+		"label", "lblTorCacheMessage",
 		"label", "lblMessage",
 		"label", "lblSettingsWarning",
 		"label", "lblConfigFileCorrupted",
@@ -150,6 +319,10 @@ func (u *gtkUI) getSettingsBuilder() *uiBuilder {
 		"label", "lblMumbleBinaryDescription",
 		"button", "btnCancelSettings",
 		"button", "btnSaveSettings",
+		"button", "btnImportBridges",
+		"button", "btnClearTorCache",
+		"button", "btnImportIdentity",
+		"button", "btnExportIdentity",
 		"button", "btnConfigFileCorruptedCancel",
 		"button", "btnConfigFileCorruptedBackup",
 		"placeholder", "mumbleBinaryLocation",
@@ -168,6 +341,138 @@ func (s *settings) processAutojoinOption() {
 	}
 }
 
+func (s *settings) processMeetingIsolationOption() {
+	enabled := s.chkMeetingIsolation.GetActive()
+	s.u.config.EnableMeetingIsolation(enabled)
+	s.boxMeetingIsolationLimit.SetSensitive(enabled)
+}
+
+// processSingleHopOption asks the user to confirm that the location of the
+// computer doesn't need to be hidden before enabling the single-hop mode
+func (s *settings) processSingleHopOption() {
+	conf := s.u.config
+
+	if s.chkSingleHopHosting.GetActive() == s.singleHopOriginalValue {
+		return
+	}
+
+	if s.singleHopOriginalValue {
+		s.singleHopOriginalValue = false
+		conf.EnableSingleHopHosting(false)
+		return
+	}
+
+	s.u.showConfirmation(func(op bool) {
+		if op {
+			s.singleHopOriginalValue = true
+			conf.EnableSingleHopHosting(true)
+		} else {
+			s.chkSingleHopHosting.SetActive(false)
+		}
+	}, i18n().Sprintf("The meetings you host won't hide the location of this computer. "+
+		"Anyone who knows a meeting ID can find out the IP address of this computer. "+
+		"The participants will still be anonymous. Do you want to continue?"))
+}
+
+// processMeetingIsolationLimit validates the maximum number of Tor instances
+// for the hosted meetings and saves it. It returns false if it's not valid
+func (s *settings) processMeetingIsolationLimit() bool {
+	v, _ := s.meetingIsolationLimit.GetText()
+
+	limit, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || limit < 1 {
+		s.lblMeetingIsolationMessage.SetVisible(true)
+		return false
+	}
+
+	s.lblMeetingIsolationMessage.SetVisible(false)
+	s.u.config.SetMeetingIsolationLimit(limit)
+
+	return true
+}
+
+var (
+	errInvalidMaxStreams    = errors.New("invalid maximum number of streams")
+	errInvalidRequestsLimit = errors.New("invalid limit of requests")
+)
+
+func (s *settings) initHostingProtection(p *config.HostingProtection) {
+	if p == nil {
+		p = hostingProtectionSettings(hosting.DefaultProtection())
+	}
+
+	s.chkHostingProofOfWork.SetActive(p.ProofOfWork)
+	s.hostingMaxStreams.SetText(strconv.Itoa(p.MaxStreams))
+	s.hostingRequestsPerSecond.SetText(strconv.Itoa(p.RequestsPerSecond))
+	s.hostingRequestsBurst.SetText(strconv.Itoa(p.RequestsBurst))
+}
+
+// processHostingProtection validates the limits that protect the hosted
+// meetings and saves them. It returns false if they're not valid
+func (s *settings) processHostingProtection() bool {
+	maxStreams, _ := s.hostingMaxStreams.GetText()
+	perSecond, _ := s.hostingRequestsPerSecond.GetText()
+	burst, _ := s.hostingRequestsBurst.GetText()
+
+	p, err := parseHostingProtection(maxStreams, perSecond, burst, s.chkHostingProofOfWork.GetActive())
+	if err != nil {
+		s.lblHostingProtectionMessage.SetText(hostingProtectionErrorMessage(err))
+		s.lblHostingProtectionMessage.SetVisible(true)
+		return false
+	}
+
+	// The default protection isn't saved, so it
+	// can be improved in new versions of Wahay
+	if *p == *hostingProtectionSettings(hosting.DefaultProtection()) {
+		p = nil
+	}
+
+	s.lblHostingProtectionMessage.SetVisible(false)
+	s.u.config.SetHostingProtection(p)
+
+	return true
+}
+
+// parseHostingProtection returns the limits entered in the settings to
+// protect the hosted meetings. Empty values disable the limits
+func parseHostingProtection(maxStreams, perSecond, burst string, proofOfWork bool) (*config.HostingProtection, error) {
+	p := &config.HostingProtection{ProofOfWork: proofOfWork}
+
+	var ok bool
+	if p.MaxStreams, ok = parseLimit(maxStreams); !ok {
+		return nil, errInvalidMaxStreams
+	}
+
+	if p.RequestsPerSecond, ok = parseLimit(perSecond); !ok {
+		return nil, errInvalidRequestsLimit
+	}
+
+	if p.RequestsBurst, ok = parseLimit(burst); !ok || (p.RequestsPerSecond > 0 && p.RequestsBurst < 1) {
+		return nil, errInvalidRequestsLimit
+	}
+
+	return p, nil
+}
+
+// parseLimit returns the number in the given value, which
+// is zero when it's empty, and if it's a valid limit
+func parseLimit(v string) (int, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 0
+}
+
+func hostingProtectionErrorMessage(err error) string {
+	if err == errInvalidMaxStreams {
+		return i18n().Sprintf("The maximum number of streams must be zero or a positive number")
+	}
+	return i18n().Sprintf("The requests per second must be zero or a positive number, and the burst must be at least one when they are limited")
+}
+
 func (s *settings) processPersistentConfigOption() {
 	conf := s.u.config
 
@@ -189,6 +494,7 @@ func (s *settings) processEncryptFileOption() {
 					s.encryptFileOriginalValue = false
 					conf.SetShouldEncrypt(false)
 					s.chkEncryptFile.SetActive(false)
+					s.refreshTorCacheOption()
 				} else {
 					// We keep the checkbutton checked. Nothing else change.
 					s.chkEncryptFile.SetActive(true)
@@ -198,6 +504,7 @@ func (s *settings) processEncryptFileOption() {
 			s.u.captureMasterPassword(func() {
 				s.encryptFileOriginalValue = true
 				conf.SetShouldEncrypt(true)
+				s.refreshTorCacheOption()
 				s.u.saveConfigOnly()
 			}, func() {
 				s.chkEncryptFile.SetActive(false)
@@ -218,6 +525,557 @@ func (s *settings) processLogsOption() {
 	}
 }
 
+func (s *settings) processBridgesOption() {
+	conf := s.u.config
+
+	if s.chkUseBridges.GetActive() != s.bridgesOriginalValue {
+		s.bridgesOriginalValue = !s.bridgesOriginalValue
+		s.txtBridges.SetSensitive(s.bridgesOriginalValue)
+		s.btnImportBridges.SetSensitive(s.bridgesOriginalValue)
+		conf.EnableBridges(s.bridgesOriginalValue)
+	}
+}
+
+// processBridges validates the bridges entered by the user and saves them
+// in the configuration. It returns false if some of them are not valid
+func (s *settings) processBridges() bool {
+	bridges, err := tor.ParseBridgeLines(s.getBridgesText())
+	if err != nil {
+		s.lblBridgesMessage.SetText(bridgesErrorMessage(err))
+		s.lblBridgesMessage.SetVisible(true)
+		return false
+	}
+
+	s.lblBridgesMessage.SetVisible(false)
+
+	lines := make([]string, 0, len(bridges))
+	for _, b := range bridges {
+		lines = append(lines, b.String())
+	}
+
+	s.u.config.SetBridgeLines(lines)
+
+	return true
+}
+
+// processTorrcLines validates the additional lines for the Tor configuration
+// file and saves them. It returns false if some of them are not valid
+func (s *settings) processTorrcLines() bool {
+	lines, err := tor.ParseTorrcLines(getTextViewText(s.txtTorrcLines))
+	if err != nil {
+		s.lblTorrcLinesMessage.SetText(torrcLinesErrorMessage(err))
+		s.lblTorrcLinesMessage.SetVisible(true)
+		return false
+	}
+
+	s.lblTorrcLinesMessage.SetVisible(false)
+	s.u.config.SetTorrcLines(lines)
+
+	return true
+}
+
+func torrcLinesErrorMessage(err error) string {
+	if err == tor.ErrTorrcOptionNotAllowed {
+		return i18n().Sprintf("Some of the lines change options that Wahay needs to control Tor")
+	}
+	return i18n().Sprintf("Some of the lines are not valid")
+}
+
+func bridgesErrorMessage(err error) string {
+	if err == tor.ErrUnsupportedTransport {
+		return i18n().Sprintf("Some of the bridges use a transport that is not supported")
+	}
+	return i18n().Sprintf("Some of the bridges are not valid")
+}
+
+func (s *settings) getBridgesText() string {
+	return getTextViewText(s.txtBridges)
+}
+
+func (s *settings) setBridgesText(text string) {
+	setTextViewText(s.txtBridges, text)
+}
+
+func getTextViewText(v gtki.TextView) string {
+	buffer, err := v.GetBuffer()
+	if err != nil {
+		return ""
+	}
+
+	start, end := buffer.GetBounds()
+	return buffer.GetText(start, end, false)
+}
+
+func setTextViewText(v gtki.TextView, text string) {
+	buffer, err := v.GetBuffer()
+	if err != nil {
+		return
+	}
+
+	buffer.SetText(text)
+}
+
+func (s *settings) importBridges() {
+	go func() {
+		ok, fileName := s.u.getCustomFilePath()
+		if !ok {
+			return
+		}
+
+		content, err := os.ReadFile(filepath.Clean(fileName))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"fileName": fileName,
+			}).Errorf("importBridges(): %s", err)
+			s.u.doInUIThread(func() {
+				s.u.reportError(i18n().Sprintf("The bridges file can't be read"))
+			})
+			return
+		}
+
+		s.u.doInUIThread(func() {
+			s.setBridgesText(strings.TrimSpace(string(content)))
+		})
+	}()
+}
+
+// torProxyTypes are the types of proxy for Tor,
+// in the same order they are shown in the settings
+var torProxyTypes = []string{
+	config.TorProxyHTTPS,
+	config.TorProxySocks4,
+	config.TorProxySocks5,
+}
+
+func (s *settings) initTorProxy(p *config.TorProxy) {
+	s.torProxyOriginalValue = p != nil
+	s.chkUseTorProxy.SetActive(s.torProxyOriginalValue)
+	s.gridTorProxy.SetSensitive(s.torProxyOriginalValue)
+
+	if p == nil {
+		return
+	}
+
+	s.torProxyAddress.SetText(p.Address)
+	s.torProxyUsername.SetText(p.Username)
+	s.torProxyPassword.SetText(p.Password)
+
+	for ix, t := range torProxyTypes {
+		if t == p.Type {
+			s.cmbTorProxyType.SetActive(ix)
+		}
+	}
+}
+
+func (s *settings) processTorProxyOption() {
+	if s.chkUseTorProxy.GetActive() != s.torProxyOriginalValue {
+		s.torProxyOriginalValue = !s.torProxyOriginalValue
+		s.gridTorProxy.SetSensitive(s.torProxyOriginalValue)
+	}
+
+	s.u.config.EnableTorProxyFromEnvironment(s.chkTorProxyFromEnvironment.GetActive())
+}
+
+// processTorProxy validates the proxy for Tor entered by the user and saves
+// it in the configuration. It returns false if it's not valid
+func (s *settings) processTorProxy() bool {
+	if !s.torProxyOriginalValue {
+		s.lblTorProxyMessage.SetVisible(false)
+		s.u.config.SetTorProxy(nil)
+		return true
+	}
+
+	address, _ := s.torProxyAddress.GetText()
+	username, _ := s.torProxyUsername.GetText()
+	password, _ := s.torProxyPassword.GetText()
+
+	p := &config.TorProxy{
+		Type:     config.TorProxyHTTPS,
+		Address:  strings.TrimSpace(address),
+		Username: strings.TrimSpace(username),
+		Password: password,
+	}
+
+	if ix := s.cmbTorProxyType.GetActive(); ix >= 0 && ix < len(torProxyTypes) {
+		p.Type = torProxyTypes[ix]
+	}
+
+	err := tor.ValidateProxy(p)
+	if err != nil {
+		s.lblTorProxyMessage.SetText(torProxyErrorMessage(p))
+		s.lblTorProxyMessage.SetVisible(true)
+		return false
+	}
+
+	s.lblTorProxyMessage.SetVisible(false)
+	s.u.config.SetTorProxy(p)
+
+	return true
+}
+
+func torProxyErrorMessage(p *config.TorProxy) string {
+	switch {
+	case p.Address == "":
+		return i18n().Sprintf("The address of the proxy is needed")
+	case p.Type == config.TorProxySocks4 && (p.Username != "" || p.Password != ""):
+		return i18n().Sprintf("SOCKS 4 proxies don't support a username and a password")
+	}
+	return i18n().Sprintf("The proxy settings are not valid. The address must include the port, " +
+		"for example 192.0.2.1:3128")
+}
+
+// torEndpointAuthMethods are the authentication methods for the Tor
+// control port, in the same order they are shown in the settings
+var torEndpointAuthMethods = []string{
+	config.TorAuthAutomatic,
+	config.TorAuthNone,
+	config.TorAuthCookie,
+	config.TorAuthPassword,
+}
+
+var (
+	errInvalidTorControl   = errors.New("invalid Tor control port")
+	errInvalidTorSocksPort = errors.New("invalid Tor SOCKS port")
+	errMissingTorPassword  = errors.New("missing Tor control port password")
+)
+
+func (s *settings) initTorEndpoint(e *config.TorEndpoint) {
+	s.torEndpointOriginalValue = e != nil
+	s.chkUseTorEndpoint.SetActive(s.torEndpointOriginalValue)
+	s.gridTorEndpoint.SetSensitive(s.torEndpointOriginalValue)
+
+	if e == nil {
+		return
+	}
+
+	control := e.ControlSocket
+	if control == "" && e.ControlPort != 0 {
+		control = strconv.Itoa(e.ControlPort)
+	}
+
+	socksPort := ""
+	if e.SocksPort != 0 {
+		socksPort = strconv.Itoa(e.SocksPort)
+	}
+
+	s.torEndpointHost.SetText(e.Host)
+	s.torEndpointControl.SetText(control)
+	s.torEndpointSocksPort.SetText(socksPort)
+	s.torEndpointPassword.SetText(e.Password)
+	s.torEndpointCookieFile.SetText(e.CookieFile)
+
+	for ix, m := range torEndpointAuthMethods {
+		if m == e.AuthMethod {
+			s.cmbTorEndpointAuth.SetActive(ix)
+		}
+	}
+}
+
+func (s *settings) processTorEndpointOption() {
+	if s.chkUseTorEndpoint.GetActive() != s.torEndpointOriginalValue {
+		s.torEndpointOriginalValue = !s.torEndpointOriginalValue
+		s.gridTorEndpoint.SetSensitive(s.torEndpointOriginalValue)
+	}
+}
+
+// processTorEndpoint validates the Tor instance entered by the user and saves
+// it in the configuration. It returns false if it's not valid
+func (s *settings) processTorEndpoint() bool {
+	if !s.torEndpointOriginalValue {
+		s.lblTorEndpointMessage.SetVisible(false)
+		s.u.config.SetTorEndpoint(nil)
+		return true
+	}
+
+	host, _ := s.torEndpointHost.GetText()
+	control, _ := s.torEndpointControl.GetText()
+	socksPort, _ := s.torEndpointSocksPort.GetText()
+	password, _ := s.torEndpointPassword.GetText()
+	cookieFile, _ := s.torEndpointCookieFile.GetText()
+
+	authMethod := config.TorAuthAutomatic
+	if ix := s.cmbTorEndpointAuth.GetActive(); ix >= 0 && ix < len(torEndpointAuthMethods) {
+		authMethod = torEndpointAuthMethods[ix]
+	}
+
+	e, err := parseTorEndpoint(host, control, socksPort, authMethod, password, cookieFile)
+	if err != nil {
+		s.lblTorEndpointMessage.SetText(torEndpointErrorMessage(err))
+		s.lblTorEndpointMessage.SetVisible(true)
+		return false
+	}
+
+	s.lblTorEndpointMessage.SetVisible(false)
+	s.u.config.SetTorEndpoint(e)
+
+	return true
+}
+
+// parseTorEndpoint returns the Tor instance described by the values entered
+// in the settings. The control port can also be the path to a Unix domain socket
+func parseTorEndpoint(host, control, socksPort, authMethod, password, cookieFile string) (*config.TorEndpoint, error) {
+	e := &config.TorEndpoint{
+		Host:       strings.TrimSpace(host),
+		AuthMethod: authMethod,
+		Password:   password,
+		CookieFile: strings.TrimSpace(cookieFile),
+	}
+
+	if e.Host == "" {
+		e.Host = config.DefaultHost
+	}
+
+	control = strings.TrimSpace(control)
+	switch {
+	case control == "":
+		e.ControlPort = config.DefaultControlPort
+	case filepath.IsAbs(control):
+		e.ControlSocket = control
+	default:
+		p, err := strconv.Atoi(control)
+		if err != nil || !config.CheckPort(p) {
+			return nil, errInvalidTorControl
+		}
+		e.ControlPort = p
+	}
+
+	socksPort = strings.TrimSpace(socksPort)
+	e.SocksPort = config.DefaultRoutePort
+	if socksPort != "" {
+		p, err := strconv.Atoi(socksPort)
+		if err != nil || !config.CheckPort(p) {
+			return nil, errInvalidTorSocksPort
+		}
+		e.SocksPort = p
+	}
+
+	if authMethod == config.TorAuthPassword && password == "" {
+		return nil, errMissingTorPassword
+	}
+
+	return e, nil
+}
+
+func torEndpointErrorMessage(err error) string {
+	switch err {
+	case errInvalidTorControl:
+		return i18n().Sprintf("The control port must be a port number or the full path to a Unix domain socket")
+	case errInvalidTorSocksPort:
+		return i18n().Sprintf("The SOCKS port is not valid")
+	case errMissingTorPassword:
+		return i18n().Sprintf("A password is needed for the password authentication")
+	}
+	return i18n().Sprintf("The Tor instance settings are not valid")
+}
+
+func (s *settings) processTorCacheOption() {
+	conf := s.u.config
+
+	if s.chkUseTorCache.GetActive() == s.torCacheOriginalValue {
+		return
+	}
+
+	if s.torCacheOriginalValue || !conf.ShouldEncrypt() {
+		s.torCacheOriginalValue = !s.torCacheOriginalValue
+		conf.EnableTorCache(s.torCacheOriginalValue)
+		return
+	}
+
+	s.u.showConfirmation(func(op bool) {
+		if op {
+			s.torCacheOriginalValue = true
+			conf.EnableTorCache(true)
+			conf.ConsentToTorCache(true)
+		} else {
+			// We keep the checkbutton unchecked. Nothing else change.
+			s.chkUseTorCache.SetActive(false)
+		}
+	}, i18n().Sprintf("Your configuration settings are encrypted, but the Tor cache can't be. Anyone with access to this computer could see that Tor was used"))
+}
+
+// refreshTorCacheOption shows if the Tor cache will be used, since that depends
+// on the consent of the user when the configuration settings are encrypted
+func (s *settings) refreshTorCacheOption() {
+	s.torCacheOriginalValue = s.u.config.UseTorCache()
+	s.chkUseTorCache.SetActive(s.torCacheOriginalValue)
+}
+
+func (s *settings) clearTorCache() {
+	cleared, err := config.ClearTorCache()
+	switch {
+	case err != nil:
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("clearTorCache(): the Tor cache can't be cleared")
+		s.lblTorCacheMessage.SetText(i18n().Sprintf("The Tor cache couldn't be cleared"))
+	case cleared:
+		s.lblTorCacheMessage.SetText(i18n().Sprintf("The Tor cache was cleared"))
+	default:
+		s.lblTorCacheMessage.SetText(i18n().Sprintf("Tor is using the Tor cache now, so it will be cleared the next time Tor starts"))
+	}
+
+	s.lblTorCacheMessage.SetVisible(true)
+}
+
+// The name suggested when exporting the identity,
+// with the extension of the PKCS12 files Mumble imports and exports
+const (
+	identityFileExtension = ".p12"
+	identityFileName      = "wahay-identity" + identityFileExtension
+)
+
+// processPersistentIdentityOption asks the user to confirm that the hosts can
+// recognise them before joining every meeting with the same identity
+func (s *settings) processPersistentIdentityOption() {
+	conf := s.u.config
+
+	if s.chkPersistentIdentity.GetActive() == s.persistentIdentityOriginalValue {
+		return
+	}
+
+	if s.persistentIdentityOriginalValue {
+		s.persistentIdentityOriginalValue = false
+		conf.EnablePersistentIdentity(false)
+		return
+	}
+
+	s.u.showConfirmation(func(op bool) {
+		if !op {
+			s.chkPersistentIdentity.SetActive(false)
+			return
+		}
+
+		s.persistentIdentityOriginalValue = true
+		conf.EnablePersistentIdentity(true)
+
+		if len(conf.GetParticipantIdentity()) == 0 {
+			s.generateIdentity()
+		}
+	}, i18n().Sprintf("The hosts of the meetings you join will be able to know that you "+
+		"joined their meetings before, and to tell other hosts who you are. Do you want to continue?"))
+}
+
+func (s *settings) generateIdentity() {
+	go func() {
+		identity, err := client.GenerateIdentity()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Error("generateIdentity(): the identity can't be created")
+			s.u.doInUIThread(func() {
+				s.persistentIdentityOriginalValue = false
+				s.u.config.EnablePersistentIdentity(false)
+				s.chkPersistentIdentity.SetActive(false)
+			})
+			s.u.messageToLabel(s.lblIdentityMessage, i18n().Sprintf("The identity couldn't be created"), 5)
+			return
+		}
+
+		s.u.doInUIThread(func() {
+			s.u.config.SetParticipantIdentity(identity)
+			s.refreshIdentity()
+		})
+	}()
+}
+
+// refreshIdentity shows the fingerprint of the identity kept in the configuration,
+// which is the one the hosts see for the participant in Mumble
+func (s *settings) refreshIdentity() {
+	fingerprint := ""
+	if identity := s.u.config.GetParticipantIdentity(); len(identity) != 0 {
+		fingerprint, _ = client.IdentityFingerprint(identity)
+	}
+
+	s.lblIdentityFingerprint.SetVisible(len(fingerprint) != 0)
+	s.lblIdentityFingerprint.SetText(i18n().Sprintf("Fingerprint of your identity: %s", fingerprint))
+	s.btnExportIdentity.SetSensitive(len(fingerprint) != 0)
+}
+
+// importIdentity replaces the identity kept in the configuration with the one in
+// the chosen file, and starts using it, since that's why it is imported
+func (s *settings) importIdentity() {
+	doImport := func() {
+		go func() {
+			ok, fileName := s.u.getCustomFilePath()
+			if !ok {
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Clean(fileName))
+			if err != nil {
+				log.WithFields(log.Fields{
+					"fileName": fileName,
+				}).Errorf("importIdentity(): %s", err)
+				s.u.messageToLabel(s.lblIdentityMessage, i18n().Sprintf("The identity file can't be read"), 5)
+				return
+			}
+
+			identity, err := client.ImportIdentity(content)
+			if err != nil {
+				s.u.messageToLabel(s.lblIdentityMessage, identityErrorMessage(err), 5)
+				return
+			}
+
+			s.u.doInUIThread(func() {
+				s.u.config.SetParticipantIdentity(identity)
+				s.u.config.EnablePersistentIdentity(true)
+				s.persistentIdentityOriginalValue = true
+				s.chkPersistentIdentity.SetActive(true)
+				s.refreshIdentity()
+			})
+			s.u.messageToLabel(s.lblIdentityMessage, i18n().Sprintf("The identity was imported"), 5)
+		}()
+	}
+
+	if len(s.u.config.GetParticipantIdentity()) == 0 {
+		doImport()
+		return
+	}
+
+	s.u.showConfirmation(func(op bool) {
+		if op {
+			doImport()
+		}
+	}, i18n().Sprintf("The imported identity will replace the one you have now, and the hosts "+
+		"won't recognise you with it anymore. Do you want to continue?"))
+}
+
+func (s *settings) exportIdentity() {
+	identity := s.u.config.GetParticipantIdentity()
+	if len(identity) == 0 {
+		return
+	}
+
+	go func() {
+		ok, fileName := s.u.getSaveFilePath(identityFileName)
+		if !ok {
+			return
+		}
+
+		if !strings.HasSuffix(fileName, identityFileExtension) {
+			fileName = fileName + identityFileExtension
+		}
+
+		err := os.WriteFile(fileName, identity, 0600)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"fileName": fileName,
+			}).Errorf("exportIdentity(): %s", err)
+			s.u.messageToLabel(s.lblIdentityMessage, i18n().Sprintf("The identity file can't be saved"), 5)
+			return
+		}
+
+		s.u.messageToLabel(s.lblIdentityMessage, i18n().Sprintf("The identity was exported"), 5)
+	}()
+}
+
+func identityErrorMessage(err error) string {
+	if err == client.ErrIdentityWithPassword {
+		return i18n().Sprintf("The identity is protected with a password. Please export it again without a password.")
+	}
+	return i18n().Sprintf("The file doesn't contain a valid identity")
+}
+
 func (s *settings) processMumblePort() {
 	conf := s.u.config
 	v, _ := s.mumblePort.GetText()
@@ -226,9 +1084,16 @@ func (s *settings) processMumblePort() {
 
 func (u *gtkUI) onSettingsToggleOption(s *settings) {
 	s.processAutojoinOption()
+	s.processMeetingIsolationOption()
+	s.processSingleHopOption()
 	s.processPersistentConfigOption()
 	s.processEncryptFileOption()
+	s.processPersistentIdentityOption()
 	s.processLogsOption()
+	s.processBridgesOption()
+	s.processTorProxyOption()
+	s.processTorEndpointOption()
+	s.processTorCacheOption()
 }
 
 func (u *gtkUI) cleanupSettings(s *settings) {
@@ -240,6 +1105,10 @@ func (u *gtkUI) cleanupSettings(s *settings) {
 }
 
 func (u *gtkUI) handleOnSaveSettings(s *settings) {
+	if !s.processMeetingIsolationLimit() || !s.processHostingProtection() || !s.processBridges() || !s.processTorrcLines() || !s.processTorProxy() || !s.processTorEndpoint() {
+		return
+	}
+
 	s.processMumblePort()
 	u.saveConfigOnly()
 	u.cleanupSettings(s)
@@ -267,6 +1136,10 @@ func (u *gtkUI) openSettingsWindow() {
 		"on_torBinaryLocation_icon_press":       s.setCustomPathForTor,
 		"on_torBinaryLocation_clicked_event":    s.setCustomPathForTor,
 		"on_colorScheme_changed_event":          s.changeColorScheme,
+		"on_import_bridges":                     s.importBridges,
+		"on_clear_tor_cache":                    s.clearTorCache,
+		"on_import_identity":                    s.importIdentity,
+		"on_export_identity":                    s.exportIdentity,
 	})
 
 	u.connectShortcutsSettingsWindow(s.dialog)
@@ -0,0 +1,85 @@
+package gui
+
+import (
+	"github.com/digitalautonomy/wahay/config"
+	"github.com/digitalautonomy/wahay/hosting"
+	. "gopkg.in/check.v1"
+)
+
+type WahaySettingsSuite struct{}
+
+var _ = Suite(&WahaySettingsSuite{})
+
+func (s *WahaySettingsSuite) Test_Settings_parseTorEndpoint_usesTheDefaultsForEmptyValues(c *C) {
+	e, err := parseTorEndpoint("", "", "", config.TorAuthAutomatic, "", "")
+
+	c.Assert(err, IsNil)
+	c.Assert(e, DeepEquals, &config.TorEndpoint{
+		Host:        config.DefaultHost,
+		ControlPort: config.DefaultControlPort,
+		SocksPort:   config.DefaultRoutePort,
+	})
+}
+
+func (s *WahaySettingsSuite) Test_Settings_parseTorEndpoint_usesAnAbsolutePathAsTheControlSocket(c *C) {
+	e, err := parseTorEndpoint(" tor ", "/run/tor/control", "9150", config.TorAuthCookie, "", "/run/tor/control.authcookie")
+
+	c.Assert(err, IsNil)
+	c.Assert(e, DeepEquals, &config.TorEndpoint{
+		Host:          "tor",
+		ControlSocket: "/run/tor/control",
+		SocksPort:     9150,
+		AuthMethod:    config.TorAuthCookie,
+		CookieFile:    "/run/tor/control.authcookie",
+	})
+}
+
+func (s *WahaySettingsSuite) Test_Settings_parseTorEndpoint_FailsIfNoValidValues(c *C) {
+	_, e1 := parseTorEndpoint("tor", "control", "9050", config.TorAuthNone, "", "")
+	_, e2 := parseTorEndpoint("tor", "70000", "9050", config.TorAuthNone, "", "")
+	_, e3 := parseTorEndpoint("tor", "9051", "socks", config.TorAuthNone, "", "")
+	_, e4 := parseTorEndpoint("tor", "9051", "9050", config.TorAuthPassword, "", "")
+
+	c.Assert(e1, Equals, errInvalidTorControl)
+	c.Assert(e2, Equals, errInvalidTorControl)
+	c.Assert(e3, Equals, errInvalidTorSocksPort)
+	c.Assert(e4, Equals, errMissingTorPassword)
+}
+
+func (s *WahaySettingsSuite) Test_Settings_parseHostingProtection_disablesTheEmptyLimits(c *C) {
+	p, err := parseHostingProtection(" 30 ", "", "", true)
+
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, &config.HostingProtection{MaxStreams: 30, ProofOfWork: true})
+}
+
+func (s *WahaySettingsSuite) Test_Settings_parseHostingProtection_FailsIfNoValidValues(c *C) {
+	_, e1 := parseHostingProtection("many", "2", "10", true)
+	_, e2 := parseHostingProtection("-1", "2", "10", true)
+	_, e3 := parseHostingProtection("20", "fast", "10", true)
+	_, e4 := parseHostingProtection("20", "2", "0", true)
+
+	c.Assert(e1, Equals, errInvalidMaxStreams)
+	c.Assert(e2, Equals, errInvalidMaxStreams)
+	c.Assert(e3, Equals, errInvalidRequestsLimit)
+	c.Assert(e4, Equals, errInvalidRequestsLimit)
+}
+
+func (s *WahaySettingsSuite) Test_meetingProtection_usesTheLimitsOfTheSettings(c *C) {
+	c.Assert(meetingProtection(nil), DeepEquals, hosting.DefaultProtection())
+
+	p := meetingProtection(&config.HostingProtection{MaxStreams: 50, RequestsPerSecond: 5, RequestsBurst: 20})
+
+	c.Assert(p.Onion.MaxStreams, Equals, 50)
+	c.Assert(p.Onion.MaxStreamsCloseCircuit, Equals, true)
+	c.Assert(p.Onion.ProofOfWork, Equals, false)
+	c.Assert(p.RequestsPerSecond, Equals, float64(5))
+	c.Assert(p.RequestsBurst, Equals, 20)
+
+	c.Assert(hostingProtectionSettings(meetingProtection(nil)), DeepEquals, &config.HostingProtection{
+		MaxStreams:        20,
+		ProofOfWork:       true,
+		RequestsPerSecond: 2,
+		RequestsBurst:     10,
+	})
+}
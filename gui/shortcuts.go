@@ -100,13 +100,13 @@ func (u *gtkUI) connectShortcutsCurrentMeetingWindow(w gtki.Window, m tor.Servic
 	})
 }
 
-func (u *gtkUI) connectShortcutsInviteMeetingWindow(w gtki.Window, b *uiBuilder) {
+func (u *gtkUI) connectShortcutsInviteMeetingWindow(w gtki.Window, onJoin func()) {
 	// <Primary> maps to Command and OS X, but Control on other platforms
 	u.connectShortcut("<Primary>q", w, u.closeApplicationWindow)
 	u.connectShortcut("<Primary>F4", w, u.closeWindow)
 	u.connectShortcut("Escape", w, u.closeWindow)
 	u.connectShortcut("<Primary>j", w, func(_ gtki.Window) {
-		u.handleOnJoinMeeting(b)
+		onJoin()
 	})
 }
 
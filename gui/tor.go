@@ -3,7 +3,9 @@ package gui
 import (
 	"errors"
 	"sync"
+	"time"
 
+	"github.com/coyim/gotk3adapter/gtki"
 	"github.com/digitalautonomy/wahay/tor"
 )
 
@@ -15,7 +17,7 @@ func (u *gtkUI) ensureTor(wg *sync.WaitGroup) {
 		defer wg.Done()
 		defer u.torInitialized.Done()
 
-		instance, e := tor.NewInstance(u.config, u.onTorInstanceCreated)
+		instance, e := tor.NewInstance(u.config, u.onTorInstanceCreated, u.onTorBootstrapProgress)
 		if e != nil {
 			u.errorHandler.addNewStartupError(e, errGroupTor)
 			return
@@ -27,6 +29,8 @@ func (u *gtkUI) ensureTor(wg *sync.WaitGroup) {
 		}
 
 		u.tor = instance
+		u.torPool = tor.NewPool(u.config, instance)
+		u.onExit(u.torPool.Close)
 	}()
 }
 
@@ -37,6 +41,53 @@ func (u *gtkUI) onTorInstanceCreated(i tor.Instance) {
 	u.onExit(i.Destroy)
 }
 
+// onTorBootstrapProgress displays in the loading window how far
+// our Tor instance is in the process of connecting to the Tor network
+func (u *gtkUI) onTorBootstrapProgress(st tor.BootstrapStatus) {
+	u.doInUIThread(func() {
+		if u.loadingBuilder == nil {
+			return
+		}
+
+		lblLoading := u.loadingBuilder.get("lblLoading").(gtki.Label)
+		loadingProgress := u.loadingBuilder.get("loadingProgress").(gtki.ProgressBar)
+
+		loadingProgress.SetFraction(float64(st.Progress) / 100)
+		loadingProgress.SetText(i18n().Sprintf("%d%%", st.Progress))
+		loadingProgress.Show()
+
+		lblLoading.SetLabel(torBootstrapMessage(st))
+	})
+}
+
+func torBootstrapMessage(st tor.BootstrapStatus) string {
+	if len(st.Warning) != 0 {
+		return i18n().Sprintf("Tor is having trouble connecting to the Tor network: %s", st.Warning)
+	}
+
+	if st.Done() {
+		return i18n().Sprintf("Connected to the Tor network")
+	}
+
+	return i18n().Sprintf("Connecting to the Tor network...")
+}
+
+func clockSkewText(skew time.Duration) string {
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew < time.Minute {
+		return i18n().Sprintf("%d seconds", int(skew.Seconds()))
+	}
+
+	if skew < time.Hour {
+		return i18n().Sprintf("%d minutes", int(skew.Minutes()))
+	}
+
+	return i18n().Sprintf("%d hours", int(skew.Hours()))
+}
+
 func (u *gtkUI) waitForTorInstance(f func(tor.Instance)) {
 	go func() {
 		u.torInitialized.Wait()
@@ -51,6 +102,13 @@ func init() {
 }
 
 func torErrorTranslator(err error) string {
+	var skew *tor.ClockSkewError
+	if errors.As(err, &skew) {
+		return i18n().Sprintf("Tor can't connect to the Tor network because the clock of your "+
+			"computer is wrong by %s.\n\nPlease set the correct date, time and time zone "+
+			"in your system settings and start Wahay again.", clockSkewText(skew.Skew))
+	}
+
 	switch err {
 	case tor.ErrTorBinaryNotFound:
 		return i18n().Sprintf("In order to run Wahay, you must have Tor installed in your system.")
@@ -81,6 +139,57 @@ func torErrorTranslator(err error) string {
 		return i18n().Sprintf("The configured path to the Tor binary is not valid or can't be used.\n\n" +
 			"Please configure another path.")
 
+	case tor.ErrInvalidBridgeLine, tor.ErrUnsupportedTransport:
+		return i18n().Sprintf("Some of the configured bridges are not valid.\n\n" +
+			"Please review them in the Tor settings.")
+
+	case tor.ErrTransportPluginNotFound:
+		return i18n().Sprintf("The program needed to connect to some of the configured bridges " +
+			"can't be found.\n\nPlease install it next to the Tor binary.")
+
+	case tor.ErrInvalidBridgesConfiguration:
+		return i18n().Sprintf("Tor doesn't accept the configured bridges.\n\n" +
+			"Please review them in the Tor settings.")
+
+	case tor.ErrInvalidTorrcLine, tor.ErrTorrcOptionNotAllowed, tor.ErrInvalidTorrcConfiguration:
+		return i18n().Sprintf("Tor doesn't accept the additional configuration.\n\n" +
+			"Please review it in the Tor settings.")
+
+	case tor.ErrInvalidProxy:
+		return i18n().Sprintf("The proxy configured for Tor is not valid.\n\n" +
+			"Please review it in the Tor settings.")
+
+	case tor.ErrProxyUnreachable:
+		return i18n().Sprintf("Tor can't connect to the configured proxy.\n\n" +
+			"Please check its address in the Tor settings, and that the proxy is running.")
+
+	case tor.ErrProxyAuthenticationFailed:
+		return i18n().Sprintf("The configured proxy doesn't accept the username and password.\n\n" +
+			"Please review them in the Tor settings.")
+
+	case tor.ErrProxyRefused:
+		return i18n().Sprintf("The configured proxy refuses to connect Tor to the Internet.\n\n" +
+			"Please check with the administrators of your network that the proxy " +
+			"allows connecting to the Tor network.")
+
+	case tor.ErrNetworkUnreachable:
+		return i18n().Sprintf("Tor can't reach the Tor network.\n\n" +
+			"Please check that your computer is connected to the Internet. If it is, the network " +
+			"you are using could be blocking Tor, and you can configure bridges in the Tor settings.")
+
+	case tor.ErrDirectoryFetchFailed:
+		return i18n().Sprintf("Tor can't download the information about the Tor network.\n\n" +
+			"The network you are using could be blocking Tor. Please try again later or " +
+			"configure bridges in the Tor settings.")
+
+	case tor.ErrBridgeConnectionFailed:
+		return i18n().Sprintf("Tor can't connect to the Tor network through the configured bridges.\n\n" +
+			"Please get new bridges from https://bridges.torproject.org and configure them in the Tor settings.")
+
+	case tor.ErrDangerousTorVersion:
+		return i18n().Sprintf("Tor can't connect to the Tor network and it reports that the installed " +
+			"version of Tor is obsolete.\n\nPlease update Tor.")
+
 	case tor.ErrInvalidTorPath:
 	default:
 		return i18n().Sprintf("No valid Tor binary found on the system.")
@@ -23,11 +23,17 @@ const (
 
 // Graphics represent the graphic configuration
 type Graphics struct {
-	gtk  gtki.Gtk
-	gdk  gdki.Gdk
-	glib glibi.Glib
+	gtk         gtki.Gtk
+	gdk         gdki.Gdk
+	glib        glibi.Glib
+	connectOpen ApplicationOpenConnector
 }
 
+// ApplicationOpenConnector connects the given function to the open signal of
+// the application, which receives the invitations given to Wahay as paths or
+// URIs, since the adapters can't marshal the files of that signal
+type ApplicationOpenConnector func(app gtki.Application, onOpen func([]string))
+
 var g Graphics
 
 // CreateGraphics creates a Graphic representation from the given arguments
@@ -39,6 +45,13 @@ func CreateGraphics(gtkVal gtki.Gtk, glibVal glibi.Glib, gdkVal gdki.Gdk) Graphi
 	}
 }
 
+// WithApplicationOpen returns the graphics with the given connector for the
+// open signal of the application
+func (g Graphics) WithApplicationOpen(c ApplicationOpenConnector) Graphics {
+	g.connectOpen = c
+	return g
+}
+
 // UI is the user interface functionality exposed to main
 type UI interface {
 	Loop()
@@ -56,8 +69,10 @@ type gtkUI struct {
 	mainWindow     gtki.ApplicationWindow
 	currentWindow  gtki.Window
 	loadingWindow  gtki.Window
+	loadingBuilder *uiBuilder
 	g              Graphics
 	tor            tor.Instance
+	torPool        tor.Pool
 	torInitialized *sync.WaitGroup
 	client         client.Instance
 	keySupplier    config.KeySupplier
@@ -65,6 +80,10 @@ type gtkUI struct {
 	servers        hosting.Servers
 	errorHandler   *errorHandler
 	cleanupHandler *cleanupHandler
+	activated      bool
+	// pendingInvitations are the invitations received
+	// before the main window was created
+	pendingInvitations []string
 	colorManager
 }
 
@@ -74,7 +93,7 @@ func NewGTK(gx Graphics) UI {
 	runtime.LockOSThread()
 	g.gtk.Init(argsWithApplicationName())
 
-	app, err := g.gtk.ApplicationNew(applicationID, glibi.APPLICATION_FLAGS_NONE)
+	app, err := g.gtk.ApplicationNew(applicationID, glibi.APPLICATION_HANDLES_OPEN)
 	if err != nil {
 		fatalf("Couldn't create application: %v", err)
 	}
@@ -92,9 +111,19 @@ func NewGTK(gx Graphics) UI {
 func (u *gtkUI) Loop() {
 	// This Connect call returns a signal handle, but that's not useful
 	// for us, so we ignore it.
+	_ = u.app.Connect("startup", u.onStartup)
 	_ = u.app.Connect("activate", u.onActivate)
+	if u.g.connectOpen != nil {
+		u.g.connectOpen(u.app, u.onOpen)
+	}
+
+	u.app.Run(applicationArguments())
+}
 
-	u.app.Run([]string{})
+// applicationArguments returns the arguments GIO handles: the name of the
+// program and the invitations, since the flags have already been parsed
+func applicationArguments() []string {
+	return append([]string{programName}, config.InvitationArguments()...)
 }
 
 func (u *gtkUI) initTasks() {
@@ -112,7 +141,22 @@ func (u *gtkUI) initTasks() {
 	u.ensureInstallation()
 }
 
+// onStartup is only called once, in the first running instance of Wahay
+func (u *gtkUI) onStartup() {
+	// Only the temp dirs of executions of Wahay that aren't
+	// running anymore are removed, like the ones that crashed
+	config.RemoveStaleTempDirs()
+}
+
 func (u *gtkUI) onActivate() {
+	if u.activated {
+		if u.currentWindow != nil {
+			u.currentWindow.Present()
+		}
+		return
+	}
+	u.activated = true
+
 	u.displayLoadingWindowWithCallback(u.quit)
 	go func() {
 		u.loadConfig()
@@ -136,6 +180,7 @@ func (u *gtkUI) configLoaded() {
 
 		u.doInUIThread(func() {
 			u.createMainWindow()
+			u.openPendingInvitations()
 		})
 	})
 }
@@ -187,6 +187,10 @@ func getImage(imageName string) []byte {
 }
 
 func (g Graphics) getImagePixbufForSize(imageName string, size int) (gdki.Pixbuf, error) {
+	return g.getPixbufFromBytes(getImage(imageName), size)
+}
+
+func (g Graphics) getPixbufFromBytes(bytes []byte, size int) (gdki.Pixbuf, error) {
 	var w sync.WaitGroup
 
 	pl, err := g.gdk.PixbufLoaderNew()
@@ -204,7 +208,6 @@ func (g Graphics) getImagePixbufForSize(imageName string, size int) (gdki.Pixbuf
 		pl.SetSize(size, size)
 	})
 
-	bytes := getImage(imageName)
 	if _, err := pl.Write(bytes); err != nil {
 		return nil, err
 	}
@@ -128,9 +128,10 @@ func (s *WahayGUIUIReaderSuite) Test_readFile_failsIfErrorHappens(c *C) {
 func (s *WahayGUIUIReaderSuite) Test_getConfigFileFor_returnsTheWahayDesktopConfigFile(c *C) {
 	val := getConfigFileFor("wahay", ".desktop")
 
-	c.Assert(val, HasLen, 221)
+	c.Assert(val, HasLen, 288)
 	c.Assert(val, Contains, "Terminal=false")
 	c.Assert(val, Contains, "Secure and Decentralized Conference")
+	c.Assert(val, Contains, "x-scheme-handler/wahay")
 }
 
 func (s *WahayGUIUIReaderSuite) Test_getConfigFileFor_panicsWhenAskedForAConfigFileThatDoesntExist(c *C) {
@@ -79,6 +79,7 @@ func noPointInEverCallingThisButYouCanIfYouReallyFeelLikeIt2() {
 	_ = i18n().Sprintf("Master password")
 	_ = i18n().Sprintf("Meeting ID")
 	_ = i18n().Sprintf("Tip: Push right control to talk")
+	_ = i18n().Sprintf("Everyone in this meeting should see the same words. Read them aloud to confirm you are all connected to the genuine host.")
 	_ = i18n().Sprintf("Invite others")
 	_ = i18n().Sprintf("One or more errors have been found that prevent Wahay from working properly:")
 	_ = i18n().Sprintf("You can download the Wahay bundle with Mumble and Tor from our website: https://wahay.org/download.html")
@@ -87,6 +88,29 @@ func noPointInEverCallingThisButYouCanIfYouReallyFeelLikeIt2() {
 func noPointInEverCallingThisButYouCanIfYouReallyFeelLikeIt3() {
 	_ = i18n().Sprintf("Meeting ID:")
 	_ = i18n().Sprintf("Meeting password")
+	_ = i18n().Sprintf("Meeting title")
+	_ = i18n().Sprintf("Meeting ID as words, to read it aloud")
+	_ = i18n().Sprintf("Include the meeting password")
+	_ = i18n().Sprintf("Anyone with the invitation link or file will be able to join the meeting without knowing the password")
+	_ = i18n().Sprintf("Copy Invitation Link")
+	_ = i18n().Sprintf("Save Invitation File")
+	_ = i18n().Sprintf("Scan this code from another device to join the meeting")
+	_ = i18n().Sprintf("Export QR Code")
+	_ = i18n().Sprintf("Read the invitation from a QR code image")
+	_ = i18n().Sprintf("Connect to Tor using bridges")
+	_ = i18n().Sprintf("Use bridges if the Tor network is blocked where you are")
+	_ = i18n().Sprintf("Enter one bridge per line, as given by https://bridges.torproject.org. " +
+		"The obfs4, meek_lite and snowflake transports are supported when their programs are installed next to Tor. " +
+		"The changes will be used the next time Wahay starts.")
+	_ = i18n().Sprintf("Some of the bridges are not valid")
+	_ = i18n().Sprintf("Import bridges from a file")
+	_ = i18n().Sprintf("Join every meeting with the same identity")
+	_ = i18n().Sprintf("Allow the hosts to recognise you when you join their meetings again")
+	_ = i18n().Sprintf("By default, you join each meeting with a new identity, so the hosts can't know if you " +
+		"joined their meetings before. With the same identity, the hosts can recognise you and register you " +
+		"with permissions in their meetings. The identity is kept in the configuration file.")
+	_ = i18n().Sprintf("Import identity")
+	_ = i18n().Sprintf("Export identity")
 	_ = i18n().Sprintf("Mumble")
 	_ = i18n().Sprintf("No, cancel")
 	_ = i18n().Sprintf("Now you are hosting a meeting.")
@@ -103,6 +127,7 @@ func noPointInEverCallingThisButYouCanIfYouReallyFeelLikeIt3() {
 	_ = i18n().Sprintf("Settings")
 	_ = i18n().Sprintf("Show")
 	_ = i18n().Sprintf("Specify a password for the meeting")
+	_ = i18n().Sprintf("Specify a title participants will see before joining")
 	_ = i18n().Sprintf("Start meeting")
 	_ = i18n().Sprintf("The error message")
 	_ = i18n().Sprintf("The meeting ID has been copied to the clipboard")
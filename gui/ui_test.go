@@ -112,7 +112,7 @@ func (s *WahayGUISuite) Test_NewGTK_callsApplicationNewWithAppropriateArguments(
 
 	c.Assert(ourGtk.applicationNewCalled, Equals, true)
 	c.Assert(ourGtk.applicationNewArg1, Equals, "digital.autonomia.Wahay")
-	c.Assert(ourGtk.applicationNewArg2, Equals, glibi.APPLICATION_FLAGS_NONE)
+	c.Assert(ourGtk.applicationNewArg2, Equals, glibi.APPLICATION_HANDLES_OPEN)
 }
 
 func (s *WahayGUISuite) Test_NewGTK_panicsIfApplicationNewFails(c *C) {
@@ -132,7 +132,7 @@ func (s *WahayGUISuite) Test_NewGTK_returnsAGTKUIWithProperData(c *C) {
 	ret := NewGTK(g1).(*gtkUI)
 
 	c.Assert(ret.app, Equals, app)
-	c.Assert(ret.g, Equals, g1)
+	c.Assert(ret.g, DeepEquals, g1)
 }
 
 // TODO: uncomment this when the code were reviewed
@@ -224,5 +224,29 @@ func (s *WahayGUISuite) Test_gtkUI_Loop_runsTheAppWithArguments(c *C) {
 	}
 	u.Loop()
 
-	c.Assert(app.runArg1, DeepEquals, []string{})
+	c.Assert(app.runArg1, DeepEquals, []string{"Wahay"})
+}
+
+func (s *WahayGUISuite) Test_gtkUI_Loop_connectsTheOpenSignal(c *C) {
+	app := &testApplication{}
+	var connectedApp gtki.Application
+	var onOpen func([]string)
+
+	g := CreateGraphics(&testGtkStruct{}, &testGlibStruct{}, nil).
+		WithApplicationOpen(func(a gtki.Application, f func([]string)) {
+			connectedApp = a
+			onOpen = f
+		})
+	u := &gtkUI{
+		app:       app,
+		g:         g,
+		activated: true,
+	}
+	u.Loop()
+
+	c.Assert(connectedApp, Equals, app)
+	c.Assert(onOpen, NotNil)
+
+	onOpen([]string{"/tmp/one.wahay", "wahay:two"})
+	c.Assert(u.pendingInvitations, DeepEquals, []string{"/tmp/one.wahay", "wahay:two"})
 }
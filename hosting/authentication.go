@@ -0,0 +1,42 @@
+package hosting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/digitalautonomy/wahay/wordlist"
+)
+
+const (
+	authenticationStringContext = "wahay-sas-v1"
+	authenticationStringWords   = 4
+)
+
+// ShortAuthenticationString returns a few words derived from the onion address of the
+// meeting and the fingerprint of the Mumble server certificate. Participants that are
+// connected to the same meeting will get exactly the same words, so they can read
+// them aloud to confirm nobody is impersonating the host
+func ShortAuthenticationString(meetingID, fingerprint string) ([]string, error) {
+	digest, err := hex.DecodeString(fingerprint)
+	if err != nil || len(digest) == 0 {
+		return nil, ErrInvalidFingerprint
+	}
+
+	h := sha256.New()
+	h.Write([]byte(authenticationStringContext))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeOnionAddress(meetingID)))
+	h.Write([]byte{0})
+	h.Write(digest)
+
+	return wordlist.Encode(h.Sum(nil)[:authenticationStringWords]), nil
+}
+
+func normalizeOnionAddress(meetingID string) string {
+	id := strings.ToLower(strings.TrimSpace(meetingID))
+	if idx := strings.Index(id, ":"); idx != -1 {
+		id = id[:idx]
+	}
+	return strings.TrimSuffix(id, ".onion")
+}
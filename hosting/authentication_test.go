@@ -0,0 +1,54 @@
+package hosting
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+const testOnion = "qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid.onion"
+const testFingerprint = "0123456789abcdef0123456789abcdef01234567"
+
+func (s *hostingSuite) Test_ShortAuthenticationString_isDeterministic(c *C) {
+	w1, err := ShortAuthenticationString(testOnion, testFingerprint)
+	c.Assert(err, IsNil)
+
+	w2, err := ShortAuthenticationString(testOnion, testFingerprint)
+	c.Assert(err, IsNil)
+
+	c.Assert(w1, HasLen, authenticationStringWords)
+	c.Assert(w1, DeepEquals, w2)
+}
+
+func (s *hostingSuite) Test_ShortAuthenticationString_returnsTheExpectedWords(c *C) {
+	w, err := ShortAuthenticationString(testOnion, testFingerprint)
+
+	c.Assert(err, IsNil)
+	c.Assert(w, DeepEquals, []string{"fiber", "badge", "cherry", "cinema"})
+}
+
+func (s *hostingSuite) Test_ShortAuthenticationString_ignoresTheWayTheMeetingIDIsWritten(c *C) {
+	expected, _ := ShortAuthenticationString(testOnion, testFingerprint)
+
+	w, err := ShortAuthenticationString("QVDJPOQCG572IBYLV673QR76IWASHLAZH6SPM47LY37W65IWWMKBMTID.onion:1234", "0123456789ABCDEF0123456789ABCDEF01234567")
+	c.Assert(err, IsNil)
+	c.Assert(w, DeepEquals, expected)
+
+	w, err = ShortAuthenticationString("qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid", testFingerprint)
+	c.Assert(err, IsNil)
+	c.Assert(w, DeepEquals, expected)
+}
+
+func (s *hostingSuite) Test_ShortAuthenticationString_changesWithTheCertificate(c *C) {
+	w1, _ := ShortAuthenticationString(testOnion, testFingerprint)
+	w2, _ := ShortAuthenticationString(testOnion, "1123456789abcdef0123456789abcdef01234567")
+
+	c.Assert(w1, Not(DeepEquals), w2)
+}
+
+func (s *hostingSuite) Test_ShortAuthenticationString_returnsAnErrorWhenTheFingerprintIsInvalid(c *C) {
+	w, err := ShortAuthenticationString(testOnion, "")
+	c.Assert(err, Equals, ErrInvalidFingerprint)
+	c.Assert(w, IsNil)
+
+	_, err = ShortAuthenticationString(testOnion, "not a fingerprint")
+	c.Assert(err, Equals, ErrInvalidFingerprint)
+}
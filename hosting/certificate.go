@@ -2,6 +2,8 @@ package hosting
 
 import (
 	"context"
+	"crypto"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -16,18 +18,30 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/digitalautonomy/wahay/config"
+	"github.com/digitalautonomy/wahay/tor"
 )
 
 type webserver struct {
 	sync.WaitGroup
-	port    int
-	address string
-	cert    []byte
-	running bool
-	server  *http.Server
+	port        int
+	address     string
+	socket      string
+	cert        []byte
+	fingerprint string
+	key         crypto.Signer
+	running     bool
+	server      *http.Server
+
+	meetingLock      sync.RWMutex
+	title            string
+	welcomeText      string
+	passwordRequired bool
 }
 
-const certServerPort = 8181
+const (
+	certServerPort       = 8181
+	certServerSocketName = "certificate.sock"
+)
 
 var ioutilReadFile = ioutil.ReadFile
 
@@ -42,16 +56,28 @@ func newCertificateServer(dir string) (*webserver, error) {
 		return nil, err
 	}
 
+	fingerprint, err := CertificateFingerprint(cert)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"certificate": certFile,
+		}).Warnf("newCertificateServer(): the certificate fingerprint can't be calculated: %s", err)
+	}
+
 	port := config.GetRandomPort()
 	address := net.JoinHostPort(defaultHost(), strconv.Itoa(port))
 
 	s := &webserver{
-		port:    port,
-		address: address,
-		cert:    cert,
+		port:        port,
+		address:     address,
+		cert:        cert,
+		fingerprint: fingerprint,
+		key:         readSigningKey(filepath.Join(dir, "key.pem")),
 	}
 
 	h := http.NewServeMux()
+	h.HandleFunc(MeetingMetadataPath, s.handleMeetingMetadataRequest)
+	// The bare certificate is still served for participants
+	// using versions of Wahay without meeting metadata support
 	h.HandleFunc("/", s.handleCertificateRequest)
 
 	s.server = &http.Server{
@@ -87,7 +113,7 @@ func (h *webserver) start(onFails func(error)) {
 
 		h.running = true
 
-		err := h.server.ListenAndServe()
+		err := h.listenAndServe()
 		if err != http.ErrServerClosed {
 			if onFails != nil {
 				onFails(err)
@@ -98,6 +124,58 @@ func (h *webserver) start(onFails func(error)) {
 	}()
 }
 
+type connectionLimiterKey struct{}
+
+// limitRequests limits the requests accepted through
+// each connection to the server, as given in the protection
+func (h *webserver) limitRequests(p Protection) {
+	handler := h.server.Handler
+
+	h.server.ConnContext = func(ctx context.Context, _ net.Conn) context.Context {
+		return context.WithValue(ctx, connectionLimiterKey{}, p.newConnectionLimiter())
+	}
+
+	h.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l, _ := r.Context().Value(connectionLimiterKey{}).(*rateLimiter)
+		if l != nil && !l.allow() {
+			log.Warn("Certificate HTTP server: too many requests through the same connection")
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// useUnixSocket makes the server listen in the Unix domain
+// socket at the given path instead of its TCP port
+func (h *webserver) useUnixSocket(path string) {
+	h.socket = path
+	h.address = tor.UnixSocketAddress(path)
+}
+
+func (h *webserver) listenAndServe() error {
+	if h.socket == "" {
+		return h.server.ListenAndServe()
+	}
+
+	l, err := net.Listen("unix", h.socket)
+	if err != nil {
+		return err
+	}
+
+	return h.server.Serve(l)
+}
+
+func (h *webserver) onionPort() tor.OnionPort {
+	return tor.OnionPort{
+		DestinationHost:   defaultHost(),
+		DestinationPort:   h.port,
+		DestinationSocket: h.socket,
+		ServicePort:       certServerPort,
+	}
+}
+
 func (h *webserver) stop() error {
 	if !h.running {
 		log.WithFields(log.Fields{
@@ -127,11 +205,77 @@ func (h *webserver) stop() error {
 	return nil
 }
 
+// readSigningKey returns the private key of the Mumble server certificate,
+// which is used to sign the meeting invitations
+func readSigningKey(keyFile string) crypto.Signer {
+	content, err := ioutilReadFile(filepath.Clean(keyFile))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"key": keyFile,
+		}).Warnf("readSigningKey(): invitations won't be signed: %s", err)
+		return nil
+	}
+
+	key, err := parsePrivateKey(content)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"key": keyFile,
+		}).Warnf("readSigningKey(): invitations won't be signed: %s", err)
+		return nil
+	}
+
+	return key
+}
+
+func (h *webserver) setMeetingInformation(title, welcomeText string, passwordRequired bool) {
+	h.meetingLock.Lock()
+	defer h.meetingLock.Unlock()
+
+	h.title = title
+	h.welcomeText = welcomeText
+	h.passwordRequired = passwordRequired
+}
+
+func (h *webserver) meetingMetadata() *MeetingMetadata {
+	h.meetingLock.RLock()
+	defer h.meetingLock.RUnlock()
+
+	return &MeetingMetadata{
+		Version:          MeetingMetadataVersion,
+		WahayVersion:     config.ApplicationVersion(),
+		Certificate:      string(h.cert),
+		Fingerprint:      h.fingerprint,
+		Title:            h.title,
+		WelcomeText:      h.welcomeText,
+		PasswordRequired: h.passwordRequired,
+		Features:         supportedFeatures,
+	}
+}
+
 func (h *webserver) handleCertificateRequest(w http.ResponseWriter, r *http.Request) {
 	log.Debug("handleCertificateRequest(): serving certificate content")
 	fmt.Fprint(w, string(h.cert))
 }
 
+func (h *webserver) handleMeetingMetadataRequest(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query().Get(MeetingMetadataVersionParameter)
+	if len(v) != 0 && v != strconv.Itoa(MeetingMetadataVersion) {
+		log.WithFields(log.Fields{
+			"version": v,
+		}).Warn("handleMeetingMetadataRequest(): a participant requested an unsupported metadata version")
+		http.Error(w, "unsupported meeting metadata version", http.StatusNotAcceptable)
+		return
+	}
+
+	log.Debug("handleMeetingMetadataRequest(): serving meeting metadata")
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(h.meetingMetadata())
+	if err != nil {
+		log.Errorf("handleMeetingMetadataRequest(): %s", err)
+	}
+}
+
 func fileExists(filename string) bool {
 	_, err := os.Stat(filename)
 	return err == nil
@@ -7,35 +7,61 @@ import (
 	"net"
 
 	"github.com/digitalautonomy/wahay/config"
+	"github.com/digitalautonomy/wahay/tor"
 	log "github.com/sirupsen/logrus"
 )
 
 type checkService struct {
-	port int
-	l    net.Listener
-	conn net.Conn
+	port       int
+	socket     string
+	protection Protection
+	l          net.Listener
+	conn       net.Conn
 }
 
-const checkConnectionPort = 12321
+const (
+	checkConnectionPort       = 12321
+	checkConnectionSocketName = "check.sock"
+)
+
+// newCheckConnectionService creates the service listening in the Unix domain
+// socket at the given path or, when no path is given, in a random TCP port
+func newCheckConnectionService(socket string) (*checkService, error) {
+	if socket != "" {
+		return newCheckConnectionServiceOn(0, socket, "unix", socket)
+	}
 
-func newCheckConnectionService() (*checkService, error) {
 	checkPort := config.GetRandomPort()
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", checkPort))
+	return newCheckConnectionServiceOn(checkPort, "", "tcp", fmt.Sprintf(":%d", checkPort))
+}
+
+func newCheckConnectionServiceOn(port int, socket, network, address string) (*checkService, error) {
+	listener, err := net.Listen(network, address)
 	if err != nil {
-		log.Errorf("Failed to start server on port %v: %v\n", checkPort, err)
+		log.Errorf("Failed to start server on %v: %v\n", address, err)
 		return nil, err
 	}
 
-	log.Infof("Check connection server listening on port: %v\n", checkPort)
+	log.Infof("Check connection server listening on: %v\n", address)
 
 	cs := &checkService{
-		l:    listener,
-		port: checkPort,
+		l:      listener,
+		port:   port,
+		socket: socket,
 	}
 
 	return cs, nil
 }
 
+func (cs *checkService) onionPort() tor.OnionPort {
+	return tor.OnionPort{
+		DestinationHost:   defaultHost(),
+		DestinationPort:   cs.port,
+		DestinationSocket: cs.socket,
+		ServicePort:       checkConnectionPort,
+	}
+}
+
 func (cs *checkService) start() {
 	go func() {
 		for {
@@ -52,6 +78,8 @@ func (cs *checkService) start() {
 func (cs *checkService) handleClient() {
 	defer cs.conn.Close()
 
+	limiter := cs.protection.newConnectionLimiter()
+
 	for {
 		message, err := cs.waitForClientMessage()
 		if err != nil {
@@ -59,6 +87,11 @@ func (cs *checkService) handleClient() {
 			break
 		}
 
+		if limiter != nil && !limiter.allow() {
+			log.Warn("Check connection server: too many messages through the same connection")
+			break
+		}
+
 		log.Debug(message)
 
 		status, err := cs.sendConnectionConfirmation()
@@ -0,0 +1,331 @@
+package hosting
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// InvitationVersion is the version of the invitation format generated by this version of Wahay
+	InvitationVersion = 1
+
+	// InvitationScheme is the URI scheme Wahay is registered to handle
+	InvitationScheme = "wahay"
+
+	// InvitationFileExtension is the extension of the invitation files
+	InvitationFileExtension = ".wahay"
+
+	// DefaultInvitationValidity is how long an invitation can be used after being created
+	DefaultInvitationValidity = 7 * 24 * time.Hour
+
+	invitationAction = "join"
+)
+
+const (
+	invitationFieldVersion       = "v"
+	invitationFieldOnion         = "onion"
+	invitationFieldPort          = "port"
+	invitationFieldPassword      = "password"
+	invitationFieldFingerprint   = "fingerprint"
+	invitationFieldExpires       = "expires"
+	invitationFieldTitle         = "title"
+	invitationFieldClientAuthKey = "auth"
+	invitationFieldSignature     = "sig"
+)
+
+var (
+	// ErrInvalidInvitation is an error to return when an invitation can't be understood
+	ErrInvalidInvitation = errors.New("invalid invitation")
+	// ErrIncompatibleInvitation is an error to return when the invitation has been
+	// created by an incompatible version of Wahay
+	ErrIncompatibleInvitation = errors.New("the invitation has been created with an incompatible version of Wahay")
+	// ErrInvitationExpired is an error to return when the invitation can't be used anymore
+	ErrInvitationExpired = errors.New("the invitation has expired")
+	// ErrInvalidInvitationSignature is an error to return when the invitation
+	// hasn't been signed with the key of the meeting server
+	ErrInvalidInvitationSignature = errors.New("the invitation signature is not valid")
+
+	errUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Invitation is a representation of all the information a participant
+// needs to join a meeting. It can be shared as a wahay:// URI or as a file
+type Invitation struct {
+	Version     int       `json:"version"`
+	Onion       string    `json:"onion"`
+	Port        int       `json:"port"`
+	Password    string    `json:"password,omitempty"`
+	Fingerprint string    `json:"fingerprint"`
+	Expires     time.Time `json:"expires"`
+	Title       string    `json:"title,omitempty"`
+	// ClientAuthKey is the optional private key a participant needs when the
+	// onion service of the meeting requires client authorization. It's
+	// covered by the signature, so it can't be removed or replaced
+	ClientAuthKey string `json:"client_auth_key,omitempty"`
+	Signature     []byte `json:"signature,omitempty"`
+}
+
+// MeetingID returns the meeting ID as it's written by the participants
+func (i *Invitation) MeetingID() string {
+	if i.Port != 0 && i.Port != DefaultPort {
+		return net.JoinHostPort(i.Onion, strconv.Itoa(i.Port))
+	}
+	return i.Onion
+}
+
+// Expired returns a boolean indicating if the invitation can't be used anymore
+func (i *Invitation) Expired() bool {
+	return !i.Expires.IsZero() && time.Now().After(i.Expires)
+}
+
+// Validate returns an error if the invitation can't be used to join a meeting
+func (i *Invitation) Validate() error {
+	if i.Version != InvitationVersion {
+		return ErrIncompatibleInvitation
+	}
+
+	if len(i.Onion) == 0 || i.Port < 0 || i.Port > 65535 {
+		return ErrInvalidInvitation
+	}
+
+	if len(i.Fingerprint) != 0 && !isValidFingerprint(i.Fingerprint) {
+		return ErrInvalidFingerprint
+	}
+
+	if i.Expired() {
+		return ErrInvitationExpired
+	}
+
+	return nil
+}
+
+func (i *Invitation) values() url.Values {
+	v := url.Values{}
+	v.Set(invitationFieldVersion, strconv.Itoa(i.Version))
+	v.Set(invitationFieldOnion, i.Onion)
+
+	if i.Port != 0 {
+		v.Set(invitationFieldPort, strconv.Itoa(i.Port))
+	}
+	if len(i.Password) != 0 {
+		v.Set(invitationFieldPassword, i.Password)
+	}
+	if len(i.Fingerprint) != 0 {
+		v.Set(invitationFieldFingerprint, i.Fingerprint)
+	}
+	if !i.Expires.IsZero() {
+		v.Set(invitationFieldExpires, strconv.FormatInt(i.Expires.Unix(), 10))
+	}
+	if len(i.Title) != 0 {
+		v.Set(invitationFieldTitle, i.Title)
+	}
+	if len(i.ClientAuthKey) != 0 {
+		v.Set(invitationFieldClientAuthKey, i.ClientAuthKey)
+	}
+
+	return v
+}
+
+// signedContent returns the content covered by the signature. It's the same for
+// the URI and the file representations, since url.Values are always sorted by key
+func (i *Invitation) signedContent() []byte {
+	return []byte(i.values().Encode())
+}
+
+// URI returns the invitation as a wahay://join URI
+func (i *Invitation) URI() string {
+	v := i.values()
+	if len(i.Signature) != 0 {
+		v.Set(invitationFieldSignature, base64.RawURLEncoding.EncodeToString(i.Signature))
+	}
+
+	u := &url.URL{
+		Scheme:   InvitationScheme,
+		Host:     invitationAction,
+		RawQuery: v.Encode(),
+	}
+
+	return u.String()
+}
+
+// File returns the content of the invitation file
+func (i *Invitation) File() ([]byte, error) {
+	return json.MarshalIndent(i, "", "  ")
+}
+
+// ParseInvitationURI returns the invitation contained in the given wahay:// URI
+func ParseInvitationURI(uri string) (*Invitation, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != InvitationScheme || u.Host != invitationAction {
+		return nil, ErrInvalidInvitation
+	}
+
+	q := u.Query()
+
+	i := &Invitation{
+		Onion:         q.Get(invitationFieldOnion),
+		Password:      q.Get(invitationFieldPassword),
+		Fingerprint:   strings.ToLower(q.Get(invitationFieldFingerprint)),
+		Title:         q.Get(invitationFieldTitle),
+		ClientAuthKey: q.Get(invitationFieldClientAuthKey),
+	}
+
+	i.Version, err = strconv.Atoi(q.Get(invitationFieldVersion))
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	if p := q.Get(invitationFieldPort); len(p) != 0 {
+		i.Port, err = strconv.Atoi(p)
+		if err != nil {
+			return nil, ErrInvalidInvitation
+		}
+	}
+
+	if e := q.Get(invitationFieldExpires); len(e) != 0 {
+		ts, err := strconv.ParseInt(e, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidInvitation
+		}
+		i.Expires = time.Unix(ts, 0)
+	}
+
+	if s := q.Get(invitationFieldSignature); len(s) != 0 {
+		i.Signature, err = base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, ErrInvalidInvitation
+		}
+	}
+
+	return i, nil
+}
+
+// ParseInvitationFile returns the invitation contained in the content of an invitation file
+func ParseInvitationFile(content []byte) (*Invitation, error) {
+	i := &Invitation{}
+	err := json.Unmarshal(content, i)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	i.Fingerprint = strings.ToLower(i.Fingerprint)
+
+	return i, nil
+}
+
+var osReadFile = os.ReadFile
+
+// ReadInvitation returns the invitation from a wahay:// URI or from the path to an
+// invitation file. The invitation is validated but its signature isn't verified,
+// since that requires the certificate of the meeting server
+func ReadInvitation(uriOrPath string) (*Invitation, error) {
+	var i *Invitation
+	var err error
+
+	if strings.HasPrefix(strings.ToLower(uriOrPath), InvitationScheme+":") {
+		i, err = ParseInvitationURI(uriOrPath)
+	} else {
+		var content []byte
+		content, err = osReadFile(filepath.Clean(uriOrPath))
+		if err != nil {
+			return nil, err
+		}
+		i, err = ParseInvitationFile(content)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Sign signs the invitation with the private key of the meeting server
+func (i *Invitation) Sign(key crypto.Signer) error {
+	digest := sha256.Sum256(i.signedContent())
+
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return err
+	}
+
+	i.Signature = signature
+	return nil
+}
+
+// VerifySignature checks that the invitation has been signed with the
+// private key of the given PEM encoded meeting server certificate
+func (i *Invitation) VerifySignature(cert []byte) error {
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errInvalidCertificate
+	}
+
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errInvalidCertificate
+	}
+
+	digest := sha256.Sum256(i.signedContent())
+
+	switch pub := c.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], i.Signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], i.Signature) {
+			err = ErrInvalidInvitationSignature
+		}
+	default:
+		err = errUnsupportedKey
+	}
+
+	if err != nil {
+		return ErrInvalidInvitationSignature
+	}
+
+	return nil
+}
+
+func parsePrivateKey(content []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errUnsupportedKey
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := k.(crypto.Signer); ok {
+			return s, nil
+		}
+	}
+
+	return nil, errUnsupportedKey
+}
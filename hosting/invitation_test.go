@@ -0,0 +1,286 @@
+package hosting
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/prashantv/gostub"
+	. "gopkg.in/check.v1"
+)
+
+const testInvitationOnion = "ynbtnwqwvhsnp2ok6mjecsbgkjvvgfe7afhbk5tvozjlmdrpqezyhxid.onion"
+
+func testInvitation() *Invitation {
+	return &Invitation{
+		Version:     InvitationVersion,
+		Onion:       testInvitationOnion,
+		Port:        DefaultPort,
+		Password:    "secret password",
+		Fingerprint: "0123456789abcdef0123456789abcdef01234567",
+		Expires:     time.Unix(time.Now().Add(time.Hour).Unix(), 0),
+		Title:       "Weekly meeting & more",
+	}
+}
+
+func generateTestSigningCertificate(c *C) (*ecdsa.PrivateKey, []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Wahay Test Certificate"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	c.Assert(err, IsNil)
+
+	return priv, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func (s *hostingSuite) Test_Invitation_URI_canBeParsedBack(c *C) {
+	i := testInvitation()
+	i.Signature = []byte{0x01, 0x02, 0xff}
+
+	uri := i.URI()
+	c.Assert(strings.HasPrefix(uri, "wahay://join?"), Equals, true)
+
+	parsed, err := ParseInvitationURI(uri)
+
+	c.Assert(err, IsNil)
+	c.Assert(parsed, DeepEquals, i)
+}
+
+func (s *hostingSuite) Test_Invitation_File_canBeParsedBack(c *C) {
+	i := testInvitation()
+	i.Signature = []byte{0x01, 0x02, 0xff}
+
+	content, err := i.File()
+	c.Assert(err, IsNil)
+
+	parsed, err := ParseInvitationFile(content)
+
+	c.Assert(err, IsNil)
+	c.Assert(parsed.Expires.Equal(i.Expires), Equals, true)
+	parsed.Expires = i.Expires
+	c.Assert(parsed, DeepEquals, i)
+}
+
+const testClientAuthKey = "descriptor:x25519:QFZLRIUKZVJDBKRFR2MQAKKSRYBBCVANBLCNALEJXFRKENCMWOIA"
+
+func (s *hostingSuite) Test_Invitation_URI_includesTheClientAuthKeyOnlyWhenThereIsOne(c *C) {
+	i := testInvitation()
+	c.Assert(strings.Contains(i.URI(), "auth="), Equals, false)
+
+	i.ClientAuthKey = testClientAuthKey
+	i.Signature = []byte{0x01, 0x02, 0xff}
+
+	uri := i.URI()
+	c.Assert(strings.Contains(uri, "auth="), Equals, true)
+
+	parsed, err := ReadInvitation(uri)
+
+	c.Assert(err, IsNil)
+	c.Assert(parsed, DeepEquals, i)
+}
+
+func (s *hostingSuite) Test_Invitation_File_includesTheClientAuthKeyOnlyWhenThereIsOne(c *C) {
+	i := testInvitation()
+	content, err := i.File()
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(content), "client_auth_key"), Equals, false)
+
+	i.ClientAuthKey = testClientAuthKey
+	content, err = i.File()
+	c.Assert(err, IsNil)
+
+	defer gostub.Stub(&osReadFile, func(string) ([]byte, error) {
+		return content, nil
+	}).Reset()
+
+	read, err := ReadInvitation("/tmp/meeting.wahay")
+
+	c.Assert(err, IsNil)
+	c.Assert(read.ClientAuthKey, Equals, testClientAuthKey)
+}
+
+func (s *hostingSuite) Test_Invitation_VerifySignature_rejectsInvitationsWithAChangedClientAuthKey(c *C) {
+	key, cert := generateTestSigningCertificate(c)
+
+	i := testInvitation()
+	i.ClientAuthKey = testClientAuthKey
+	c.Assert(i.Sign(key), IsNil)
+
+	parsed, err := ParseInvitationURI(i.URI())
+	c.Assert(err, IsNil)
+	c.Assert(parsed.VerifySignature(cert), IsNil)
+
+	parsed.ClientAuthKey = ""
+	c.Assert(parsed.VerifySignature(cert), Equals, ErrInvalidInvitationSignature)
+
+	parsed.ClientAuthKey = "descriptor:x25519:OTHERKEY"
+	c.Assert(parsed.VerifySignature(cert), Equals, ErrInvalidInvitationSignature)
+}
+
+func (s *hostingSuite) Test_ParseInvitationURI_returnsAnErrorForOtherURIs(c *C) {
+	for _, uri := range []string{
+		"https://join?v=1&onion=abc.onion",
+		"wahay://leave?v=1&onion=abc.onion",
+		"wahay://join?onion=abc.onion",
+		"wahay://join?v=1&onion=abc.onion&port=abc",
+		"wahay://join?v=1&onion=abc.onion&expires=tomorrow",
+		"wahay://join?v=1&onion=abc.onion&sig=!!!",
+	} {
+		_, err := ParseInvitationURI(uri)
+		c.Assert(err, Equals, ErrInvalidInvitation, Commentf("uri: %s", uri))
+	}
+}
+
+func (s *hostingSuite) Test_ParseInvitationFile_returnsAnErrorForInvalidContent(c *C) {
+	_, err := ParseInvitationFile([]byte("not an invitation"))
+	c.Assert(err, Equals, ErrInvalidInvitation)
+}
+
+func (s *hostingSuite) Test_Invitation_MeetingID_includesThePortOnlyWhenItIsNotTheDefault(c *C) {
+	i := testInvitation()
+	c.Assert(i.MeetingID(), Equals, testInvitationOnion)
+
+	i.Port = 12345
+	c.Assert(i.MeetingID(), Equals, testInvitationOnion+":12345")
+}
+
+func (s *hostingSuite) Test_Invitation_Validate_returnsAnErrorForUnusableInvitations(c *C) {
+	i := testInvitation()
+	c.Assert(i.Validate(), IsNil)
+
+	i = testInvitation()
+	i.Version = InvitationVersion + 1
+	c.Assert(i.Validate(), Equals, ErrIncompatibleInvitation)
+
+	i = testInvitation()
+	i.Onion = ""
+	c.Assert(i.Validate(), Equals, ErrInvalidInvitation)
+
+	i = testInvitation()
+	i.Port = 70000
+	c.Assert(i.Validate(), Equals, ErrInvalidInvitation)
+
+	i = testInvitation()
+	i.Fingerprint = "abc"
+	c.Assert(i.Validate(), Equals, ErrInvalidFingerprint)
+
+	i = testInvitation()
+	i.Expires = time.Now().Add(-time.Minute)
+	c.Assert(i.Validate(), Equals, ErrInvitationExpired)
+}
+
+func (s *hostingSuite) Test_Invitation_VerifySignature_acceptsInvitationsSignedByTheServerKey(c *C) {
+	key, cert := generateTestSigningCertificate(c)
+
+	i := testInvitation()
+	c.Assert(i.Sign(key), IsNil)
+
+	c.Assert(i.VerifySignature(cert), IsNil)
+
+	parsed, err := ParseInvitationURI(i.URI())
+	c.Assert(err, IsNil)
+	c.Assert(parsed.VerifySignature(cert), IsNil)
+}
+
+func (s *hostingSuite) Test_Invitation_VerifySignature_rejectsModifiedInvitations(c *C) {
+	key, cert := generateTestSigningCertificate(c)
+
+	i := testInvitation()
+	c.Assert(i.Sign(key), IsNil)
+
+	i.Onion = "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion"
+
+	c.Assert(i.VerifySignature(cert), Equals, ErrInvalidInvitationSignature)
+}
+
+func (s *hostingSuite) Test_Invitation_VerifySignature_rejectsInvitationsSignedByAnotherKey(c *C) {
+	key, _ := generateTestSigningCertificate(c)
+	_, otherCert := generateTestSigningCertificate(c)
+
+	i := testInvitation()
+	c.Assert(i.Sign(key), IsNil)
+
+	c.Assert(i.VerifySignature(otherCert), Equals, ErrInvalidInvitationSignature)
+	c.Assert(i.VerifySignature([]byte("dummy cert")), Equals, errInvalidCertificate)
+}
+
+func (s *hostingSuite) Test_ReadInvitation_readsInvitationURIs(c *C) {
+	i := testInvitation()
+
+	read, err := ReadInvitation(i.URI())
+
+	c.Assert(err, IsNil)
+	c.Assert(read, DeepEquals, i)
+}
+
+func (s *hostingSuite) Test_ReadInvitation_readsInvitationFiles(c *C) {
+	i := testInvitation()
+	content, err := i.File()
+	c.Assert(err, IsNil)
+
+	var readPath string
+	defer gostub.Stub(&osReadFile, func(name string) ([]byte, error) {
+		readPath = name
+		return content, nil
+	}).Reset()
+
+	read, err := ReadInvitation("/tmp/../tmp/meeting.wahay")
+
+	c.Assert(err, IsNil)
+	c.Assert(readPath, Equals, "/tmp/meeting.wahay")
+	c.Assert(read.Onion, Equals, i.Onion)
+	c.Assert(read.Password, Equals, i.Password)
+}
+
+func (s *hostingSuite) Test_ReadInvitation_returnsAnErrorForExpiredInvitations(c *C) {
+	i := testInvitation()
+	i.Expires = time.Now().Add(-time.Minute)
+
+	_, err := ReadInvitation(i.URI())
+
+	c.Assert(err, Equals, ErrInvitationExpired)
+}
+
+func (s *hostingSuite) Test_ReadInvitation_returnsTheErrorWhenTheFileCantBeRead(c *C) {
+	expected := errors.New("file not found")
+	defer gostub.Stub(&osReadFile, func(name string) ([]byte, error) {
+		return nil, expected
+	}).Reset()
+
+	_, err := ReadInvitation("meeting.wahay")
+
+	c.Assert(err, Equals, expected)
+}
+
+func (s *hostingSuite) Test_parsePrivateKey_readsECAndPKCS8Keys(c *C) {
+	key, _ := generateTestSigningCertificate(c)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+	k, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	c.Assert(err, IsNil)
+	c.Assert(k.Public(), DeepEquals, key.Public())
+
+	der, err = x509.MarshalPKCS8PrivateKey(key)
+	c.Assert(err, IsNil)
+	k, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	c.Assert(err, IsNil)
+	c.Assert(k.Public(), DeepEquals, key.Public())
+
+	_, err = parsePrivateKey([]byte("not a key"))
+	c.Assert(err, Equals, errUnsupportedKey)
+}
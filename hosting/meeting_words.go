@@ -0,0 +1,87 @@
+package hosting
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/digitalautonomy/wahay/tor"
+	"github.com/digitalautonomy/wahay/wordlist"
+)
+
+// ErrInvalidMeetingWords is an error to return when a sequence of words
+// doesn't represent a valid meeting ID
+var ErrInvalidMeetingWords = errors.New("the words don't represent a valid meeting ID")
+
+const meetingWordsCount = 33
+
+// MeetingIDAsWords encodes the public key of the meeting onion address as a sequence
+// of words, followed by a checksum word and, if present, the port of the meeting.
+// This makes it possible to dictate a meeting ID by voice
+func MeetingIDAsWords(meetingID string) (string, error) {
+	host, port := meetingID, ""
+	if h, p, err := net.SplitHostPort(meetingID); err == nil {
+		host, port = h, p
+	}
+
+	publicKey, err := tor.OnionPublicKey(host)
+	if err != nil {
+		return "", err
+	}
+
+	bs := append(append([]byte{}, publicKey...), tor.OnionChecksum(publicKey)[0])
+
+	result := wordlist.Encode(bs)
+	if len(port) != 0 {
+		result = append(result, port)
+	}
+
+	return strings.Join(result, " "), nil
+}
+
+// MeetingIDFromWords returns the meeting ID represented by the given words,
+// as generated by MeetingIDAsWords
+func MeetingIDFromWords(words string) (string, error) {
+	ws := strings.Fields(words)
+
+	port := ""
+	if len(ws) == meetingWordsCount+1 {
+		if _, err := strconv.Atoi(ws[meetingWordsCount]); err != nil {
+			return "", ErrInvalidMeetingWords
+		}
+		port = ws[meetingWordsCount]
+		ws = ws[:meetingWordsCount]
+	}
+
+	if len(ws) != meetingWordsCount {
+		return "", ErrInvalidMeetingWords
+	}
+
+	bs, err := wordlist.Decode(ws)
+	if err != nil {
+		return "", ErrInvalidMeetingWords
+	}
+
+	publicKey, checksum := bs[:meetingWordsCount-1], bs[meetingWordsCount-1]
+	if tor.OnionChecksum(publicKey)[0] != checksum {
+		return "", ErrInvalidMeetingWords
+	}
+
+	onion, err := tor.OnionAddress(publicKey)
+	if err != nil {
+		return "", ErrInvalidMeetingWords
+	}
+
+	if len(port) != 0 {
+		return net.JoinHostPort(onion, port), nil
+	}
+
+	return onion, nil
+}
+
+// LooksLikeMeetingWords returns a boolean indicating if the given
+// meeting ID has been written using words instead of an onion address
+func LooksLikeMeetingWords(meetingID string) bool {
+	return len(strings.Fields(meetingID)) > 1
+}
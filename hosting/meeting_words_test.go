@@ -0,0 +1,67 @@
+package hosting
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *hostingSuite) Test_MeetingIDAsWords_encodesThePublicKeyAndAChecksumWord(c *C) {
+	words, err := MeetingIDAsWords(testOnion)
+
+	c.Assert(err, IsNil)
+	ws := strings.Fields(words)
+	c.Assert(ws, HasLen, meetingWordsCount)
+	c.Assert(ws[:4], DeepEquals, []string{"harbor", "clock", "jelly", "needle"})
+}
+
+func (s *hostingSuite) Test_MeetingIDAsWords_includesThePortWhenPresent(c *C) {
+	words, err := MeetingIDAsWords(testOnion + ":1234")
+
+	c.Assert(err, IsNil)
+	c.Assert(words, Matches, `([a-z]+ ){33}1234`)
+}
+
+func (s *hostingSuite) Test_MeetingIDAsWords_returnsAnErrorForInvalidAddresses(c *C) {
+	_, err := MeetingIDAsWords("invalid.onion")
+	c.Assert(err, NotNil)
+}
+
+func (s *hostingSuite) Test_MeetingIDFromWords_returnsTheOriginalMeetingID(c *C) {
+	for _, id := range []string{testOnion, testOnion + ":1234"} {
+		words, _ := MeetingIDAsWords(id)
+
+		result, err := MeetingIDFromWords(strings.ToUpper(words))
+
+		c.Assert(err, IsNil)
+		c.Assert(result, Equals, id)
+	}
+}
+
+func (s *hostingSuite) Test_MeetingIDFromWords_detectsMistakesUsingTheChecksum(c *C) {
+	words, _ := MeetingIDAsWords(testOnion)
+	ws := strings.Fields(words)
+	ws[0], ws[1] = ws[1], ws[0]
+
+	_, err := MeetingIDFromWords(strings.Join(ws, " "))
+
+	c.Assert(err, Equals, ErrInvalidMeetingWords)
+}
+
+func (s *hostingSuite) Test_MeetingIDFromWords_returnsAnErrorForWrongInput(c *C) {
+	words, _ := MeetingIDAsWords(testOnion)
+
+	_, err := MeetingIDFromWords("acorn agent")
+	c.Assert(err, Equals, ErrInvalidMeetingWords)
+
+	_, err = MeetingIDFromWords(words + " port")
+	c.Assert(err, Equals, ErrInvalidMeetingWords)
+
+	_, err = MeetingIDFromWords(strings.Replace(words, strings.Fields(words)[0], "wahay", 1))
+	c.Assert(err, Equals, ErrInvalidMeetingWords)
+}
+
+func (s *hostingSuite) Test_LooksLikeMeetingWords_detectsMeetingIDsWrittenWithWords(c *C) {
+	c.Assert(LooksLikeMeetingWords(testOnion), Equals, false)
+	c.Assert(LooksLikeMeetingWords("acorn agent violin"), Equals, true)
+}
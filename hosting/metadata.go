@@ -0,0 +1,132 @@
+package hosting
+
+import (
+	// #nosec
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MeetingMetadataVersion is the version of the meeting metadata format served by
+	// the hosting HTTP server. Participants will refuse to join a meeting announcing a
+	// different version, since we can't know how to interpret it
+	MeetingMetadataVersion = 1
+
+	// MeetingMetadataPath is the path where the meeting metadata is served
+	MeetingMetadataPath = "/v1/meeting"
+
+	// MeetingMetadataVersionParameter is the query parameter used by participants to
+	// tell the host which version of the meeting metadata format they understand
+	MeetingMetadataVersionParameter = "version"
+)
+
+const (
+	// FeatureConnectionCheck indicates that the host is running the
+	// connection checker service used by the participant's forwarder
+	FeatureConnectionCheck = "connection-check"
+)
+
+var supportedFeatures = []string{
+	FeatureConnectionCheck,
+}
+
+// MeetingMetadata is a representation of the information a host
+// shares with the participants before they join the meeting
+type MeetingMetadata struct {
+	Version          int      `json:"version"`
+	WahayVersion     string   `json:"wahay_version"`
+	Certificate      string   `json:"certificate"`
+	Fingerprint      string   `json:"fingerprint"`
+	Title            string   `json:"title"`
+	WelcomeText      string   `json:"welcome_text"`
+	PasswordRequired bool     `json:"password_required"`
+	Features         []string `json:"features"`
+}
+
+var (
+	// ErrIncompatibleMeetingVersion is an error to return when the meeting
+	// metadata has been created by an incompatible version of Wahay
+	ErrIncompatibleMeetingVersion = errors.New("the meeting has been created with an incompatible version of Wahay")
+
+	// ErrInvalidFingerprint is an error to return when the certificate
+	// fingerprint included in a meeting ID is not well formed
+	ErrInvalidFingerprint = errors.New("invalid certificate fingerprint")
+
+	errInvalidCertificate = errors.New("invalid certificate")
+)
+
+// CheckCompatibility returns an error if the metadata can't be used by this version of Wahay
+func (m *MeetingMetadata) CheckCompatibility() error {
+	if m.Version != MeetingMetadataVersion {
+		return ErrIncompatibleMeetingVersion
+	}
+	return nil
+}
+
+// HasFeature returns a boolean indicating if the host supports the given feature
+func (m *MeetingMetadata) HasFeature(feature string) bool {
+	for _, f := range m.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificateFingerprint returns the fingerprint of the given PEM encoded certificate,
+// in the same format the Mumble client uses to identify trusted server certificates
+func CertificateFingerprint(cert []byte) (string, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", errInvalidCertificate
+	}
+
+	// #nosec
+	bs := sha1.Sum(block.Bytes)
+
+	return fmt.Sprintf("%x", bs), nil
+}
+
+const (
+	fingerprintSeparator = "#"
+	fingerprintLength    = sha1.Size * 2
+)
+
+// PinnedMeetingID returns the given meeting ID together with the certificate fingerprint
+func PinnedMeetingID(meetingID, fingerprint string) string {
+	if len(fingerprint) == 0 {
+		return meetingID
+	}
+	return meetingID + fingerprintSeparator + fingerprint
+}
+
+// SplitPinnedMeetingID separates the certificate fingerprint from a meeting ID.
+// An empty fingerprint is returned for meeting IDs without one
+func SplitPinnedMeetingID(id string) (meetingID string, fingerprint string, err error) {
+	idx := strings.LastIndex(id, fingerprintSeparator)
+	if idx == -1 {
+		return id, "", nil
+	}
+
+	meetingID = id[:idx]
+	fingerprint = strings.ToLower(id[idx+1:])
+
+	if !isValidFingerprint(fingerprint) {
+		return "", "", ErrInvalidFingerprint
+	}
+
+	return meetingID, fingerprint, nil
+}
+
+func isValidFingerprint(fingerprint string) bool {
+	if len(fingerprint) != fingerprintLength {
+		return false
+	}
+
+	_, err := hex.DecodeString(fingerprint)
+	return err == nil
+}
@@ -0,0 +1,145 @@
+package hosting
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+
+	// #nosec
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/digitalautonomy/wahay/config"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+func generateTestCertificate(c *C) ([]byte, []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Wahay Test Certificate"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	c.Assert(err, IsNil)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), der
+}
+
+func (s *hostingSuite) Test_CertificateFingerprint_returnsTheSHA1DigestOfTheCertificate(c *C) {
+	cert, der := generateTestCertificate(c)
+
+	fingerprint, err := CertificateFingerprint(cert)
+
+	c.Assert(err, IsNil)
+	// #nosec
+	c.Assert(fingerprint, Equals, fmt.Sprintf("%x", sha1.Sum(der)))
+}
+
+func (s *hostingSuite) Test_CertificateFingerprint_returnsAnErrorWhenTheCertificateIsInvalid(c *C) {
+	fingerprint, err := CertificateFingerprint([]byte("dummy cert"))
+
+	c.Assert(err, Equals, errInvalidCertificate)
+	c.Assert(fingerprint, Equals, "")
+}
+
+func (s *hostingSuite) Test_CheckCompatibility_returnsAnErrorWhenTheVersionIsDifferent(c *C) {
+	m := &MeetingMetadata{Version: MeetingMetadataVersion + 1}
+	c.Assert(m.CheckCompatibility(), Equals, ErrIncompatibleMeetingVersion)
+
+	m = &MeetingMetadata{Version: MeetingMetadataVersion}
+	c.Assert(m.CheckCompatibility(), IsNil)
+}
+
+func (s *hostingSuite) Test_HasFeature_returnsTrueOnlyForAnnouncedFeatures(c *C) {
+	m := &MeetingMetadata{Features: []string{FeatureConnectionCheck}}
+
+	c.Assert(m.HasFeature(FeatureConnectionCheck), Equals, true)
+	c.Assert(m.HasFeature("something-else"), Equals, false)
+}
+
+func (s *hostingSuite) Test_handleMeetingMetadataRequest_servesTheMeetingInformationAsJSON(c *C) {
+	cert, _ := generateTestCertificate(c)
+	fingerprint, _ := CertificateFingerprint(cert)
+
+	ws := &webserver{cert: cert, fingerprint: fingerprint}
+	ws.setMeetingInformation("Weekly meeting", "Welcome!", true)
+
+	req := httptest.NewRequest(http.MethodGet, MeetingMetadataPath+"?version=1", nil)
+	rec := httptest.NewRecorder()
+	ws.handleMeetingMetadataRequest(rec, req)
+
+	c.Assert(rec.Code, Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), Equals, "application/json")
+
+	m := &MeetingMetadata{}
+	err := json.Unmarshal(rec.Body.Bytes(), m)
+	c.Assert(err, IsNil)
+	c.Assert(m, DeepEquals, &MeetingMetadata{
+		Version:          MeetingMetadataVersion,
+		WahayVersion:     config.ApplicationVersion(),
+		Certificate:      string(cert),
+		Fingerprint:      fingerprint,
+		Title:            "Weekly meeting",
+		WelcomeText:      "Welcome!",
+		PasswordRequired: true,
+		Features:         []string{FeatureConnectionCheck},
+	})
+}
+
+func (s *hostingSuite) Test_handleMeetingMetadataRequest_refusesUnsupportedVersions(c *C) {
+	log.SetOutput(io.Discard)
+
+	ws := &webserver{}
+
+	req := httptest.NewRequest(http.MethodGet, MeetingMetadataPath+"?version=2", nil)
+	rec := httptest.NewRecorder()
+	ws.handleMeetingMetadataRequest(rec, req)
+
+	c.Assert(rec.Code, Equals, http.StatusNotAcceptable)
+}
+
+func (s *hostingSuite) Test_PinnedMeetingID_appendsTheFingerprintOnlyWhenItIsAvailable(c *C) {
+	fingerprint := "0123456789abcdef0123456789abcdef01234567"
+
+	c.Assert(PinnedMeetingID("test.onion:1234", fingerprint), Equals, "test.onion:1234#"+fingerprint)
+	c.Assert(PinnedMeetingID("test.onion", ""), Equals, "test.onion")
+}
+
+func (s *hostingSuite) Test_SplitPinnedMeetingID_separatesTheFingerprintFromTheMeetingID(c *C) {
+	id, fingerprint, err := SplitPinnedMeetingID("test.onion:1234#0123456789ABCDEF0123456789abcdef01234567")
+
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "test.onion:1234")
+	c.Assert(fingerprint, Equals, "0123456789abcdef0123456789abcdef01234567")
+}
+
+func (s *hostingSuite) Test_SplitPinnedMeetingID_returnsTheSameMeetingIDWhenThereIsNoFingerprint(c *C) {
+	id, fingerprint, err := SplitPinnedMeetingID("test.onion")
+
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "test.onion")
+	c.Assert(fingerprint, Equals, "")
+}
+
+func (s *hostingSuite) Test_SplitPinnedMeetingID_returnsAnErrorWhenTheFingerprintIsInvalid(c *C) {
+	_, _, err := SplitPinnedMeetingID("test.onion#abc")
+	c.Assert(err, Equals, ErrInvalidFingerprint)
+
+	_, _, err = SplitPinnedMeetingID("test.onion#zz23456789abcdef0123456789abcdef01234567")
+	c.Assert(err, Equals, ErrInvalidFingerprint)
+}
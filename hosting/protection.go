@@ -0,0 +1,90 @@
+package hosting
+
+import (
+	"sync"
+	"time"
+
+	"github.com/digitalautonomy/wahay/tor"
+)
+
+// Protection contains the settings used to protect
+// hosted meetings against connection floods
+type Protection struct {
+	// Onion contains the protections applied by Tor to the onion service
+	Onion tor.OnionOptions
+	// RequestsPerSecond and RequestsBurst limit the requests accepted through
+	// each connection to the certificate and the check connection servers.
+	// A RequestsPerSecond of zero disables the limit
+	RequestsPerSecond float64
+	RequestsBurst     int
+}
+
+// DefaultProtection returns the protection used by hosted meetings, unless
+// it's changed. A participant needs a few streams to get the meeting
+// information and connect to the Mumble server, so a circuit opening
+// many more is considered abusive
+func DefaultProtection() Protection {
+	return Protection{
+		Onion: tor.OnionOptions{
+			MaxStreams:             20,
+			MaxStreamsCloseCircuit: true,
+			ProofOfWork:            true,
+		},
+		RequestsPerSecond: 2,
+		RequestsBurst:     10,
+	}
+}
+
+// rateLimiter is a token bucket allowing a number of requests
+// per second, with bursts of up to the given size
+type rateLimiter struct {
+	sync.Mutex
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+}
+
+var timeNow = time.Now
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		last:      timeNow(),
+	}
+}
+
+// allow returns a boolean indicating if one more request can be handled now
+func (l *rateLimiter) allow() bool {
+	l.Lock()
+	defer l.Unlock()
+
+	now := timeNow()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+// newConnectionLimiter returns the rate limiter for a new connection
+// to the meeting servers, or nil when the requests are not limited
+func (p Protection) newConnectionLimiter() *rateLimiter {
+	if p.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return newRateLimiter(p.RequestsPerSecond, p.RequestsBurst)
+}
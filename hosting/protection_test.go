@@ -0,0 +1,76 @@
+package hosting
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/prashantv/gostub"
+	. "gopkg.in/check.v1"
+)
+
+func (s *hostingSuite) Test_rateLimiter_allowsBurstsAndRefillsOverTime(c *C) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer gostub.Stub(&timeNow, func() time.Time { return now }).Reset()
+
+	l := newRateLimiter(2, 3)
+
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, false)
+
+	now = now.Add(500 * time.Millisecond)
+
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, false)
+
+	now = now.Add(time.Hour)
+
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, true)
+	c.Assert(l.allow(), Equals, false)
+}
+
+func (s *hostingSuite) Test_Protection_newConnectionLimiter_returnsNothingWhenRequestsAreNotLimited(c *C) {
+	c.Assert(Protection{}.newConnectionLimiter(), IsNil)
+	c.Assert(DefaultProtection().newConnectionLimiter(), NotNil)
+}
+
+func (s *hostingSuite) Test_webserver_limitRequests_refusesTooManyRequestsThroughTheSameConnection(c *C) {
+	h := &webserver{server: &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}}
+
+	h.limitRequests(Protection{RequestsPerSecond: 0.001, RequestsBurst: 2})
+
+	ctx := h.server.ConnContext(context.Background(), nil)
+	request := func() int {
+		w := httptest.NewRecorder()
+		h.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+		return w.Code
+	}
+
+	c.Assert(request(), Equals, http.StatusOK)
+	c.Assert(request(), Equals, http.StatusOK)
+	c.Assert(request(), Equals, http.StatusTooManyRequests)
+
+	ctx = h.server.ConnContext(context.Background(), nil)
+
+	c.Assert(request(), Equals, http.StatusOK)
+}
+
+func (s *hostingSuite) Test_servers_usesTheDefaultProtection(c *C) {
+	sv := &servers{protection: DefaultProtection()}
+
+	c.Assert(sv.Protection().Onion.MaxStreams, Equals, 20)
+	c.Assert(sv.Protection().Onion.ProofOfWork, Equals, true)
+
+	sv.SetProtection(Protection{})
+
+	c.Assert(sv.Protection(), DeepEquals, Protection{})
+}
@@ -2,7 +2,6 @@ package hosting
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/digitalautonomy/grumble/pkg/logtarget"
 	grumbleServer "github.com/digitalautonomy/grumble/server"
+	"github.com/digitalautonomy/wahay/config"
 	"github.com/digitalautonomy/wahay/tor"
 )
 
@@ -21,31 +21,57 @@ type Servers interface {
 	DataDir() string
 	Cleanup()
 	NewService(port string, t tor.Instance) (Service, error)
+	Protection() Protection
+	SetProtection(Protection)
 }
 
 // MeetingData is a representation of the data used to create a Mumble url
 // More information at https://wiki.mumble.info/wiki/Mumble_URL
 type MeetingData struct {
-	MeetingID string
-	Port      int
-	Password  string
-	Username  string
-	IsHost    bool
+	MeetingID   string
+	Port        int
+	Password    string
+	Username    string
+	Fingerprint string
+	IsHost      bool
+	// ServerSocket is the Unix domain socket the meeting server listens on,
+	// which the host connects to without going through Tor, if any
+	ServerSocket string
+	// Invitation is the invitation used to join the meeting, if any
+	Invitation *Invitation
+}
+
+// UsesForwarder returns a boolean indicating if Mumble connects to the meeting
+// through the forwarder, since it can only connect to TCP ports. Only the host
+// connects directly, when the meeting server listens on a TCP port
+func (d MeetingData) UsesForwarder() bool {
+	return !d.IsHost || d.ServerSocket != ""
 }
 
 func create() (Servers, error) {
-	s := &servers{}
+	s := &servers{protection: DefaultProtection()}
 	e := s.create()
 
 	return s, e
 }
 
 type servers struct {
-	dataDir string
-	started bool
-	nextID  int
-	servers map[int64]*grumbleServer.Server
-	log     *log.Logger
+	dataDir    string
+	started    bool
+	nextID     int
+	servers    map[int64]*grumbleServer.Server
+	log        *log.Logger
+	protection Protection
+}
+
+// Protection returns the protection used by the meetings hosted from now on
+func (s *servers) Protection() Protection {
+	return s.protection
+}
+
+// SetProtection changes the protection used by the meetings hosted from now on
+func (s *servers) SetProtection(p Protection) {
+	s.protection = p
 }
 
 func (s *servers) initializeSharedObjects() {
@@ -53,7 +79,7 @@ func (s *servers) initializeSharedObjects() {
 	grumbleServer.SetServers(s.servers)
 }
 
-var ioutilTempDir = ioutil.TempDir
+var ioutilTempDir = config.TempDir
 var osMkdirAll = os.MkdirAll
 
 func (s *servers) initializeDataDirectory() error {
@@ -159,6 +185,14 @@ func setPort(port string) serverModifier {
 	}
 }
 
+func setUnixSocket(path string) serverModifier {
+	return func(serv *grumbleServer.Server) {
+		if len(path) != 0 {
+			serv.Set("UnixSocket", path)
+		}
+	}
+}
+
 func setPassword(password string) serverModifier {
 	return func(serv *grumbleServer.Server) {
 		if len(password) != 0 {
@@ -307,6 +307,33 @@ func (s *hostingSuite) Test_CreateServer_sendSeveralServerModifiersReturnsAServe
 	c.Assert(reflect.TypeOf(serv), DeepEquals, reflect.TypeOf(&server{}))
 }
 
+func (s *hostingSuite) Test_CreateServer_setUnixSocketMakesTheServerListenOnIt(c *C) {
+	path := c.MkDir()
+	c.Assert(os.MkdirAll(filepath.Join(path, "servers"), 0700), IsNil)
+
+	servers := &servers{
+		nextID:  1,
+		servers: make(map[int64]*grumbleServer.Server),
+		dataDir: path,
+	}
+
+	serv, err := servers.CreateServer(setDefaultOptions, setUnixSocket(""))
+	c.Assert(err, IsNil)
+	c.Assert(serv.(*server).gs.UnixSocket(), Equals, "")
+
+	socket := filepath.Join(path, meetingServerSocketName)
+	serv, err = servers.CreateServer(setDefaultOptions, setUnixSocket(socket))
+	c.Assert(err, IsNil)
+	c.Assert(serv.(*server).gs.UnixSocket(), Equals, socket)
+}
+
+func (s *hostingSuite) Test_MeetingData_UsesForwarder_onlyForTheHostWhenTheServerListensOnAUnixSocket(c *C) {
+	c.Assert(MeetingData{}.UsesForwarder(), Equals, true)
+	c.Assert(MeetingData{ServerSocket: "/tmp/wahay1/mumble.sock"}.UsesForwarder(), Equals, true)
+	c.Assert(MeetingData{IsHost: true}.UsesForwarder(), Equals, false)
+	c.Assert(MeetingData{IsHost: true, ServerSocket: "/tmp/wahay1/mumble.sock"}.UsesForwarder(), Equals, true)
+}
+
 func (s *hostingSuite) Test_CreateServer_returnsAnErrorWhenServersDirectoryHasNotBeenCreated(c *C) {
 	servers := &servers{
 		nextID:  1,
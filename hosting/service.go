@@ -4,7 +4,9 @@ import (
 	"errors"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -20,6 +22,8 @@ func CreateServerCollection() (Servers, error) {
 const (
 	// DefaultPort is a representation of the default port Mumble server
 	DefaultPort = 64738
+
+	meetingServerSocketName = "mumble.sock"
 )
 
 var stat = os.Stat
@@ -61,17 +65,26 @@ type SuperUserData struct {
 type Service interface {
 	ID() string
 	URL() string
+	PinnedURL() string
+	Fingerprint() string
 	Port() int
 	ServicePort() int
+	ServerSocket() string
+	Title() string
+	SetTitle(string)
 	SetWelcomeText(string)
+	Invitation(includePassword bool) (*Invitation, error)
 	NewConferenceRoom(password string, u SuperUserData) error
 	Close() error
 }
 
 type service struct {
 	port        int
+	socket      string
 	mumblePort  int
+	title       string
 	welcomeText string
+	password    string
 	onion       tor.Onion
 	room        *conferenceRoom
 	httpServer  *webserver
@@ -90,6 +103,19 @@ func (s *service) URL() string {
 	return s.ID()
 }
 
+// PinnedURL returns the meeting URL together with the fingerprint of the
+// certificate, so participants can verify they are joining the right server
+func (s *service) PinnedURL() string {
+	return PinnedMeetingID(s.URL(), s.Fingerprint())
+}
+
+func (s *service) Fingerprint() string {
+	if s.httpServer == nil {
+		return ""
+	}
+	return s.httpServer.fingerprint
+}
+
 func (s *service) Port() int {
 	return s.port
 }
@@ -98,10 +124,53 @@ func (s *service) ServicePort() int {
 	return s.mumblePort
 }
 
+// ServerSocket returns the Unix domain socket the meeting server listens
+// on, or an empty string when it listens on the port returned by Port
+func (s *service) ServerSocket() string {
+	return s.socket
+}
+
+func (s *service) Title() string {
+	return s.title
+}
+
+func (s *service) SetTitle(t string) {
+	s.title = t
+}
+
 func (s *service) SetWelcomeText(t string) {
 	s.welcomeText = t
 }
 
+// Invitation returns a signed invitation to the meeting. The password
+// of the meeting is only included when explicitly requested
+func (s *service) Invitation(includePassword bool) (*Invitation, error) {
+	i := &Invitation{
+		Version:     InvitationVersion,
+		Onion:       s.ID(),
+		Port:        s.ServicePort(),
+		Fingerprint: s.Fingerprint(),
+		Expires:     time.Now().Add(DefaultInvitationValidity).Truncate(time.Second),
+		Title:       s.title,
+	}
+
+	if includePassword {
+		i.Password = s.password
+	}
+
+	if s.httpServer == nil || s.httpServer.key == nil {
+		log.Warn("Invitation(): the invitation can't be signed")
+		return i, nil
+	}
+
+	err := i.Sign(s.httpServer.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
 type conferenceRoom struct {
 	server Server
 }
@@ -111,6 +180,7 @@ func (s *service) NewConferenceRoom(password string, u SuperUserData) error {
 		setDefaultOptions,
 		setWelcomeText(s.welcomeText),
 		setPort(strconv.Itoa(s.port)),
+		setUnixSocket(s.socket),
 		setPassword(password),
 		setSuperUser(u.Username, u.Password),
 	)
@@ -126,8 +196,10 @@ func (s *service) NewConferenceRoom(password string, u SuperUserData) error {
 	s.room = &conferenceRoom{
 		server: serv,
 	}
+	s.password = password
 
 	// Start our certification http server
+	s.httpServer.setMeetingInformation(s.title, s.welcomeText, len(password) != 0)
 	s.httpServer.start(func(err error) {
 		// TODO: We must inform the user about this error in a proper way
 		log.Fatalf("Mumble certificate HTTP server: %v", err)
@@ -145,52 +217,61 @@ func (r *conferenceRoom) close() error {
 // NewService creates a new hosting service
 func (s *servers) NewService(port string, t tor.Instance) (Service, error) {
 	var onionPorts []tor.OnionPort
+	var err error
+
+	p := DefaultPort
+	if port != "" {
+		p, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, errInvalidPort
+		}
+	}
 
 	httpServer, err := newCertificateServer(s.DataDir())
 	if err != nil {
 		return nil, err
 	}
 
-	onionPorts = append(onionPorts, tor.OnionPort{
-		DestinationHost: defaultHost(),
-		DestinationPort: httpServer.port,
-		ServicePort:     certServerPort,
-	})
+	// The services are only reachable through our own Tor instance when
+	// it supports Unix domain sockets, so other users can't connect to them
+	checkSocket := ""
+	serverSocket := ""
+	if t.UsesUnixSockets() {
+		httpServer.useUnixSocket(filepath.Join(s.DataDir(), certServerSocketName))
+		checkSocket = filepath.Join(s.DataDir(), checkConnectionSocketName)
+		serverSocket = filepath.Join(s.DataDir(), meetingServerSocketName)
+	}
 
-	checkService, err := newCheckConnectionService()
+	httpServer.limitRequests(s.protection)
+
+	onionPorts = append(onionPorts, httpServer.onionPort())
+
+	checkService, err := newCheckConnectionService(checkSocket)
 	if err != nil {
 		return nil, err
 	}
 
-	onionPorts = append(onionPorts, tor.OnionPort{
-		DestinationHost: defaultHost(),
-		DestinationPort: checkService.port,
-		ServicePort:     checkConnectionPort,
-	})
+	checkService.protection = s.protection
 
-	p := DefaultPort
-	if port != "" {
-		p, err = strconv.Atoi(port)
-		if err != nil {
-			return nil, errInvalidPort
-		}
-	}
+	onionPorts = append(onionPorts, checkService.onionPort())
 
 	serverPort := config.GetRandomPort()
 
 	onionPorts = append(onionPorts, tor.OnionPort{
-		DestinationHost: defaultHost(),
-		DestinationPort: serverPort,
-		ServicePort:     p,
+		DestinationHost:   defaultHost(),
+		DestinationPort:   serverPort,
+		DestinationSocket: serverSocket,
+		ServicePort:       p,
 	})
 
-	onion, err := t.NewOnionServiceWithMultiplePorts(onionPorts)
+	onion, err := t.NewOnionServiceWithMultiplePorts(onionPorts, s.protection.Onion)
 	if err != nil {
 		return nil, err
 	}
 
 	ss := &service{
 		port:        serverPort,
+		socket:      serverSocket,
 		mumblePort:  p,
 		onion:       onion,
 		httpServer:  httpServer,
@@ -35,10 +35,19 @@ func main() {
 	}
 
 	initLogging()
+	initApplicationVersion()
 
 	runClient()
 }
 
+func initApplicationVersion() {
+	if BuildTag != "(no tag)" && BuildTag != "" {
+		config.SetApplicationVersion(BuildTag)
+		return
+	}
+	config.SetApplicationVersion(BuildShortCommit)
+}
+
 func initLogging() {
 	log.SetLevel(log.InfoLevel)
 	if *config.Debug {
@@ -51,6 +60,7 @@ func initLogging() {
 }
 
 func runClient() {
-	g := gui.CreateGraphics(gtka.Real, gliba.Real, gdka.Real)
+	g := gui.CreateGraphics(gtka.Real, gliba.Real, gdka.Real).
+		WithApplicationOpen(connectApplicationOpen)
 	gui.NewGTK(g).Loop()
 }
@@ -0,0 +1,13 @@
+package qr
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type QRSuite struct{}
+
+var _ = Suite(&QRSuite{})
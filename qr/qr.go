@@ -0,0 +1,74 @@
+// Package qr renders meeting invitations as QR codes and reads them
+// back from images, so participants don't need to type onion addresses
+package qr
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+
+	// Register the decoders for the image formats participants
+	// are most likely to use when sharing a QR code
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqr "github.com/makiuchi-d/gozxing/qrcode"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// DefaultSize is the size in pixels of the generated QR code images
+const DefaultSize = 256
+
+var (
+	// ErrNoQRCode is an error to return when the image doesn't contain a QR code
+	ErrNoQRCode = errors.New("the image doesn't contain a QR code")
+	// ErrInvalidImage is an error to return when the file is not a supported image
+	ErrInvalidImage = errors.New("the file is not a supported image")
+)
+
+// Encode returns a PNG image of the given size containing the content as a QR code.
+// Medium error recovery is used, which is enough for codes shown on a screen
+// and keeps long invitations readable
+func Encode(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// Decode returns the content of the QR code contained in the given image
+func Decode(img image.Image) (string, error) {
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+	}
+
+	result, err := zxingqr.NewQRCodeReader().Decode(bmp, hints)
+	if err != nil {
+		return "", ErrNoQRCode
+	}
+
+	return result.GetText(), nil
+}
+
+var osOpen = os.Open
+
+// DecodeFile returns the content of the QR code contained in the given image file
+func DecodeFile(name string) (string, error) {
+	f, err := osOpen(filepath.Clean(name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+
+	return Decode(img)
+}
@@ -0,0 +1,73 @@
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+const testInvitationURI = "wahay://join?fingerprint=0123456789abcdef0123456789abcdef01234567" +
+	"&onion=ynbtnwqwvhsnp2ok6mjecsbgkjvvgfe7afhbk5tvozjlmdrpqezyhxid.onion&port=64738&v=1"
+
+func (s *QRSuite) Test_Encode_returnsAPNGImageOfTheGivenSize(c *C) {
+	content, err := Encode(testInvitationURI, DefaultSize)
+	c.Assert(err, IsNil)
+
+	img, err := png.Decode(bytes.NewReader(content))
+	c.Assert(err, IsNil)
+	c.Assert(img.Bounds().Dx(), Equals, DefaultSize)
+	c.Assert(img.Bounds().Dy(), Equals, DefaultSize)
+}
+
+func (s *QRSuite) Test_Decode_readsTheContentOfAnEncodedQRCode(c *C) {
+	content, err := Encode(testInvitationURI, DefaultSize)
+	c.Assert(err, IsNil)
+
+	img, err := png.Decode(bytes.NewReader(content))
+	c.Assert(err, IsNil)
+
+	text, err := Decode(img)
+
+	c.Assert(err, IsNil)
+	c.Assert(text, Equals, testInvitationURI)
+}
+
+func (s *QRSuite) Test_Decode_returnsAnErrorWhenTheImageDoesntContainAQRCode(c *C) {
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 100; y++ {
+			img.SetGray(x, y, color.Gray{Y: 0xff})
+		}
+	}
+
+	_, err := Decode(img)
+
+	c.Assert(err, Equals, ErrNoQRCode)
+}
+
+func (s *QRSuite) Test_DecodeFile_readsTheContentOfAQRCodeImageFile(c *C) {
+	content, err := Encode(testInvitationURI, DefaultSize)
+	c.Assert(err, IsNil)
+
+	name := filepath.Join(c.MkDir(), "invitation.png")
+	c.Assert(os.WriteFile(name, content, 0600), IsNil)
+
+	text, err := DecodeFile(name)
+
+	c.Assert(err, IsNil)
+	c.Assert(text, Equals, testInvitationURI)
+}
+
+func (s *QRSuite) Test_DecodeFile_returnsAnErrorForFilesThatAreNotImages(c *C) {
+	name := filepath.Join(c.MkDir(), "invitation.wahay")
+	c.Assert(os.WriteFile(name, []byte("{}"), 0600), IsNil)
+
+	_, err := DecodeFile(name)
+
+	c.Assert(err, Equals, ErrInvalidImage)
+}
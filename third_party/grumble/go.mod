@@ -0,0 +1,11 @@
+module github.com/digitalautonomy/grumble
+
+go 1.19
+
+require (
+	github.com/golang/protobuf v1.3.2
+	github.com/gorilla/websocket v1.4.1
+	golang.org/x/crypto v0.0.0-20191206172530-e9b2fee46413
+)
+
+require golang.org/x/sys v0.0.0-20191210023423-ac6580df4449 // indirect
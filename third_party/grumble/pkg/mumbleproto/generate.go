@@ -0,0 +1,3 @@
+//go:generate go run generate_main.go
+
+package mumbleproto
@@ -0,0 +1,6 @@
+// Copyright (c) 2012 The Grumble Authors
+// The use of this source code is goverened by a BSD-style
+// license that can be found in the LICENSE-file.
+
+// Package replacefile implements access to the ReplaceFile Win32 API.
+package replacefile
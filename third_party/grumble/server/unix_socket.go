@@ -0,0 +1,55 @@
+// Copyright (c) 2026 The Grumble Authors
+// The use of this source code is goverened by a BSD-style
+// license that can be found in the LICENSE-file.
+
+package server
+
+import (
+	"net"
+	"os"
+)
+
+// The clients connected through a Unix domain socket have no address
+// of their own, so they are considered to come from the loopback address,
+// like the ones that connect through a proxy running in the same host.
+var unixSocketClientAddr = &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+type unixSocketConn struct {
+	net.Conn
+}
+
+func (c *unixSocketConn) RemoteAddr() net.Addr {
+	return unixSocketClientAddr
+}
+
+type unixSocketListener struct {
+	net.Listener
+}
+
+func (l *unixSocketListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &unixSocketConn{conn}, nil
+}
+
+// listenUnix listens on the Unix domain socket at the given path, which
+// is only accessible by the user running the server. A socket left by
+// a previous execution of the server is replaced.
+func listenUnix(path string) (net.Listener, error) {
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	return &unixSocketListener{l}, nil
+}
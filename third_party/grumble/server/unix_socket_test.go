@@ -0,0 +1,54 @@
+// Copyright (c) 2026 The Grumble Authors
+// The use of this source code is goverened by a BSD-style
+// license that can be found in the LICENSE-file.
+
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mumble.sock")
+
+	// A socket left by a previous execution
+	err := os.WriteFile(path, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := listenUnix(path)
+	if err != nil {
+		t.Fatalf("Expected the socket to be replaced: %v", err)
+	}
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the socket to be only accessible by its owner, got %v", info.Mode().Perm())
+	}
+
+	go func() {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok || !addr.IP.IsLoopback() {
+		t.Errorf("Expected the client to come from the loopback address, got %v", conn.RemoteAddr())
+	}
+}
@@ -0,0 +1,6 @@
+package server
+
+var (
+	version   = "1.0~devel"
+	buildDate = "unknown"
+)
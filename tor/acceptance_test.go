@@ -3,9 +3,11 @@ package tor
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 
 	"github.com/digitalautonomy/wahay/config"
@@ -85,7 +87,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillBeUsed_whenSystemTorIsOKWithN
 
 	mockhttpf.checkConnectionReturn = true
 
-	ix, e := NewInstance(&config.ApplicationConfig{}, nil)
+	ix, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, IsNil)
 
@@ -97,8 +99,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillBeUsed_whenSystemTorIsOKWithN
 
 	c.Assert(tc.getVersionCalled, Equals, 1)
 
-	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1")
-	c.Assert(mockhttpf.checkConnectionArg2, Equals, 9050)
+	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1:9050")
 
 	i := ix.(*instance)
 	c.Assert(i.started, Equals, true)
@@ -132,7 +133,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorIsUsed_whenSystemTorIsOKWithCooki
 
 	mockhttpf.checkConnectionReturn = true
 
-	ix, e := NewInstance(&config.ApplicationConfig{}, nil)
+	ix, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, IsNil)
 
@@ -144,8 +145,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorIsUsed_whenSystemTorIsOKWithCooki
 
 	c.Assert(tc.getVersionCalled, Equals, 1)
 
-	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1")
-	c.Assert(mockhttpf.checkConnectionArg2, Equals, 9050)
+	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1:9050")
 
 	i := ix.(*instance)
 	c.Assert(i.started, Equals, true)
@@ -185,7 +185,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillBeUsed_whenSystemTorIsOKWithP
 
 	mockhttpf.checkConnectionReturn = true
 
-	ix, e := NewInstance(&config.ApplicationConfig{}, nil)
+	ix, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, IsNil)
 
@@ -198,8 +198,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillBeUsed_whenSystemTorIsOKWithP
 
 	c.Assert(tc.getVersionCalled, Equals, 1)
 
-	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1")
-	c.Assert(mockhttpf.checkConnectionArg2, Equals, 9050)
+	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1:9050")
 
 	i := ix.(*instance)
 	c.Assert(i.started, Equals, true)
@@ -239,7 +238,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillNotBeUsed_whenItsNotConnected
 
 	mockhttpf.checkConnectionReturn = false
 
-	_, e := NewInstance(&config.ApplicationConfig{}, nil)
+	_, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, ErrorMatches, "no Tor binary found")
 }
@@ -265,7 +264,7 @@ func (s *TorAcceptanceSuite) Test_thatSystemTorWillNotBeUsed_whenTheVersionIsToo
 
 	mockhttpf.checkConnectionReturn = true
 
-	_, e := NewInstance(&config.ApplicationConfig{}, nil)
+	_, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, ErrorMatches, "no Tor binary found")
 }
@@ -282,7 +281,7 @@ func (s *TorAcceptanceSuite) Test_thatThingsWillFailIfTheresNoSystemTor(c *C) {
 
 	mocktorgof.newControllerReturn2 = errors.New("no connection possible")
 
-	_, e := NewInstance(&config.ApplicationConfig{}, nil)
+	_, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, ErrorMatches, "no Tor binary found")
 }
@@ -318,7 +317,7 @@ func (s *TorAcceptanceSuite) Test_thatThingsWillFailIfTheresASystemTorWithOldVer
 		return nil, nil
 	}
 
-	_, e := NewInstance(&config.ApplicationConfig{}, nil)
+	_, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, ErrorMatches, "no Tor binary found")
 	c.Assert(called, Equals, true)
@@ -359,10 +358,12 @@ func (s *TorAcceptanceSuite) Test_thatASystemTorBinaryWillBeStartedIfProperVersi
 		return nil
 	}
 
-	ix, e := NewInstance(&config.ApplicationConfig{}, nil)
+	ix, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	verifyAllAssertions(c, e, tc, ix.(*instance))
 
+	// The instance is destroyed before Tor exits, so it isn't restarted
+	ix.(*instance).destroyed = true
 	finishWaiting <- true
 }
 
@@ -409,8 +410,7 @@ func verifyAllAssertions(c *C, e error, tc *mockTorgoController, i *instance) {
 
 	c.Assert(tc.getVersionCalled, Equals, 1)
 
-	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1")
-	c.Assert(mockhttpf.checkConnectionArg2, Equals, 4666)
+	c.Assert(mockhttpf.checkConnectionArg1, Equals, "127.0.0.1:4666")
 
 	c.Assert(i.started, Equals, true)
 	c.Assert(i.socksPort, Equals, 4666)
@@ -479,13 +479,17 @@ func mockAll() {
 }
 
 type mockOsImplementation struct {
-	onIsPortAvailable func(int) bool
-	onGetRandomPort   func() int
+	onIsPortAvailable   func(int) bool
+	onIsProcessRunning  func(int) bool
+	onGetRandomPort     func() int
+	onShortPathName     func(string) (string, error)
+	supportsUnixSockets bool
+	env                 map[string]string
 }
 
-func (*mockOsImplementation) Getenv(key string) string {
+func (m *mockOsImplementation) Getenv(key string) string {
 	testPrint("Getenv(%s)\n", key)
-	return ""
+	return m.env[key]
 }
 
 func (*mockOsImplementation) Getwd() (string, error) {
@@ -518,6 +522,11 @@ func (*mockOsImplementation) Stdout() *os.File {
 	return nil
 }
 
+func (*mockOsImplementation) Getpid() int {
+	testPrint("Getpid()\n")
+	return 4242
+}
+
 func (*mockOsImplementation) Stderr() *os.File {
 	testPrint("Stderr()\n")
 	return nil
@@ -539,6 +548,27 @@ func (m *mockOsImplementation) GetRandomPort() int {
 	return 0
 }
 
+func (m *mockOsImplementation) IsProcessRunning(pid int) bool {
+	testPrint("IsProcessRunning(%v)\n", pid)
+	if m.onIsProcessRunning != nil {
+		return m.onIsProcessRunning(pid)
+	}
+	return false
+}
+
+func (m *mockOsImplementation) SupportsUnixSockets() bool {
+	testPrint("SupportsUnixSockets()\n")
+	return m.supportsUnixSockets
+}
+
+func (m *mockOsImplementation) ShortPathName(path string) (string, error) {
+	testPrint("ShortPathName(%s)\n", path)
+	if m.onShortPathName != nil {
+		return m.onShortPathName(path)
+	}
+	return path, nil
+}
+
 type mockFilepathImplementation struct {
 	joinReturn1 string
 }
@@ -592,13 +622,20 @@ func (m *mockExecImplementation) WaitCommand(cmd *exec.Cmd) error {
 }
 
 type mockFilesystemImplementation struct {
-	onTempDir   func(string) string
-	onEnsureDir func(string, os.FileMode)
-	onWriteFile func(string, []byte, os.FileMode) error
+	onTempDir         func(string) string
+	onEnsureDir       func(string, os.FileMode)
+	onWriteFile       func(string, []byte, os.FileMode) error
+	onReadFile        func(string) ([]byte, error)
+	onFileExists      func(string) bool
+	onAcquireTorCache func() bool
+	torCacheReleased  int
 }
 
-func (*mockFilesystemImplementation) FileExists(path string) bool {
+func (m *mockFilesystemImplementation) FileExists(path string) bool {
 	testPrint("FileExists(%v)\n", path)
+	if m.onFileExists != nil {
+		return m.onFileExists(path)
+	}
 	return false
 }
 
@@ -630,15 +667,39 @@ func (m *mockFilesystemImplementation) WriteFile(name string, content []byte, mo
 	return nil
 }
 
+func (m *mockFilesystemImplementation) ReadFile(name string) ([]byte, error) {
+	testPrint("ReadFile(%v)\n", name)
+	if m.onReadFile != nil {
+		return m.onReadFile(name)
+	}
+	return nil, os.ErrNotExist
+}
+
+func (m *mockFilesystemImplementation) AcquireTorCache() bool {
+	testPrint("AcquireTorCache()\n")
+	if m.onAcquireTorCache != nil {
+		return m.onAcquireTorCache()
+	}
+	return true
+}
+
+func (m *mockFilesystemImplementation) ReleaseTorCache() {
+	testPrint("ReleaseTorCache()\n")
+	m.torCacheReleased++
+}
+
 type mockTorgoController struct {
 	authNoneReturn, authPassReturn, authCookieReturn error
 	authNoneCalled, authPassCalled, authCookieCalled int
 
-	authPassArg string
+	authPassArg       string
+	authCookieFileArg string
 
 	getVersionReturn1 string
 	getVersionReturn2 error
 	getVersionCalled  int
+
+	deleteOnionArgs []string
 }
 
 func (m *mockTorgoController) AuthenticatePassword(v string) error {
@@ -654,6 +715,13 @@ func (m *mockTorgoController) AuthenticateCookie() error {
 	return m.authCookieReturn
 }
 
+func (m *mockTorgoController) AuthenticateCookieFile(path string) error {
+	testPrint("torgoController.AuthenticateCookieFile(%v)\n", path)
+	m.authCookieCalled++
+	m.authCookieFileArg = path
+	return m.authCookieReturn
+}
+
 func (m *mockTorgoController) AuthenticateNone() error {
 	testPrint("torgoController.AuthenticateNone()\n")
 	m.authNoneCalled++
@@ -665,6 +733,11 @@ func (m *mockTorgoController) AddOnion(o *torgo.Onion) error {
 	return nil
 }
 
+func (m *mockTorgoController) AddOnionWithArguments(o *torgo.Onion, args []string) error {
+	testPrint("torgoController.AddOnionWithArguments(%v, %v)\n", o, args)
+	return nil
+}
+
 func (m *mockTorgoController) GetVersion() (string, error) {
 	testPrint("torgoController.GetVersion()\n")
 	m.getVersionCalled++
@@ -673,6 +746,12 @@ func (m *mockTorgoController) GetVersion() (string, error) {
 
 func (m *mockTorgoController) DeleteOnion(v string) error {
 	testPrint("torgoController.DeleteOnion(%v)\n", v)
+	m.deleteOnionArgs = append(m.deleteOnionArgs, v)
+	return nil
+}
+
+func (m *mockTorgoController) Close() error {
+	testPrint("torgoController.Close()\n")
 	return nil
 }
 
@@ -681,7 +760,8 @@ type mockTorgoImplementation struct {
 	newControllerReturn1 torgoController
 	newControllerReturn2 error
 
-	onNewController func(a string) (torgoController, error)
+	onNewController      func(a string) (torgoController, error)
+	onNewEventController func(a string) (torgoEventController, error)
 }
 
 func (m *mockTorgoImplementation) NewController(a string) (torgoController, error) {
@@ -693,21 +773,83 @@ func (m *mockTorgoImplementation) NewController(a string) (torgoController, erro
 	return m.newControllerReturn1, m.newControllerReturn2
 }
 
+func (m *mockTorgoImplementation) NewEventController(a string) (torgoEventController, error) {
+	testPrint("NewEventController(%v)\n", a)
+	if m.onNewEventController != nil {
+		return m.onNewEventController(a)
+	}
+	return &mockTorgoEventController{
+		bootstrapPhase: "NOTICE BOOTSTRAP PROGRESS=100 TAG=done SUMMARY=\"Done\"",
+	}, nil
+}
+
+type mockTorgoEventController struct {
+	mockTorgoController
+
+	bootstrapPhase string
+	info           map[string]string
+	events         []string
+	setEventsCalls [][]string
+	closedCircuits []string
+	ownershipTaken bool
+	closed         bool
+}
+
+func (m *mockTorgoEventController) GetInfo(key string) (string, error) {
+	testPrint("torgoEventController.GetInfo(%v)\n", key)
+	if v, ok := m.info[key]; ok {
+		return v, nil
+	}
+	return m.bootstrapPhase, nil
+}
+
+func (m *mockTorgoEventController) CloseCircuit(id string) error {
+	testPrint("torgoEventController.CloseCircuit(%v)\n", id)
+	m.closedCircuits = append(m.closedCircuits, id)
+	return nil
+}
+
+func (m *mockTorgoEventController) SetEvents(events ...string) error {
+	testPrint("torgoEventController.SetEvents(%v)\n", events)
+	m.setEventsCalls = append(m.setEventsCalls, events)
+	return nil
+}
+
+func (m *mockTorgoEventController) TakeOwnership() error {
+	testPrint("torgoEventController.TakeOwnership()\n")
+	m.ownershipTaken = true
+	return nil
+}
+
+func (m *mockTorgoEventController) ReadEvent() (string, error) {
+	testPrint("torgoEventController.ReadEvent()\n")
+	if len(m.events) == 0 {
+		return "", io.EOF
+	}
+	ev := m.events[0]
+	m.events = m.events[1:]
+	return ev, nil
+}
+
+func (m *mockTorgoEventController) Close() error {
+	testPrint("torgoEventController.Close()\n")
+	m.closed = true
+	return nil
+}
+
 type mockHTTPImplementation struct {
 	checkConnectionArg1   string
-	checkConnectionArg2   int
 	checkConnectionReturn bool
 }
 
-func (m *mockHTTPImplementation) CheckConnectionOverTor(host string, port int) bool {
-	testPrint("CheckConnectionOverTor(%v, %v)\n", host, port)
-	m.checkConnectionArg1 = host
-	m.checkConnectionArg2 = port
+func (m *mockHTTPImplementation) CheckConnectionOverTor(proxyAddress string) bool {
+	testPrint("CheckConnectionOverTor(%v)\n", proxyAddress)
+	m.checkConnectionArg1 = proxyAddress
 	return m.checkConnectionReturn
 }
 
-func (m *mockHTTPImplementation) HTTPRequest(host string, port int, u string) (string, error) {
-	testPrint("HTTPRequest(%v, %v, %v)\n", host, port, u)
+func (m *mockHTTPImplementation) HTTPRequest(proxyAddress string, u string) (string, error) {
+	testPrint("HTTPRequest(%v, %v)\n", proxyAddress, u)
 	return "", nil
 }
 
@@ -726,7 +868,7 @@ func (s *TorAcceptanceSuite) Test_x(c *C) {
 
 	mockhttpf.checkConnectionReturn = true
 
-	ix, e := NewInstance(&config.ApplicationConfig{}, nil)
+	ix, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
 
 	c.Assert(e, IsNil)
 
@@ -735,3 +877,103 @@ func (s *TorAcceptanceSuite) Test_x(c *C) {
 	i := ix.(*instance)
 	c.Assert(i.started, Equals, true)
 }
+
+func (s *TorAcceptanceSuite) Test_thatTheConfiguredTorWillBeUsed_withTheConfiguredAuthenticationOnly(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+	log.SetOutput(ioutil.Discard)
+
+	tc := &mockTorgoController{}
+	tc.getVersionReturn1 = "0.4.8.9"
+
+	mocktorgof.newControllerReturn1 = tc
+	mockhttpf.checkConnectionReturn = true
+
+	conf := &config.ApplicationConfig{}
+	conf.SetTorEndpoint(&config.TorEndpoint{
+		Host:        "10.0.0.2",
+		ControlPort: 9151,
+		SocksPort:   9150,
+		AuthMethod:  config.TorAuthCookie,
+		CookieFile:  "/var/lib/tor-container/control_auth_cookie",
+	})
+
+	ix, e := NewInstance(conf, nil, nil)
+
+	c.Assert(e, IsNil)
+
+	c.Assert(mocktorgof.newControllerArg, Equals, "10.0.0.2:9151")
+	c.Assert(mockhttpf.checkConnectionArg1, Equals, "10.0.0.2:9150")
+
+	c.Assert(tc.authNoneCalled, Equals, 0)
+	c.Assert(tc.authPassCalled, Equals, 0)
+	c.Assert(tc.authCookieCalled, Equals, 2)
+	c.Assert(tc.authCookieFileArg, Equals, "/var/lib/tor-container/control_auth_cookie")
+
+	i := ix.(*instance)
+	c.Assert(i.useCookie, Equals, true)
+	c.Assert(i.isLocal, Equals, true)
+	c.Assert(i.UsesUnixSockets(), Equals, false)
+	c.Assert(i.SocksAddress(), Equals, "10.0.0.2:9150")
+	c.Assert(i.runningTor, IsNil)
+}
+
+func (s *TorAcceptanceSuite) Test_thatTheConfiguredTorWillNotBeReplaced_whenItCantBeUsed(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+	log.SetOutput(ioutil.Discard)
+
+	mocktorgof.newControllerReturn2 = errors.New("connection refused")
+
+	conf := &config.ApplicationConfig{}
+	conf.SetTorEndpoint(&config.TorEndpoint{
+		ControlSocket: "/run/tor/control",
+		SocksPort:     9050,
+	})
+
+	ix, e := NewInstance(conf, nil, nil)
+
+	c.Assert(ix, IsNil)
+	c.Assert(e, Equals, ErrPartialTorNoControlPort)
+	c.Assert(mocktorgof.newControllerArg, Equals, "unix:/run/tor/control")
+}
+
+func (s *TorAcceptanceSuite) Test_thatTheOnionServicesLeftBySystemTorAreRemoved_withoutTouchingTheOthers(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+	log.SetOutput(ioutil.Discard)
+
+	tc := &mockTorgoController{}
+	tc.getVersionReturn1 = "0.4.8.9"
+
+	mocktorgof.newControllerReturn1 = tc
+	mockhttpf.checkConnectionReturn = true
+
+	mockfilesystemf.onFileExists = func(path string) bool {
+		return filepath.Base(path) == onionStateFileName
+	}
+	mockfilesystemf.onReadFile = func(string) ([]byte, error) {
+		return []byte(`[` +
+			`{"control_address":"127.0.0.1:9051","pid":100,"started":1,"onions":["leftover.onion"]},` +
+			`{"control_address":"127.0.0.1:9051","pid":200,"started":2,"onions":["running.onion"]},` +
+			`{"control_address":"127.0.0.1:9151","pid":100,"started":1,"onions":["other.onion"]}` +
+			`]`), nil
+	}
+	mockosf.onIsProcessRunning = func(pid int) bool {
+		return pid == 200
+	}
+	saved := ""
+	mockfilesystemf.onWriteFile = func(path string, content []byte, mode os.FileMode) error {
+		saved = string(content)
+		return nil
+	}
+
+	_, e := NewInstance(&config.ApplicationConfig{}, nil, nil)
+
+	c.Assert(e, IsNil)
+	c.Assert(tc.deleteOnionArgs, DeepEquals, []string{"leftover"})
+	c.Assert(saved, Equals, `[`+
+		`{"control_address":"127.0.0.1:9051","pid":200,"started":2,"onions":["running.onion"]},`+
+		`{"control_address":"127.0.0.1:9151","pid":100,"started":1,"onions":["other.onion"]}`+
+		`]`)
+}
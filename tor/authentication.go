@@ -10,6 +10,14 @@ func authenticateCookie(tc torgoController) error {
 	return tc.AuthenticateCookie()
 }
 
+// authenticateCookieFile authenticates with the cookie at the given path,
+// instead of the one reported by Tor
+func authenticateCookieFile(path string) func(tc torgoController) error {
+	return func(tc torgoController) error {
+		return tc.AuthenticateCookieFile(path)
+	}
+}
+
 func authenticatePassword(password string) func(tc torgoController) error {
 	return func(tc torgoController) error {
 		return tc.AuthenticatePassword(password)
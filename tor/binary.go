@@ -282,6 +282,7 @@ func (b *binary) start(configFile string) (*runningTor, error) {
 		finished:          false,
 		finishedWithError: nil,
 		finishChannel:     make(chan bool, 100),
+		exited:            make(chan struct{}),
 	}
 
 	return state, nil
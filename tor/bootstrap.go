@@ -0,0 +1,265 @@
+package tor
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	bootstrapPhaseInfo     = "status/bootstrap-phase"
+	bootstrapStatusKeyword = "BOOTSTRAP"
+	bootstrapDoneProgress  = 100
+)
+
+// bootstrapRetryInterval is how often we try to connect to the control
+// port of our Tor instance while it is starting
+var bootstrapRetryInterval = 250 * time.Millisecond
+
+// BootstrapStatus is a representation of the progress reported by Tor
+// while it connects to the Tor network
+type BootstrapStatus struct {
+	Progress int
+	Tag      string
+	Summary  string
+	Warning  string
+}
+
+// Done returns a boolean indicating if Tor has finished connecting to the network
+func (s BootstrapStatus) Done() bool {
+	return s.Progress >= bootstrapDoneProgress
+}
+
+// BootstrapListener is a function that will be called every time
+// Tor reports progress while connecting to the Tor network
+type BootstrapListener func(BootstrapStatus)
+
+// parseBootstrapStatus parses the bootstrap status contained either in a
+// STATUS_CLIENT event or in the status/bootstrap-phase information, as
+// described in the section 4.1.10 of the Tor control protocol specification
+func parseBootstrapStatus(line string) (BootstrapStatus, bool) {
+	status := BootstrapStatus{}
+
+	fields := splitStatusArguments(line)
+
+	idx := -1
+	for n, f := range fields {
+		if f == bootstrapStatusKeyword {
+			idx = n
+			break
+		}
+	}
+
+	if idx == -1 {
+		return status, false
+	}
+
+	hasProgress := false
+	for _, f := range fields[idx+1:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "PROGRESS":
+			p, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return status, false
+			}
+			status.Progress = p
+			hasProgress = true
+		case "TAG":
+			status.Tag = kv[1]
+		case "SUMMARY":
+			status.Summary = kv[1]
+		case "WARNING":
+			status.Warning = kv[1]
+		}
+	}
+
+	return status, hasProgress
+}
+
+// splitStatusArguments splits a status line in its arguments,
+// taking into account that values can be quoted strings
+func splitStatusArguments(line string) []string {
+	result := []string{}
+
+	var current strings.Builder
+	quoted := false
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if current.Len() != 0 {
+				result = append(result, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() != 0 {
+		result = append(result, current.String())
+	}
+
+	return result
+}
+
+// waitForBootstrap waits until our Tor instance has connected to the Tor network,
+// reporting the progress to the given listener. Instead of polling, we subscribe
+// to the events Tor sends through the control port every time the progress changes.
+// The warnings sent by Tor meanwhile are used to explain why it couldn't connect.
+// The connection is kept open afterwards, since Tor exits when it's closed
+func (i *instance) waitForBootstrap(listener BootstrapListener, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	ec, err := i.connectEventController(deadline)
+	if err != nil {
+		return err
+	}
+
+	err = followBootstrap(ec, listener, newTorDiagnosis(len(i.bridges) > 0, i.proxy != nil), deadline)
+	if err == nil {
+		err = ec.SetEvents()
+	}
+
+	if err != nil {
+		_ = ec.Close()
+		return err
+	}
+
+	i.Lock()
+	i.ownerController = ec
+	i.Unlock()
+
+	return nil
+}
+
+func followBootstrap(ec torgoEventController, listener BootstrapListener, diagnosis *torDiagnosis, deadline time.Time) error {
+	notify := func(s BootstrapStatus) {
+		log.WithFields(log.Fields{
+			"progress": s.Progress,
+			"tag":      s.Tag,
+			"warning":  s.Warning,
+		}).Debug("Tor bootstrap status")
+
+		if listener != nil {
+			listener(s)
+		}
+	}
+
+	err := ec.SetEvents(statusClientEvent, statusGeneralEvent, warnEvent)
+	if err != nil {
+		return ErrTorInstanceCantStart
+	}
+
+	// We ask for the current phase after subscribing to the events,
+	// so no progress can be lost between both steps
+	phase, err := ec.GetInfo(bootstrapPhaseInfo)
+	if err != nil {
+		return ErrTorInstanceCantStart
+	}
+
+	if s, ok := parseBootstrapStatus(phase); ok {
+		notify(s)
+		if s.Done() {
+			return nil
+		}
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		for {
+			ev, err := ec.ReadEvent()
+			if err != nil {
+				result <- diagnosis.errorOr(ErrTorInstanceCantStart)
+				return
+			}
+
+			diagnosis.analyze(ev)
+
+			s, ok := parseBootstrapStatus(ev)
+			if !ok {
+				continue
+			}
+
+			notify(s)
+
+			if s.Done() {
+				result <- nil
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(time.Until(deadline)):
+		return diagnosis.errorOr(ErrTorConnectionTimeout)
+	}
+}
+
+// connectEventController connects to the control port of our Tor instance,
+// which won't be available until the Tor process has finished starting, and
+// takes the ownership of the Tor process through the new connection
+func (i *instance) connectEventController(deadline time.Time) (torgoEventController, error) {
+	where := i.controlAddress()
+
+	for {
+		if r := i.currentRunningTor(); r != nil && r.hasExited() {
+			return nil, ErrTorInstanceCantStart
+		}
+
+		ec, err := torgof.NewEventController(where)
+		if err == nil {
+			err = i.authenticate(ec)
+			if err == nil {
+				err = ec.TakeOwnership()
+			}
+
+			if err != nil {
+				_ = ec.Close()
+				return nil, ErrTorInstanceCantStart
+			}
+
+			return ec, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrTorConnectionTimeout
+		}
+
+		time.Sleep(bootstrapRetryInterval)
+	}
+}
+
+func (i *instance) authenticate(tc torgoController) error {
+	if i.useCookie && i.cookieFile != "" {
+		return authenticateCookieFile(i.cookieFile)(tc)
+	}
+
+	if i.useCookie {
+		return authenticateCookie(tc)
+	}
+
+	if len(i.password) != 0 {
+		return authenticatePassword(i.password)(tc)
+	}
+
+	return authenticateNone(tc)
+}
@@ -0,0 +1,142 @@
+package tor
+
+import (
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_parseBootstrapStatus_parsesAStatusClientEvent(c *C) {
+	st, ok := parseBootstrapStatus(`STATUS_CLIENT NOTICE BOOTSTRAP PROGRESS=45 TAG=requesting_descriptors SUMMARY="Asking for relay descriptors"`)
+
+	c.Assert(ok, Equals, true)
+	c.Assert(st.Progress, Equals, 45)
+	c.Assert(st.Tag, Equals, "requesting_descriptors")
+	c.Assert(st.Summary, Equals, "Asking for relay descriptors")
+	c.Assert(st.Warning, Equals, "")
+	c.Assert(st.Done(), Equals, false)
+}
+
+func (s *WahayTorSuite) Test_parseBootstrapStatus_parsesTheBootstrapPhaseWithAWarning(c *C) {
+	st, ok := parseBootstrapStatus(`WARN BOOTSTRAP PROGRESS=10 TAG=conn_done SUMMARY="Connected to a relay" WARNING="Connection refused \"here\"" REASON=CONNECTREFUSED COUNT=1 RECOMMENDATION=ignore`)
+
+	c.Assert(ok, Equals, true)
+	c.Assert(st.Progress, Equals, 10)
+	c.Assert(st.Tag, Equals, "conn_done")
+	c.Assert(st.Warning, Equals, `Connection refused "here"`)
+}
+
+func (s *WahayTorSuite) Test_parseBootstrapStatus_recognizesTheEndOfTheBootstrap(c *C) {
+	st, ok := parseBootstrapStatus(`NOTICE BOOTSTRAP PROGRESS=100 TAG=done SUMMARY="Done"`)
+
+	c.Assert(ok, Equals, true)
+	c.Assert(st.Done(), Equals, true)
+}
+
+func (s *WahayTorSuite) Test_parseBootstrapStatus_ignoresOtherStatusEvents(c *C) {
+	_, ok := parseBootstrapStatus(`STATUS_CLIENT NOTICE CIRCUIT_ESTABLISHED`)
+	c.Assert(ok, Equals, false)
+
+	_, ok = parseBootstrapStatus(`STATUS_CLIENT NOTICE BOOTSTRAP PROGRESS=abc TAG=done`)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *WahayTorSuite) Test_waitForBootstrap_reportsTheProgressUntilTorIsConnected(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	ec := &mockTorgoEventController{
+		bootstrapPhase: `NOTICE BOOTSTRAP PROGRESS=5 TAG=conn SUMMARY="Connecting to a relay"`,
+		events: []string{
+			`STATUS_CLIENT NOTICE CIRCUIT_ESTABLISHED`,
+			`STATUS_CLIENT NOTICE BOOTSTRAP PROGRESS=50 TAG=loading_descriptors SUMMARY="Loading relay descriptors"`,
+			`STATUS_CLIENT NOTICE BOOTSTRAP PROGRESS=100 TAG=done SUMMARY="Done"`,
+		},
+	}
+
+	mocktorgof.onNewEventController = func(a string) (torgoEventController, error) {
+		c.Assert(a, Equals, "127.0.0.1:9051")
+		return ec, nil
+	}
+
+	i := &instance{controlHost: "127.0.0.1", controlPort: 9051, useCookie: true}
+
+	progress := []int{}
+	err := i.waitForBootstrap(func(st BootstrapStatus) {
+		progress = append(progress, st.Progress)
+	}, time.Second)
+
+	c.Assert(err, IsNil)
+	c.Assert(progress, DeepEquals, []int{5, 50, 100})
+	c.Assert(ec.setEventsCalls, DeepEquals, [][]string{{"STATUS_CLIENT", "STATUS_GENERAL", "WARN"}, nil})
+	c.Assert(ec.authCookieCalled, Equals, 1)
+}
+
+func (s *WahayTorSuite) Test_waitForBootstrap_keepsTheOwnershipOfTheTorProcess(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	ec := &mockTorgoEventController{
+		bootstrapPhase: `NOTICE BOOTSTRAP PROGRESS=100 TAG=done SUMMARY="Done"`,
+	}
+
+	mocktorgof.onNewEventController = func(a string) (torgoEventController, error) {
+		return ec, nil
+	}
+
+	i := &instance{controlHost: "127.0.0.1", controlPort: 9051, useCookie: true}
+
+	err := i.waitForBootstrap(nil, time.Second)
+
+	c.Assert(err, IsNil)
+	c.Assert(ec.ownershipTaken, Equals, true)
+	c.Assert(ec.closed, Equals, false)
+
+	i.Destroy()
+
+	c.Assert(ec.closed, Equals, true)
+	c.Assert(i.ownerController, IsNil)
+}
+
+func (s *WahayTorSuite) Test_waitForBootstrap_failsWhenTorStopsBeforeConnecting(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	ec := &mockTorgoEventController{
+		bootstrapPhase: `NOTICE BOOTSTRAP PROGRESS=0 TAG=starting SUMMARY="Starting"`,
+	}
+
+	mocktorgof.onNewEventController = func(a string) (torgoEventController, error) {
+		return ec, nil
+	}
+
+	i := &instance{controlHost: "127.0.0.1", controlPort: 9051}
+
+	err := i.waitForBootstrap(nil, time.Second)
+
+	c.Assert(err, Equals, ErrTorInstanceCantStart)
+	c.Assert(ec.closed, Equals, true)
+	c.Assert(i.ownerController, IsNil)
+}
+
+func (s *WahayTorSuite) Test_waitForBootstrap_timesOutWhenTheControlPortIsNotAvailable(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	origInterval := bootstrapRetryInterval
+	defer func() {
+		bootstrapRetryInterval = origInterval
+	}()
+	bootstrapRetryInterval = time.Millisecond
+
+	mocktorgof.onNewEventController = func(a string) (torgoEventController, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	i := &instance{controlHost: "127.0.0.1", controlPort: 9051}
+
+	err := i.waitForBootstrap(nil, 10*time.Millisecond)
+
+	c.Assert(err, Equals, ErrTorConnectionTimeout)
+}
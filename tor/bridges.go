@@ -0,0 +1,288 @@
+package tor
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidBridgeLine is an error to be trown when a bridge
+	// line can't be understood
+	ErrInvalidBridgeLine = errors.New("invalid bridge line")
+
+	// ErrUnsupportedTransport is an error to be trown when a bridge
+	// uses a pluggable transport Wahay doesn't know how to run
+	ErrUnsupportedTransport = errors.New("unsupported pluggable transport")
+
+	// ErrTransportPluginNotFound is an error to be trown when the binary
+	// for a pluggable transport used by the bridges can't be found
+	ErrTransportPluginNotFound = errors.New("pluggable transport binary not found")
+
+	// ErrInvalidTransportPluginPath is an error to be trown when the binary for
+	// a pluggable transport is in a path Tor can't run it from
+	ErrInvalidTransportPluginPath = errors.New("invalid pluggable transport binary path")
+
+	// ErrInvalidBridgesConfiguration is an error to be trown when Tor
+	// doesn't accept the configuration generated for the bridges
+	ErrInvalidBridgesConfiguration = errors.New("invalid bridges configuration")
+)
+
+const bridgeLinePrefix = "bridge"
+
+// transportPlugins contains, for each supported pluggable transport, the names
+// of the binaries that can provide it, in order of preference
+var transportPlugins = map[string][]string{
+	"obfs4":     {"lyrebird", "obfs4proxy"},
+	"meek_lite": {"lyrebird", "obfs4proxy"},
+	"snowflake": {"snowflake-client", "lyrebird"},
+}
+
+// transportPluginDirs are the directories, relative to the Tor binary,
+// where the Tor Browser and the Tor Expert Bundle put the transport plugins
+var transportPluginDirs = []string{
+	"",
+	"PluggableTransports",
+	"pluggable_transports",
+}
+
+// Bridge is a representation of a Tor bridge line
+type Bridge struct {
+	Transport   string
+	Address     string
+	Fingerprint string
+	Arguments   []string
+}
+
+// String returns the bridge as it is written in the Tor configuration file,
+// without the Bridge keyword
+func (b *Bridge) String() string {
+	parts := []string{}
+	if len(b.Transport) != 0 {
+		parts = append(parts, b.Transport)
+	}
+	parts = append(parts, b.Address)
+	if len(b.Fingerprint) != 0 {
+		parts = append(parts, b.Fingerprint)
+	}
+	parts = append(parts, b.Arguments...)
+
+	return strings.Join(parts, " ")
+}
+
+// ParseBridgeLine returns the bridge described by the given line. The line can
+// be written with or without the Bridge keyword used in the Tor configuration file
+func ParseBridgeLine(line string) (*Bridge, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && strings.ToLower(fields[0]) == bridgeLinePrefix {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return nil, ErrInvalidBridgeLine
+	}
+
+	b := &Bridge{}
+
+	if !isBridgeAddress(fields[0]) {
+		b.Transport = fields[0]
+		fields = fields[1:]
+
+		if _, ok := transportPlugins[b.Transport]; !ok {
+			return nil, ErrUnsupportedTransport
+		}
+	}
+
+	if len(fields) == 0 || !isBridgeAddress(fields[0]) {
+		return nil, ErrInvalidBridgeLine
+	}
+
+	b.Address = fields[0]
+	fields = fields[1:]
+
+	if len(fields) > 0 && !strings.Contains(fields[0], "=") {
+		if !isBridgeFingerprint(fields[0]) {
+			return nil, ErrInvalidBridgeLine
+		}
+		b.Fingerprint = strings.ToUpper(fields[0])
+		fields = fields[1:]
+	}
+
+	for _, arg := range fields {
+		if strings.Index(arg, "=") < 1 {
+			return nil, ErrInvalidBridgeLine
+		}
+	}
+
+	b.Arguments = fields
+
+	return b, nil
+}
+
+// ParseBridgeLines returns the bridges contained in the given text, one per
+// line, as they are given by https://bridges.torproject.org. Empty lines and
+// comments are ignored
+func ParseBridgeLines(text string) ([]*Bridge, error) {
+	result := []*Bridge{}
+
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		b, err := ParseBridgeLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, b)
+	}
+
+	return result, nil
+}
+
+func isBridgeAddress(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || len(host) == 0 {
+		return false
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return false
+	}
+
+	return net.ParseIP(host) != nil
+}
+
+func isBridgeFingerprint(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// findTransportPlugins returns, for each transport used by the bridges, the path
+// to the binary implementing it. The binaries are searched next to the Tor binary
+// first, since that's where the bundles put them, and then in the system
+func findTransportPlugins(torBinary string, bridges []*Bridge) (map[string]string, error) {
+	result := map[string]string{}
+
+	for _, b := range bridges {
+		if len(b.Transport) == 0 {
+			continue
+		}
+
+		if _, ok := result[b.Transport]; ok {
+			continue
+		}
+
+		path, ok := findTransportPlugin(torBinary, b.Transport)
+		if !ok {
+			log.WithFields(log.Fields{
+				"transport": b.Transport,
+			}).Error("findTransportPlugins(): no binary found for the pluggable transport")
+			return nil, ErrTransportPluginNotFound
+		}
+
+		path, err := transportPluginPath(path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"transport": b.Transport,
+				"path":      path,
+			}).Error("findTransportPlugins(): the binary for the pluggable transport can't be run by Tor")
+			return nil, err
+		}
+
+		result[b.Transport] = path
+	}
+
+	return result, nil
+}
+
+func findTransportPlugin(torBinary, transport string) (string, bool) {
+	for _, name := range transportPlugins[transport] {
+		if runtime.GOOS == "windows" {
+			name = name + ".exe"
+		}
+
+		if len(torBinary) != 0 {
+			for _, dir := range transportPluginDirs {
+				path := filepath.Join(filepath.Dir(torBinary), dir, name)
+				if filesystemf.FileExists(path) {
+					return path, true
+				}
+			}
+		}
+
+		path, err := execf.LookPath(name)
+		if err == nil {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+// transportPluginPath returns the path to use in the Tor configuration for the
+// given transport plugin. Tor splits the ClientTransportPlugin value on spaces,
+// even when it's quoted, so a path with spaces is replaced with its short name
+func transportPluginPath(path string) (string, error) {
+	if !strings.ContainsAny(path, " \t") {
+		return path, nil
+	}
+
+	short, err := osf.ShortPathName(path)
+	if err != nil || strings.ContainsAny(short, " \t") {
+		return path, ErrInvalidTransportPluginPath
+	}
+
+	return short, nil
+}
+
+// bridgesConfiguration returns the lines to add to the Tor configuration
+// file to connect to the network through the given bridges
+func bridgesConfiguration(bridges []*Bridge, plugins map[string]string) string {
+	if len(bridges) == 0 {
+		return ""
+	}
+
+	lines := []string{"UseBridges 1"}
+
+	transportsByPlugin := map[string][]string{}
+	for transport, path := range plugins {
+		transportsByPlugin[path] = append(transportsByPlugin[path], transport)
+	}
+
+	paths := make([]string, 0, len(transportsByPlugin))
+	for path := range transportsByPlugin {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		transports := transportsByPlugin[path]
+		sort.Strings(transports)
+		plugin := fmt.Sprintf("%s exec %s", strings.Join(transports, ","), path)
+		lines = append(lines, "ClientTransportPlugin "+quoteTorrcValue(plugin))
+	}
+
+	for _, b := range bridges {
+		lines = append(lines, fmt.Sprintf("Bridge %s", b.String()))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,245 @@
+package tor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/digitalautonomy/wahay/config"
+	. "gopkg.in/check.v1"
+)
+
+const (
+	testObfs4Bridge = "obfs4 192.0.2.10:443 0123456789ABCDEF0123456789ABCDEF01234567 " +
+		"cert=dGVzdGNlcnRpZmljYXRl iat-mode=0"
+	testSnowflakeBridge = "snowflake 192.0.2.3:80 2B280B23E1107BB62ABFC40DDCC8824814F80A72 " +
+		"fingerprint=2B280B23E1107BB62ABFC40DDCC8824814F80A72 url=https://snowflake-broker.torproject.net/"
+)
+
+func (s *WahayTorSuite) Test_ParseBridgeLine_parsesAPluggableTransportBridge(c *C) {
+	b, err := ParseBridgeLine("Bridge " + testObfs4Bridge)
+
+	c.Assert(err, IsNil)
+	c.Assert(b.Transport, Equals, "obfs4")
+	c.Assert(b.Address, Equals, "192.0.2.10:443")
+	c.Assert(b.Fingerprint, Equals, "0123456789ABCDEF0123456789ABCDEF01234567")
+	c.Assert(b.Arguments, DeepEquals, []string{"cert=dGVzdGNlcnRpZmljYXRl", "iat-mode=0"})
+	c.Assert(b.String(), Equals, testObfs4Bridge)
+}
+
+func (s *WahayTorSuite) Test_ParseBridgeLine_parsesAVanillaBridge(c *C) {
+	b, err := ParseBridgeLine("[2001:db8::1]:9001")
+
+	c.Assert(err, IsNil)
+	c.Assert(b.Transport, Equals, "")
+	c.Assert(b.Address, Equals, "[2001:db8::1]:9001")
+	c.Assert(b.Fingerprint, Equals, "")
+	c.Assert(b.String(), Equals, "[2001:db8::1]:9001")
+}
+
+func (s *WahayTorSuite) Test_ParseBridgeLine_returnsAnErrorForInvalidLines(c *C) {
+	for _, line := range []string{
+		"",
+		"Bridge",
+		"obfs4",
+		"obfs4 example.org:443",
+		"obfs4 192.0.2.10:99999",
+		"obfs4 192.0.2.10:443 notAFingerprint",
+		"obfs4 192.0.2.10:443 0123456789ABCDEF0123456789ABCDEF01234567 =value",
+	} {
+		_, err := ParseBridgeLine(line)
+		c.Assert(err, Equals, ErrInvalidBridgeLine, Commentf("line: %s", line))
+	}
+}
+
+func (s *WahayTorSuite) Test_ParseBridgeLine_returnsAnErrorForUnsupportedTransports(c *C) {
+	_, err := ParseBridgeLine("fte 192.0.2.10:443")
+
+	c.Assert(err, Equals, ErrUnsupportedTransport)
+}
+
+func (s *WahayTorSuite) Test_ParseBridgeLines_ignoresEmptyLinesAndComments(c *C) {
+	bridges, err := ParseBridgeLines("# bridges from bridges.torproject.org\n\n" +
+		testObfs4Bridge + "\n  \n" + testSnowflakeBridge + "\n")
+
+	c.Assert(err, IsNil)
+	c.Assert(bridges, HasLen, 2)
+	c.Assert(bridges[0].Transport, Equals, "obfs4")
+	c.Assert(bridges[1].Transport, Equals, "snowflake")
+}
+
+func (s *WahayTorSuite) Test_findTransportPlugins_prefersTheBinariesNextToTor(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	torBinary := filepath.Join("/opt", "wahay", "tor", "tor")
+	lyrebird := filepath.Join("/opt", "wahay", "tor", "PluggableTransports", "lyrebird")
+
+	mockfilesystemf.onFileExists = func(path string) bool {
+		return path == lyrebird
+	}
+	mockexecf.lookPathReturn1 = "/usr/bin/snowflake-client"
+
+	bridges, err := ParseBridgeLines(testObfs4Bridge + "\n" + testSnowflakeBridge)
+	c.Assert(err, IsNil)
+
+	plugins, err := findTransportPlugins(torBinary, bridges)
+
+	c.Assert(err, IsNil)
+	c.Assert(plugins, DeepEquals, map[string]string{
+		"obfs4":     lyrebird,
+		"snowflake": "/usr/bin/snowflake-client",
+	})
+}
+
+func (s *WahayTorSuite) Test_findTransportPlugins_returnsAnErrorWhenThePluginIsMissing(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockexecf.lookPathReturn2 = errors.New("not found")
+
+	bridges, err := ParseBridgeLines(testObfs4Bridge)
+	c.Assert(err, IsNil)
+
+	_, err = findTransportPlugins("/usr/sbin/tor", bridges)
+
+	c.Assert(err, Equals, ErrTransportPluginNotFound)
+}
+
+func (s *WahayTorSuite) Test_bridgesConfiguration_rendersTheTorConfiguration(c *C) {
+	bridges, err := ParseBridgeLines(testObfs4Bridge + "\n" + testSnowflakeBridge)
+	c.Assert(err, IsNil)
+
+	content := bridgesConfiguration(bridges, map[string]string{
+		"obfs4":     "/opt/tor/lyrebird",
+		"meek_lite": "/opt/tor/lyrebird",
+		"snowflake": "/opt/tor/snowflake-client",
+	})
+
+	c.Assert(content, Equals, "UseBridges 1\n"+
+		"ClientTransportPlugin \"meek_lite,obfs4 exec /opt/tor/lyrebird\"\n"+
+		"ClientTransportPlugin \"snowflake exec /opt/tor/snowflake-client\"\n"+
+		"Bridge "+testObfs4Bridge+"\n"+
+		"Bridge "+testSnowflakeBridge+"\n")
+}
+
+func (s *WahayTorSuite) Test_bridgesConfiguration_escapesThePathsOfThePlugins(c *C) {
+	bridges, err := ParseBridgeLines(testObfs4Bridge)
+	c.Assert(err, IsNil)
+
+	content := bridgesConfiguration(bridges, map[string]string{
+		"obfs4": `C:\PROGRA~1\Tor#1\lyrebird.exe`,
+	})
+
+	c.Assert(content, Equals, "UseBridges 1\n"+
+		`ClientTransportPlugin "obfs4 exec C:\\PROGRA~1\\Tor#1\\lyrebird.exe"`+"\n"+
+		"Bridge "+testObfs4Bridge+"\n")
+}
+
+func (s *WahayTorSuite) Test_findTransportPlugins_usesTheShortNameOfAPathWithSpaces(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockexecf.lookPathReturn1 = `C:\Program Files\Tor\lyrebird.exe`
+	mockosf.onShortPathName = func(path string) (string, error) {
+		c.Assert(path, Equals, `C:\Program Files\Tor\lyrebird.exe`)
+		return `C:\PROGRA~1\Tor\lyrebird.exe`, nil
+	}
+
+	bridges, err := ParseBridgeLines(testObfs4Bridge)
+	c.Assert(err, IsNil)
+
+	plugins, err := findTransportPlugins("", bridges)
+
+	c.Assert(err, IsNil)
+	c.Assert(plugins, DeepEquals, map[string]string{"obfs4": `C:\PROGRA~1\Tor\lyrebird.exe`})
+	c.Assert(bridgesConfiguration(bridges, plugins), Equals, "UseBridges 1\n"+
+		`ClientTransportPlugin "obfs4 exec C:\\PROGRA~1\\Tor\\lyrebird.exe"`+"\n"+
+		"Bridge "+testObfs4Bridge+"\n")
+}
+
+func (s *WahayTorSuite) Test_findTransportPlugins_returnsAnErrorForAPathWithSpacesWithoutShortName(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockexecf.lookPathReturn1 = "/opt/my tools/lyrebird"
+
+	bridges, err := ParseBridgeLines(testObfs4Bridge)
+	c.Assert(err, IsNil)
+
+	_, err = findTransportPlugins("", bridges)
+
+	c.Assert(err, Equals, ErrInvalidTransportPluginPath)
+}
+
+func (s *WahayTorSuite) Test_bridgesConfiguration_returnsNothingWithoutBridges(c *C) {
+	c.Assert(bridgesConfiguration(nil, nil), Equals, "")
+}
+
+func (s *WahayTorSuite) Test_instance_useBridges_writesTheBridgesAndVerifiesTheConfiguration(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	var written []byte
+	mockfilesystemf.onWriteFile = func(name string, content []byte, _ os.FileMode) error {
+		written = content
+		return nil
+	}
+
+	var verifyArgs []string
+	mockexecf.onExecWithModify = func(bin string, args []string, _ ModifyCommand) ([]byte, error) {
+		verifyArgs = args
+		return []byte("Configuration was valid"), nil
+	}
+	mockexecf.lookPathReturn1 = "/usr/bin/lyrebird"
+
+	conf := &config.ApplicationConfig{}
+	conf.EnableBridges(true)
+	conf.SetBridgeLines([]string{testObfs4Bridge})
+
+	i := &instance{
+		configFile: "/tmp/wahay-tor/torrc",
+		binary:     &binary{path: "/usr/sbin/tor", isValid: true},
+	}
+
+	err := i.useBridges(conf)
+
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(written), "UseBridges 1\n"), Equals, true)
+	c.Assert(strings.Contains(string(written), "ClientTransportPlugin \"obfs4 exec /usr/bin/lyrebird\"\n"), Equals, true)
+	c.Assert(strings.Contains(string(written), "Bridge "+testObfs4Bridge+"\n"), Equals, true)
+	c.Assert(verifyArgs, DeepEquals, []string{"--verify-config", "-f", "/tmp/wahay-tor/torrc"})
+}
+
+func (s *WahayTorSuite) Test_instance_useBridges_returnsAnErrorWhenTorRejectsTheConfiguration(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockexecf.onExecWithModify = func(string, []string, ModifyCommand) ([]byte, error) {
+		return nil, &exec.ExitError{}
+	}
+	mockexecf.lookPathReturn1 = "/usr/bin/lyrebird"
+
+	conf := &config.ApplicationConfig{}
+	conf.EnableBridges(true)
+	conf.SetBridgeLines([]string{testObfs4Bridge})
+
+	i := &instance{
+		configFile: "/tmp/wahay-tor/torrc",
+		binary:     &binary{path: "/usr/sbin/tor", isValid: true},
+	}
+
+	c.Assert(i.useBridges(conf), Equals, ErrInvalidBridgesConfiguration)
+}
+
+func (s *WahayTorSuite) Test_instance_useBridges_doesNothingWhenBridgesAreDisabled(c *C) {
+	conf := &config.ApplicationConfig{}
+	conf.SetBridgeLines([]string{testObfs4Bridge})
+
+	i := &instance{}
+
+	c.Assert(i.useBridges(conf), IsNil)
+	c.Assert(i.bridges, IsNil)
+}
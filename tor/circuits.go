@@ -0,0 +1,417 @@
+package tor
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrCircuitDiagnosticsUnavailable is an error to be trown when the circuits
+// of a meeting can't be followed, usually because a filter in front of the
+// control port doesn't allow the commands or the events needed for it
+var ErrCircuitDiagnosticsUnavailable = errors.New("the circuits of the meeting can't be followed")
+
+const (
+	circuitStatusInfo = "circuit-status"
+	streamStatusInfo  = "stream-status"
+	circuitEvent      = "CIRC"
+	streamEvent       = "STREAM"
+
+	// circuitTimeLayout is the format of the TIME_CREATED
+	// argument, which Tor always sends in UTC
+	circuitTimeLayout = "2006-01-02T15:04:05.999999"
+)
+
+// The statuses of a circuit, as described in the
+// section 4.1.1 of the Tor control protocol specification
+const (
+	CircuitLaunched = "LAUNCHED"
+	CircuitBuilt    = "BUILT"
+	CircuitExtended = "EXTENDED"
+	CircuitFailed   = "FAILED"
+	CircuitClosed   = "CLOSED"
+)
+
+// Circuit is a representation of a circuit built by Tor
+type Circuit struct {
+	ID      string
+	Status  string
+	Path    []string
+	Purpose string
+	// RendQuery is the onion service the circuit is used for, without
+	// the ".onion" suffix, when it is a circuit for an onion service
+	RendQuery string
+	Created   time.Time
+	// BuildTime is how long Tor took to build the circuit,
+	// when we have been following it while it was being built
+	BuildTime time.Duration
+	Reason    string
+}
+
+// Hops returns the number of relays the circuit goes through
+func (c Circuit) Hops() int {
+	return len(c.Path)
+}
+
+// Age returns how long ago the circuit was created
+func (c Circuit) Age() time.Duration {
+	if c.Created.IsZero() {
+		return 0
+	}
+	return time.Since(c.Created)
+}
+
+// Finished returns a boolean indicating if the circuit
+// has been closed or couldn't be built
+func (c Circuit) Finished() bool {
+	return c.Status == CircuitFailed || c.Status == CircuitClosed
+}
+
+// Stream is a representation of a connection that Tor
+// sends through one of its circuits
+type Stream struct {
+	ID        string
+	Status    string
+	CircuitID string
+	Target    string
+}
+
+// parseCircuit parses the description of a circuit contained either in a
+// CIRC event or in a line of the circuit-status information, as described
+// in the sections 4.1.1 and 3.9 of the Tor control protocol specification
+func parseCircuit(line string) (Circuit, bool) {
+	fields := splitStatusArguments(line)
+	if len(fields) < 2 {
+		return Circuit{}, false
+	}
+
+	c := Circuit{ID: fields[0], Status: fields[1]}
+
+	rest := fields[2:]
+	if len(rest) > 0 && strings.HasPrefix(rest[0], "$") {
+		c.Path = strings.Split(rest[0], ",")
+		rest = rest[1:]
+	}
+
+	for _, f := range rest {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "PURPOSE":
+			c.Purpose = kv[1]
+		case "REND_QUERY":
+			c.RendQuery = kv[1]
+		case "REASON":
+			c.Reason = kv[1]
+		case "TIME_CREATED":
+			t, err := time.ParseInLocation(circuitTimeLayout, kv[1], time.UTC)
+			if err == nil {
+				c.Created = t
+			}
+		}
+	}
+
+	return c, true
+}
+
+// parseStream parses the description of a stream contained either in a
+// STREAM event or in a line of the stream-status information, as described
+// in the sections 4.1.2 and 3.9 of the Tor control protocol specification
+func parseStream(line string) (Stream, bool) {
+	fields := splitStatusArguments(line)
+	if len(fields) < 4 {
+		return Stream{}, false
+	}
+
+	return Stream{
+		ID:        fields[0],
+		Status:    fields[1],
+		CircuitID: fields[2],
+		Target:    fields[3],
+	}, true
+}
+
+// onionServiceID returns the ID of the onion service of a meeting, as
+// Tor reports it in the REND_QUERY argument of the circuits
+func onionServiceID(meetingID string) string {
+	id := strings.ToLower(strings.TrimSpace(meetingID))
+	if host, _, err := net.SplitHostPort(id); err == nil {
+		id = host
+	}
+	return strings.TrimSuffix(id, onionSuffix)
+}
+
+// CircuitDiagnostics follows the circuits that carry the traffic of
+// a meeting, both the ones of its onion service and the ones used by the
+// forwarder of a participant, so the quality of a meeting can be explained
+type CircuitDiagnostics interface {
+	// Circuits returns the circuits of the meeting that are open or being built
+	Circuits() []Circuit
+	// Failures returns how many circuits of the meeting
+	// couldn't be built since we started following them
+	Failures() int
+	// NewCircuits closes the current circuits of the meeting, so Tor builds
+	// new ones. The circuits of other meetings using the same Tor are kept
+	NewCircuits() error
+	// Close stops following the circuits of the meeting
+	Close()
+}
+
+type circuitDiagnostics struct {
+	sync.Mutex
+	onionID  string
+	ec       torgoEventController
+	connect  func() (torgoEventController, error)
+	onChange func()
+	circuits map[string]*Circuit
+	// meeting contains the IDs of the circuits carrying the traffic of the meeting
+	meeting  map[string]bool
+	failures int
+	closed   bool
+}
+
+// DiagnoseCircuits starts following the circuits of the meeting with the given
+// ID, calling the given function every time one of them changes
+func (i *instance) DiagnoseCircuits(meetingID string, onChange func()) (CircuitDiagnostics, error) {
+	connect := func() (torgoEventController, error) {
+		ec, err := torgof.NewEventController(i.controlAddress())
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.authenticate(ec)
+		if err != nil {
+			_ = ec.Close()
+			return nil, err
+		}
+
+		return ec, nil
+	}
+
+	return newCircuitDiagnostics(meetingID, connect, onChange)
+}
+
+func newCircuitDiagnostics(meetingID string, connect func() (torgoEventController, error), onChange func()) (*circuitDiagnostics, error) {
+	d := &circuitDiagnostics{
+		onionID:  onionServiceID(meetingID),
+		connect:  connect,
+		onChange: onChange,
+		circuits: make(map[string]*Circuit),
+		meeting:  make(map[string]bool),
+	}
+
+	ec, err := connect()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("newCircuitDiagnostics(): can't connect to the control port")
+		return nil, ErrCircuitDiagnosticsUnavailable
+	}
+
+	err = d.follow(ec)
+	if err != nil {
+		_ = ec.Close()
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("newCircuitDiagnostics(): can't follow the circuits")
+		return nil, ErrCircuitDiagnosticsUnavailable
+	}
+
+	d.ec = ec
+
+	go d.readEvents()
+
+	return d, nil
+}
+
+// follow subscribes to the events of the circuits and the streams, and then
+// takes their current state, so no change can be lost between both steps
+func (d *circuitDiagnostics) follow(ec torgoEventController) error {
+	err := ec.SetEvents(circuitEvent, streamEvent)
+	if err != nil {
+		return err
+	}
+
+	circuits, err := ec.GetInfo(circuitStatusInfo)
+	if err != nil {
+		return err
+	}
+
+	streams, err := ec.GetInfo(streamStatusInfo)
+	if err != nil {
+		return err
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	for _, l := range strings.Split(circuits, "\n") {
+		if c, ok := parseCircuit(l); ok {
+			d.updateCircuit(c, false)
+		}
+	}
+
+	for _, l := range strings.Split(streams, "\n") {
+		if s, ok := parseStream(l); ok {
+			d.updateStream(s)
+		}
+	}
+
+	return nil
+}
+
+func (d *circuitDiagnostics) readEvents() {
+	for {
+		ev, err := d.ec.ReadEvent()
+		if err != nil {
+			if !d.isClosed() {
+				log.WithFields(log.Fields{
+					"error": err,
+				}).Debug("circuitDiagnostics.readEvents(): the connection to the control port was lost")
+			}
+			return
+		}
+
+		if d.processEvent(ev) && d.onChange != nil {
+			d.onChange()
+		}
+	}
+}
+
+// processEvent updates the state of the circuits with the given event,
+// returning a boolean indicating if a circuit of the meeting has changed
+func (d *circuitDiagnostics) processEvent(ev string) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	switch {
+	case strings.HasPrefix(ev, circuitEvent+" "):
+		c, ok := parseCircuit(strings.TrimPrefix(ev, circuitEvent+" "))
+		return ok && d.updateCircuit(c, true)
+	case strings.HasPrefix(ev, streamEvent+" "):
+		s, ok := parseStream(strings.TrimPrefix(ev, streamEvent+" "))
+		return ok && d.updateStream(s)
+	}
+
+	return false
+}
+
+func (d *circuitDiagnostics) updateCircuit(c Circuit, live bool) bool {
+	previous, known := d.circuits[c.ID]
+
+	if c.RendQuery != "" && c.RendQuery == d.onionID {
+		d.meeting[c.ID] = true
+	}
+
+	if known {
+		c.BuildTime = previous.BuildTime
+	}
+
+	// The build time can only be known when we receive the event of
+	// the circuit being built, since Tor doesn't report it otherwise
+	if live && c.Status == CircuitBuilt && !c.Created.IsZero() && (!known || previous.Status != CircuitBuilt) {
+		c.BuildTime = time.Since(c.Created)
+	}
+
+	isMeeting := d.meeting[c.ID]
+
+	if c.Finished() {
+		delete(d.circuits, c.ID)
+		delete(d.meeting, c.ID)
+
+		if isMeeting && c.Status == CircuitFailed {
+			d.failures++
+		}
+
+		return isMeeting
+	}
+
+	d.circuits[c.ID] = &c
+
+	return isMeeting
+}
+
+func (d *circuitDiagnostics) updateStream(s Stream) bool {
+	host, _, err := net.SplitHostPort(s.Target)
+	if err != nil || onionServiceID(host) != d.onionID {
+		return false
+	}
+
+	if s.CircuitID == "0" || d.meeting[s.CircuitID] {
+		return false
+	}
+
+	d.meeting[s.CircuitID] = true
+
+	return true
+}
+
+func (d *circuitDiagnostics) Circuits() []Circuit {
+	d.Lock()
+	defer d.Unlock()
+
+	result := []Circuit{}
+	for id := range d.meeting {
+		if c, ok := d.circuits[id]; ok {
+			result = append(result, *c)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, _ := strconv.Atoi(result[i].ID)
+		b, _ := strconv.Atoi(result[j].ID)
+		return a < b
+	})
+
+	return result
+}
+
+func (d *circuitDiagnostics) Failures() int {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.failures
+}
+
+func (d *circuitDiagnostics) NewCircuits() error {
+	ec, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = ec.Close()
+	}()
+
+	for _, c := range d.Circuits() {
+		err = ec.CloseCircuit(c.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *circuitDiagnostics) isClosed() bool {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.closed
+}
+
+func (d *circuitDiagnostics) Close() {
+	d.Lock()
+	d.closed = true
+	d.Unlock()
+
+	_ = d.ec.Close()
+}
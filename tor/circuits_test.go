@@ -0,0 +1,168 @@
+package tor
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wybiral/torgo"
+	. "gopkg.in/check.v1"
+)
+
+const (
+	fakeMeetingID = fakeServiceID + ".onion"
+
+	fakeCircuitStatus = "3 BUILT $AAAA~guard,$BBBB~middle,$CCCC~exit BUILD_FLAGS=NEED_CAPACITY PURPOSE=GENERAL TIME_CREATED=2024-05-01T10:00:00.000000\n" +
+		"5 BUILT $AAAA~guard,$DDDD~middle,$EEEE~rend PURPOSE=HS_SERVICE_REND HS_STATE=HSSR_JOINED REND_QUERY=" + fakeServiceID + " TIME_CREATED=2024-05-01T10:01:00.500000\n" +
+		"7 BUILT $AAAA~guard,$FFFF~middle PURPOSE=HS_SERVICE_REND HS_STATE=HSSR_JOINED REND_QUERY=otherservice TIME_CREATED=2024-05-01T10:02:00.000000"
+)
+
+func (s *WahayTorSuite) Test_parseCircuit_readsTheDescriptionOfACircuit(c *C) {
+	circuit, ok := parseCircuit("5 BUILT $AAAA~guard,$DDDD~middle,$EEEE~rend BUILD_FLAGS=IS_INTERNAL,NEED_CAPACITY " +
+		"PURPOSE=HS_SERVICE_REND HS_STATE=HSSR_JOINED REND_QUERY=" + fakeServiceID + " TIME_CREATED=2024-05-01T10:01:00.500000")
+
+	c.Assert(ok, Equals, true)
+	c.Assert(circuit.ID, Equals, "5")
+	c.Assert(circuit.Status, Equals, CircuitBuilt)
+	c.Assert(circuit.Path, DeepEquals, []string{"$AAAA~guard", "$DDDD~middle", "$EEEE~rend"})
+	c.Assert(circuit.Hops(), Equals, 3)
+	c.Assert(circuit.Purpose, Equals, "HS_SERVICE_REND")
+	c.Assert(circuit.RendQuery, Equals, fakeServiceID)
+	c.Assert(circuit.Created, Equals, time.Date(2024, 5, 1, 10, 1, 0, 500000000, time.UTC))
+
+	circuit, ok = parseCircuit("9 FAILED PURPOSE=GENERAL REASON=TIMEOUT")
+
+	c.Assert(ok, Equals, true)
+	c.Assert(circuit.Hops(), Equals, 0)
+	c.Assert(circuit.Reason, Equals, "TIMEOUT")
+	c.Assert(circuit.Finished(), Equals, true)
+
+	_, ok = parseCircuit("")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *WahayTorSuite) Test_parseStream_readsTheDescriptionOfAStream(c *C) {
+	stream, ok := parseStream("12 SUCCEEDED 3 " + fakeMeetingID + ":64738 PURPOSE=USER")
+
+	c.Assert(ok, Equals, true)
+	c.Assert(stream, DeepEquals, Stream{ID: "12", Status: "SUCCEEDED", CircuitID: "3", Target: fakeMeetingID + ":64738"})
+
+	_, ok = parseStream("12 SUCCEEDED")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *WahayTorSuite) Test_onionServiceID_usesTheFormOfTheRendezvousQuery(c *C) {
+	c.Assert(onionServiceID(fakeMeetingID), Equals, fakeServiceID)
+	c.Assert(onionServiceID(" "+fakeMeetingID+":8181 "), Equals, fakeServiceID)
+	c.Assert(onionServiceID(fakeServiceID), Equals, fakeServiceID)
+}
+
+func newFakeCircuitDiagnostics(c *C, ec *mockTorgoEventController) *circuitDiagnostics {
+	log.SetOutput(ioutil.Discard)
+
+	d, err := newCircuitDiagnostics(fakeMeetingID, func() (torgoEventController, error) {
+		return ec, nil
+	}, nil)
+	c.Assert(err, IsNil)
+
+	return d
+}
+
+func (s *WahayTorSuite) Test_circuitDiagnostics_onlyFollowsTheCircuitsOfTheMeeting(c *C) {
+	ec := &mockTorgoEventController{info: map[string]string{
+		circuitStatusInfo: fakeCircuitStatus,
+		streamStatusInfo:  "20 SUCCEEDED 3 " + fakeMeetingID + ":64738\n21 SUCCEEDED 7 example.com:443",
+	}}
+
+	d := newFakeCircuitDiagnostics(c, ec)
+
+	c.Assert(ec.setEventsCalls, DeepEquals, [][]string{{circuitEvent, streamEvent}})
+
+	circuits := d.Circuits()
+	c.Assert(circuits, HasLen, 2)
+	c.Assert(circuits[0].ID, Equals, "3")
+	c.Assert(circuits[1].ID, Equals, "5")
+	c.Assert(circuits[1].BuildTime, Equals, time.Duration(0))
+
+	c.Assert(d.processEvent("CIRC 7 CLOSED $AAAA~guard,$FFFF~middle PURPOSE=HS_SERVICE_REND REASON=FINISHED"), Equals, false)
+	c.Assert(d.processEvent("STREAM 22 NEW 0 "+fakeMeetingID+":64738"), Equals, false)
+	c.Assert(d.processEvent("STREAM 22 SENTCONNECT 9 "+fakeMeetingID+":64738"), Equals, true)
+	c.Assert(d.processEvent("CIRC 9 FAILED PURPOSE=HS_CLIENT_REND REND_QUERY="+fakeServiceID+" REASON=TIMEOUT"), Equals, true)
+	c.Assert(d.processEvent("CIRC 3 CLOSED $AAAA~guard,$BBBB~middle,$CCCC~exit REASON=FINISHED"), Equals, true)
+
+	c.Assert(d.Circuits(), HasLen, 1)
+	c.Assert(d.Failures(), Equals, 1)
+
+	d.Close()
+	c.Assert(ec.closed, Equals, true)
+}
+
+func (s *WahayTorSuite) Test_circuitDiagnostics_measuresTheBuildTimeOfNewCircuits(c *C) {
+	d := newFakeCircuitDiagnostics(c, &mockTorgoEventController{info: map[string]string{}})
+
+	created := time.Now().Add(-1500 * time.Millisecond).UTC().Format(circuitTimeLayout)
+
+	c.Assert(d.processEvent("CIRC 11 LAUNCHED PURPOSE=HS_SERVICE_REND REND_QUERY="+fakeServiceID+" TIME_CREATED="+created), Equals, true)
+	c.Assert(d.processEvent("CIRC 11 BUILT $AAAA~guard,$DDDD~middle,$EEEE~rend PURPOSE=HS_SERVICE_REND REND_QUERY="+fakeServiceID+" TIME_CREATED="+created), Equals, true)
+
+	circuits := d.Circuits()
+	c.Assert(circuits, HasLen, 1)
+	c.Assert(circuits[0].Hops(), Equals, 3)
+	c.Assert(circuits[0].BuildTime >= 1500*time.Millisecond, Equals, true)
+	c.Assert(circuits[0].BuildTime < time.Minute, Equals, true)
+}
+
+func (s *WahayTorSuite) Test_circuitDiagnostics_NewCircuits_closesTheCircuitsOfTheMeeting(c *C) {
+	ec := &mockTorgoEventController{info: map[string]string{
+		circuitStatusInfo: fakeCircuitStatus,
+	}}
+
+	d := newFakeCircuitDiagnostics(c, ec)
+
+	c.Assert(d.NewCircuits(), IsNil)
+	c.Assert(ec.closedCircuits, DeepEquals, []string{"5"})
+}
+
+func (s *WahayTorSuite) Test_newCircuitDiagnostics_failsWhenTheEventsAreNotAllowed(c *C) {
+	log.SetOutput(ioutil.Discard)
+
+	_, err := newCircuitDiagnostics(fakeMeetingID, func() (torgoEventController, error) {
+		return nil, textproto.ProtocolError("refused")
+	}, nil)
+
+	c.Assert(err, Equals, ErrCircuitDiagnosticsUnavailable)
+}
+
+func (s *WahayTorSuite) Test_eventController_GetInfo_readsTheValuesSentAsData(c *C) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+
+		r := bufio.NewReader(server)
+		if _, err := r.ReadString('\n'); err != nil {
+			return
+		}
+		fmt.Fprint(server, "650 CIRC 1 LAUNCHED\r\n"+
+			"250+circuit-status=\r\n"+
+			"3 BUILT $AAAA~guard PURPOSE=GENERAL\r\n"+
+			"5 EXTENDED $AAAA~guard PURPOSE=GENERAL\r\n"+
+			".\r\n"+
+			"250 OK\r\n")
+	}()
+
+	ec := &eventController{controlConnection: &controlConnection{&torgo.Controller{Text: textproto.NewConn(client)}}}
+
+	value, err := ec.GetInfo(circuitStatusInfo)
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "3 BUILT $AAAA~guard PURPOSE=GENERAL\n5 EXTENDED $AAAA~guard PURPOSE=GENERAL")
+
+	ev, err := ec.ReadEvent()
+	c.Assert(err, IsNil)
+	c.Assert(ev, Equals, "CIRC 1 LAUNCHED")
+}
@@ -15,34 +15,49 @@ type basicConnectivity interface {
 }
 
 type connectivity struct {
-	host        string
-	routePort   int
-	controlPort int
-	password    string
-	authType    string
+	controlAddress string
+	routeAddress   string
+	password       string
+	cookieFile     string
+	authMethod     string
+	authType       string
 }
 
-func newCustomChecker(host string, routePort, controlPort int) basicConnectivity {
-	return newChecker(host, routePort, controlPort, "")
+func newCustomChecker(controlAddress, routeAddress string) basicConnectivity {
+	return newChecker(controlAddress, routeAddress, "")
+}
+
+// newEndpointChecker checks the connectivity of the Tor instance described by
+// the endpoint, only trying the authentication method configured, if any
+func newEndpointChecker(controlAddress, routeAddress string, e *config.TorEndpoint) basicConnectivity {
+	return &connectivity{
+		controlAddress: controlAddress,
+		routeAddress:   routeAddress,
+		password:       e.Password,
+		cookieFile:     e.CookieFile,
+		authMethod:     e.AuthMethod,
+	}
 }
 
 func newDefaultChecker(defaultControlPort int) basicConnectivity {
-	return newChecker(defaultControlHost, defaultSocksPort, defaultControlPort, *config.TorControlPassword)
+	return newChecker(
+		net.JoinHostPort(defaultControlHost, strconv.Itoa(defaultControlPort)),
+		net.JoinHostPort(defaultControlHost, strconv.Itoa(defaultSocksPort)),
+		*config.TorControlPassword)
 }
 
-// newChecker can check connectivity on custom ports, and optionally
+// newChecker can check connectivity on custom addresses, and optionally
 // avoid checking for binary compatibility
-func newChecker(host string, routePort, controlPort int, password string) basicConnectivity {
+func newChecker(controlAddress, routeAddress string, password string) basicConnectivity {
 	return &connectivity{
-		host:        host,
-		routePort:   routePort,
-		controlPort: controlPort,
-		password:    password,
+		controlAddress: controlAddress,
+		routeAddress:   routeAddress,
+		password:       password,
 	}
 }
 
 func (c *connectivity) checkTorControlPortExists() bool {
-	_, err := torgof.NewController(net.JoinHostPort(c.host, strconv.Itoa(c.controlPort)))
+	_, err := torgof.NewController(c.controlAddress)
 	return err == nil
 }
 
@@ -67,31 +82,46 @@ func (c *connectivity) settingAuthType(tp string, a authenticationMethod) authen
 }
 
 func (c *connectivity) checkTorControlAuth() bool {
-	where := net.JoinHostPort(c.host, strconv.Itoa(c.controlPort))
+	where := c.controlAddress
 
-	authCallback := authenticateAny(
-		withNewTorgoController(where, c.settingAuthType("none", authenticateNone)),
-		withNewTorgoController(where, c.settingAuthType("cookie", authenticateCookie)),
-		withNewTorgoController(where, c.settingAuthType("password", authenticatePassword(c.password))))
+	methods := []authenticationMethod{}
+	for _, tp := range []string{config.TorAuthNone, config.TorAuthCookie, config.TorAuthPassword} {
+		if c.authMethod == config.TorAuthAutomatic || c.authMethod == tp {
+			methods = append(methods, withNewTorgoController(where, c.settingAuthType(tp, c.authenticationFor(tp))))
+		}
+	}
+
+	if len(methods) == 0 {
+		return false
+	}
 
-	return authCallback(nil) == nil
+	return authenticateAny(methods...)(nil) == nil
 }
 
-func (c *connectivity) tryAuthenticate(tc torgoController) error {
-	switch c.authType {
-	case "none":
-		return authenticateNone(tc)
-	case "password":
-		return authenticatePassword(c.password)(tc)
-	case "cookie":
-		return authenticateCookie(tc)
+func (c *connectivity) authenticationFor(tp string) authenticationMethod {
+	switch tp {
+	case config.TorAuthNone:
+		return authenticateNone
+	case config.TorAuthPassword:
+		return authenticatePassword(c.password)
+	case config.TorAuthCookie:
+		if c.cookieFile != "" {
+			return authenticateCookieFile(c.cookieFile)
+		}
+		return authenticateCookie
 	default:
-		return errors.New("no valid authentication type")
+		return func(torgoController) error {
+			return errors.New("no valid authentication type")
+		}
 	}
 }
 
+func (c *connectivity) tryAuthenticate(tc torgoController) error {
+	return c.authenticationFor(c.authType)(tc)
+}
+
 func (c *connectivity) checkControlPortVersion() bool {
-	where := net.JoinHostPort(c.host, strconv.Itoa(c.controlPort))
+	where := c.controlAddress
 
 	tc, err := torgof.NewController(where)
 	if err != nil {
@@ -105,6 +135,12 @@ func (c *connectivity) checkControlPortVersion() bool {
 	}
 
 	v, err := tc.GetVersion()
+	if isCommandFiltered(err) {
+		// Only the Tor instances of systems like Tails and Whonix are
+		// behind a control port filter, and they are recent enough
+		log.Debugf("checkControlPortVersion() - the control port filter doesn't allow getting the version")
+		return true
+	}
 	if err != nil {
 		log.Debugf("checkControlPortVersion() - can't get version: %v", err)
 		return false
@@ -125,7 +161,7 @@ type checkTorResult struct {
 }
 
 func (c *connectivity) checkConnectionOverTor() bool {
-	return httpf.CheckConnectionOverTor(c.host, c.routePort)
+	return httpf.CheckConnectionOverTor(c.routeAddress)
 }
 
 var (
@@ -0,0 +1,97 @@
+package tor
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/wybiral/torgo"
+)
+
+// controlConnection adds to the torgo controller the commands it doesn't support
+type controlConnection struct {
+	*torgo.Controller
+}
+
+// controlReplyUnrecognizedCommand is the code of the reply sent by Tor to the
+// commands it doesn't know. The filters in front of the control port, like
+// onion-grater in Tails or the control port filter proxy in Whonix, use
+// the same code when they refuse a command
+const controlReplyUnrecognizedCommand = 510
+
+// isCommandFiltered returns a boolean indicating if the error is the reply of
+// a filter in front of the control port that didn't allow the command
+func isCommandFiltered(err error) bool {
+	var reply *textproto.Error
+	if !errors.As(err, &reply) || reply.Code != controlReplyUnrecognizedCommand {
+		return false
+	}
+	return !strings.HasPrefix(reply.Msg, "Unrecognized command")
+}
+
+// Close closes the connection to the control port
+func (c *controlConnection) Close() error {
+	return c.Text.Close()
+}
+
+// AuthenticateCookieFile authenticates with the cookie at the given path,
+// for when the path reported by Tor is not valid in this computer
+func (c *controlConnection) AuthenticateCookieFile(path string) error {
+	c.CookieFile = path
+	return c.AuthenticateCookie()
+}
+
+// AddOnionWithArguments works like AddOnion, but also sends the given
+// arguments, which torgo doesn't support
+func (c *controlConnection) AddOnionWithArguments(onion *torgo.Onion, arguments []string) error {
+	if len(arguments) == 0 || len(onion.Ports) == 0 {
+		return c.AddOnion(onion)
+	}
+
+	request := []string{"ADD_ONION", fmt.Sprintf("%s:%s", onion.PrivateKeyType, onion.PrivateKey)}
+	request = append(request, arguments...)
+
+	virtualPorts := make([]int, 0, len(onion.Ports))
+	for p := range onion.Ports {
+		virtualPorts = append(virtualPorts, p)
+	}
+	sort.Ints(virtualPorts)
+
+	for _, p := range virtualPorts {
+		request = append(request, fmt.Sprintf("Port=%d,%s", p, onion.Ports[p]))
+	}
+
+	id, err := c.Text.Cmd("%s", strings.Join(request, " "))
+	if err != nil {
+		return err
+	}
+
+	c.Text.StartResponse(id)
+	defer c.Text.EndResponse(id)
+
+	_, msg, err := c.Text.ReadResponse(250)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "ServiceID":
+			onion.ServiceID = kv[1]
+		case "PrivateKey":
+			key := strings.SplitN(kv[1], ":", 2)
+			if len(key) == 2 {
+				onion.PrivateKeyType, onion.PrivateKey = key[0], key[1]
+			}
+		}
+	}
+
+	return nil
+}
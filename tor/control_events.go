@@ -0,0 +1,134 @@
+package tor
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	controlReplyOK    = "250"
+	controlReplyEvent = "650"
+)
+
+var errInvalidControlReply = errors.New("invalid reply from the Tor control port")
+
+// eventController is a control port connection able to receive the
+// asynchronous events sent by Tor, which torgo doesn't support
+type eventController struct {
+	*controlConnection
+	pending []string
+}
+
+func newEventController(addr string) (*eventController, error) {
+	c, err := newTorgoController(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventController{controlConnection: &controlConnection{c}}, nil
+}
+
+// GetInfo returns the value of the given key, as described in the
+// section 3.9 of the Tor control protocol specification
+func (c *eventController) GetInfo(key string) (string, error) {
+	lines, err := c.command("GETINFO %s", key)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := key + "="
+	for _, l := range lines {
+		if strings.HasPrefix(l, prefix) {
+			return strings.TrimPrefix(l, prefix), nil
+		}
+	}
+
+	return "", errInvalidControlReply
+}
+
+// SetEvents subscribes the connection to the given events,
+// or unsubscribes it from all the events when none is given
+func (c *eventController) SetEvents(events ...string) error {
+	_, err := c.command("%s", strings.TrimSpace("SETEVENTS "+strings.Join(events, " ")))
+	return err
+}
+
+// CloseCircuit makes Tor close the circuit with the given ID
+func (c *eventController) CloseCircuit(id string) error {
+	_, err := c.command("CLOSECIRCUIT %s", id)
+	return err
+}
+
+// TakeOwnership makes Tor exit when this connection is closed
+func (c *eventController) TakeOwnership() error {
+	_, err := c.command("TAKEOWNERSHIP")
+	return err
+}
+
+// ReadEvent blocks until Tor sends an event, and returns its content
+func (c *eventController) ReadEvent() (string, error) {
+	if len(c.pending) > 0 {
+		ev := c.pending[0]
+		c.pending = c.pending[1:]
+		return ev, nil
+	}
+
+	for {
+		line, err := c.Text.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		if len(line) > 4 && line[:3] == controlReplyEvent {
+			return line[4:], nil
+		}
+	}
+}
+
+// command sends the given command and returns the lines of the reply. The
+// events received while waiting for the reply are kept to be read later.
+// The values sent as data, like the ones of circuit-status, are joined
+// to the line that introduces them, one line of the data after another
+func (c *eventController) command(format string, args ...interface{}) ([]string, error) {
+	err := c.Text.PrintfLine(format, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for {
+		line, err := c.Text.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) < 4 {
+			return nil, errInvalidControlReply
+		}
+
+		code, separator, content := line[:3], line[3], line[4:]
+
+		if code == controlReplyEvent {
+			c.pending = append(c.pending, content)
+			continue
+		}
+
+		if code != controlReplyOK {
+			return nil, errors.New(line)
+		}
+
+		if separator == '+' {
+			data, err := c.Text.ReadDotLines()
+			if err != nil {
+				return nil, err
+			}
+			content += strings.Join(data, "\n")
+		}
+
+		result = append(result, content)
+
+		if separator == ' ' {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package tor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	onionGraterProfile = "../packaging/tails/onion-grater-profile.yml"
+	fakeServiceID      = "qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid"
+	fakePrivateKey     = "ED25519-V3:aGVsbG8td29ybGQtaGVsbG8td29ybGQtaGVsbG8td29ybGQtaGVsbG8td29ybGQtaGVsbG8td29ybGQtaGVsbG8td29ybA=="
+)
+
+// fakeControlPortFilter works like onion-grater: it answers the
+// authentication itself, and only allows the commands whose
+// arguments match the patterns of its profile
+type fakeControlPortFilter struct {
+	sync.Mutex
+	allowed  map[string][]*regexp.Regexp
+	received []string
+	refused  []string
+	closed   chan bool
+}
+
+func newFakeControlPortFilter(commands map[string][]string) *fakeControlPortFilter {
+	f := &fakeControlPortFilter{
+		allowed: make(map[string][]*regexp.Regexp),
+		closed:  make(chan bool, 10),
+	}
+
+	for command, patterns := range commands {
+		for _, p := range patterns {
+			f.allowed[command] = append(f.allowed[command], regexp.MustCompile("^(?:"+p+")$"))
+		}
+	}
+
+	return f
+}
+
+func readOnionGraterProfile(c *C) map[string][]string {
+	content, err := ioutil.ReadFile(onionGraterProfile)
+	c.Assert(err, IsNil)
+
+	profile := []struct {
+		Commands map[string][]string `yaml:"commands"`
+	}{}
+	c.Assert(yaml.Unmarshal(content, &profile), IsNil)
+	c.Assert(profile, HasLen, 1)
+
+	return profile[0].Commands
+}
+
+func (f *fakeControlPortFilter) listen(c *C) string {
+	path := filepath.Join(c.MkDir(), controlSocketName)
+
+	l, err := net.Listen("unix", path)
+	c.Assert(err, IsNil)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return UnixSocketAddress(path)
+}
+
+func (f *fakeControlPortFilter) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+		f.closed <- true
+	}()
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fmt.Fprint(conn, f.reply(strings.TrimSpace(line)))
+	}
+}
+
+func (f *fakeControlPortFilter) reply(line string) string {
+	command, arguments := line, ""
+	if i := strings.Index(line, " "); i != -1 {
+		command, arguments = line[:i], line[i+1:]
+	}
+
+	switch command {
+	case "PROTOCOLINFO":
+		return "250-PROTOCOLINFO 1\r\n250-AUTH METHODS=NULL\r\n250-VERSION Tor=\"0.4.8.9\"\r\n250 OK\r\n"
+	case "AUTHENTICATE":
+		return "250 OK\r\n"
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.received = append(f.received, line)
+	if !f.allows(command, arguments) {
+		f.refused = append(f.refused, line)
+		return "510 Command filtered\r\n"
+	}
+
+	switch command {
+	case "GETINFO":
+		if arguments == "version" {
+			return "250-version=0.4.8.9\r\n250 OK\r\n"
+		}
+		return "250-" + arguments + "=\r\n250 OK\r\n"
+	case "ADD_ONION":
+		return "250-ServiceID=" + fakeServiceID + "\r\n250-PrivateKey=" + fakePrivateKey + "\r\n250 OK\r\n"
+	}
+	return "250 OK\r\n"
+}
+
+func (f *fakeControlPortFilter) allows(command, arguments string) bool {
+	for _, p := range f.allowed[command] {
+		if p.MatchString(arguments) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WahayTorSuite) Test_onionGraterProfile_allowsTheCommandsSentToHostMeetings(c *C) {
+	log.SetOutput(ioutil.Discard)
+
+	f := newFakeControlPortFilter(readOnionGraterProfile(c))
+	cntrl := createController(f.listen(c))
+
+	ports := []OnionPort{
+		{ServicePort: 64738, DestinationHost: "127.0.0.1", DestinationPort: 34567},
+		{ServicePort: 8181, DestinationHost: "127.0.0.1", DestinationPort: 34568},
+		{ServicePort: 8182, DestinationHost: "127.0.0.1", DestinationPort: 34569},
+	}
+	options := OnionOptions{
+		MaxStreams:             20,
+		MaxStreamsCloseCircuit: true,
+		ProofOfWork:            true,
+		PoWQueueRate:           100,
+		PoWQueueBurst:          200,
+	}
+
+	serviceID, key, err := cntrl.CreateOnionServiceWithKey(ports, options, "")
+	c.Assert(err, IsNil)
+	c.Assert(serviceID, Equals, fakeServiceID+".onion")
+
+	_, _, err = cntrl.CreateOnionServiceWithKey(ports, OnionOptions{}, key)
+	c.Assert(err, IsNil)
+
+	c.Assert(cntrl.DeleteOnionService(serviceID), IsNil)
+
+	c.Assert(f.received, HasLen, 4)
+	c.Assert(f.refused, HasLen, 0)
+}
+
+// allOnionOptions returns every combination of the options
+// an onion service can be added with
+func allOnionOptions() []OnionOptions {
+	result := []OnionOptions{}
+	for _, nonAnonymous := range []bool{false, true} {
+		for _, maxStreams := range []int{0, 20} {
+			for _, closeCircuit := range []bool{false, true} {
+				for _, proofOfWork := range []bool{false, true} {
+					for _, queue := range []int{0, 100} {
+						result = append(result, OnionOptions{
+							NonAnonymous:           nonAnonymous,
+							MaxStreams:             maxStreams,
+							MaxStreamsCloseCircuit: closeCircuit,
+							ProofOfWork:            proofOfWork,
+							PoWQueueRate:           queue,
+							PoWQueueBurst:          queue * 2,
+						})
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+func (s *WahayTorSuite) Test_onionGraterProfile_allowsEveryCombinationOfOnionOptions(c *C) {
+	f := newFakeControlPortFilter(readOnionGraterProfile(c))
+
+	keys := []string{"NEW:" + onionKeyType, fakePrivateKey}
+	ports := "Port=64738,127.0.0.1:34567 Port=8181,127.0.0.1:34568"
+
+	for _, o := range allOnionOptions() {
+		for _, withProofOfWork := range []bool{false, true} {
+			for _, k := range keys {
+				arguments := append(append([]string{k}, o.arguments(withProofOfWork)...), ports)
+				line := strings.Join(arguments, " ")
+
+				c.Check(f.allows("ADD_ONION", line), Equals, true, Commentf("ADD_ONION %s", line))
+			}
+		}
+	}
+}
+
+func (s *WahayTorSuite) Test_onionGraterProfile_allowsTheCommandsSentToDiagnoseTheCircuits(c *C) {
+	log.SetOutput(ioutil.Discard)
+
+	f := newFakeControlPortFilter(readOnionGraterProfile(c))
+	addr := f.listen(c)
+
+	d, err := newCircuitDiagnostics(fakeMeetingID, func() (torgoEventController, error) {
+		ec, err := newEventController(addr)
+		if err != nil {
+			return nil, err
+		}
+		return ec, ec.AuthenticateNone()
+	}, nil)
+	c.Assert(err, IsNil)
+
+	d.Lock()
+	d.circuits["5"] = &Circuit{ID: "5", Status: CircuitBuilt}
+	d.meeting["5"] = true
+	d.Unlock()
+
+	c.Assert(d.NewCircuits(), IsNil)
+	d.Close()
+
+	f.Lock()
+	defer f.Unlock()
+
+	c.Assert(f.received, DeepEquals, []string{
+		"SETEVENTS CIRC STREAM",
+		"GETINFO circuit-status",
+		"GETINFO stream-status",
+		"CLOSECIRCUIT 5",
+	})
+	c.Assert(f.refused, HasLen, 0)
+}
+
+func (s *WahayTorSuite) Test_controller_adaptsToAControlPortFilterRefusingTheOptionalCommands(c *C) {
+	log.SetOutput(ioutil.Discard)
+
+	f := newFakeControlPortFilter(map[string][]string{
+		"ADD_ONION": {`NEW:ED25519-V3( Port=\d+,127\.0\.0\.1:\d+)+`},
+	})
+	cntrl := createController(f.listen(c))
+
+	ports := []OnionPort{{ServicePort: 64738, DestinationHost: "127.0.0.1", DestinationPort: 34567}}
+	options := OnionOptions{MaxStreams: 20, MaxStreamsCloseCircuit: true, ProofOfWork: true}
+
+	serviceID, err := cntrl.CreateNewOnionServiceWithMultiplePorts(ports, options)
+	c.Assert(err, IsNil)
+	c.Assert(serviceID, Equals, fakeServiceID+".onion")
+
+	c.Assert(f.refused, DeepEquals, []string{
+		"GETINFO version",
+		"ADD_ONION NEW:ED25519-V3 Flags=MaxStreamsCloseCircuit MaxStreams=20 Port=64738,127.0.0.1:34567",
+	})
+
+	c.Assert(cntrl.DeleteOnionService(serviceID), IsNil)
+
+	_, err = cntrl.CreateNewOnionServiceWithMultiplePorts(ports, options)
+	c.Assert(err, IsNil)
+
+	cntrl.DeleteOnionServices()
+
+	c.Assert(f.refused, HasLen, 3)
+	c.Assert(f.refused[2], Equals, "DEL_ONION "+fakeServiceID)
+	c.Assert(f.received[len(f.received)-1], Equals, "ADD_ONION NEW:ED25519-V3 Port=64738,127.0.0.1:34567")
+	c.Assert(cntrl.onions, HasLen, 0)
+
+	// The onion services are removed by closing the connections that added them
+	<-f.closed
+	<-f.closed
+}
+
+func (s *WahayTorSuite) Test_controller_DeleteOnionService_keepsTheConnectionWhenOtherOnionServicesWouldBeRemoved(c *C) {
+	cm := &controllerMock{deleteOnionReturnError: &textproto.Error{Code: 510, Msg: "Command filtered"}}
+	cntrl := &controller{c: cm, onions: []string{"one.onion", "two.onion"}}
+
+	c.Assert(cntrl.DeleteOnionService("one.onion"), Equals, errDeleteOnionFiltered)
+	c.Assert(cm.closeCalled, Equals, false)
+	c.Assert(cntrl.onions, HasLen, 2)
+
+	cntrl.DeleteOnionServices()
+
+	c.Assert(cm.closeCalled, Equals, true)
+	c.Assert(cntrl.c, IsNil)
+	c.Assert(cntrl.onions, HasLen, 0)
+}
+
+func (s *WahayTorSuite) Test_isCommandFiltered_onlyAcceptsTheRepliesOfFilters(c *C) {
+	c.Assert(isCommandFiltered(&textproto.Error{Code: 510, Msg: "Command filtered"}), Equals, true)
+	c.Assert(isCommandFiltered(&textproto.Error{Code: 510, Msg: "Prohibited command"}), Equals, true)
+	c.Assert(isCommandFiltered(&textproto.Error{Code: 510, Msg: "Unrecognized command \"FOO\""}), Equals, false)
+	c.Assert(isCommandFiltered(&textproto.Error{Code: 552, Msg: "Unrecognized option"}), Equals, false)
+	c.Assert(isCommandFiltered(errors.New("510 Command filtered")), Equals, false)
+	c.Assert(isCommandFiltered(nil), Equals, false)
+}
+
+func (s *WahayTorSuite) Test_connectivity_checkControlPortVersion_acceptsAFilteredVersion(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	tc := &mockTorgoController{getVersionReturn2: &textproto.Error{Code: 510, Msg: "Command filtered"}}
+	mocktorgof.newControllerReturn1 = tc
+
+	ch := &connectivity{controlAddress: "127.0.0.1:951", authType: "none"}
+
+	c.Assert(ch.checkControlPortVersion(), Equals, true)
+	c.Assert(tc.getVersionCalled, Equals, 1)
+}
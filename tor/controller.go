@@ -19,36 +19,52 @@ import (
 type Control interface {
 	SetPassword(string)
 	UseCookieAuth()
-	CreateNewOnionServiceWithMultiplePorts(ports []OnionPort) (serviceID string, err error)
+	UseCookieFile(string)
+	CreateNewOnionServiceWithMultiplePorts(ports []OnionPort, options OnionOptions) (serviceID string, err error)
 	CreateNewOnionService(destinationHost string, destinationPort int, port int) (serviceID string, err error)
+	CreateOnionServiceWithKey(ports []OnionPort, options OnionOptions, privateKey string) (serviceID string, key string, err error)
 	DeleteOnionService(serviceID string) error
 	DeleteOnionServices()
 }
 
 type controller struct {
-	torHost  string
-	torPort  int
+	torAddr  string
 	authType *authenticationMethod
 	password string
 	c        torgoController
 	tc       func(string) (torgoController, error)
+	// filtered contains the commands that a filter in front of the
+	// control port refused, so they are not sent again
+	filtered map[string]bool
+	// onions are the onion services added through this controller
+	onions []string
+	// state, when given, keeps a record of the onion services in a file,
+	// so they can be removed even if Wahay crashes before doing it
+	state *onionState
 }
 
-// TODO[OB] - I'm not a huge fan of this being global
-// Is there any reason why this is not on the controller?
+// The commands, or forms of them, that a filter in front of the control port
+// can refuse and we can do without
+const (
+	filteredVersion      = "GETINFO version"
+	filteredOnionOptions = "ADD_ONION options"
+	filteredDeleteOnion  = "DEL_ONION"
+)
 
-var onions = []string{}
+var (
+	errNoControlConnection = errors.New("there is no connection to the Tor control port")
+	errDeleteOnionFiltered = errors.New("the control port filter doesn't allow removing the onion service")
+)
 
-// createController takes the Tor information given
-// and returns a controlling interface
-func createController(torHost string, torPort int) Control {
+// createController takes the address of the Tor control
+// port given and returns a controlling interface
+func createController(torAddr string) *controller {
 	f := torgof.NewController
 
 	var a authenticationMethod = authenticateNone
 
 	return &controller{
-		torHost:  torHost,
-		torPort:  torPort,
+		torAddr:  torAddr,
 		authType: &a,
 		tc:       f,
 		c:        nil,
@@ -68,38 +84,66 @@ func (cntrl *controller) UseCookieAuth() {
 	cntrl.authType = &a
 }
 
+// UseCookieFile makes the controller authenticate with the cookie at
+// the given path, instead of the one reported by Tor
+func (cntrl *controller) UseCookieFile(path string) {
+	var a authenticationMethod = authenticateCookieFile(path)
+	cntrl.authType = &a
+}
+
 // OnionPort is a representation of the information to create a hidde
-// service with support for multiple destination ports
+// service with support for multiple destination ports. When a
+// DestinationSocket is given, the connections are sent to the Unix
+// domain socket at that path instead of the destination host and port
 type OnionPort struct {
-	ServicePort     int
-	DestinationPort int
-	DestinationHost string
+	ServicePort       int
+	DestinationPort   int
+	DestinationHost   string
+	DestinationSocket string
 }
 
+func (p OnionPort) target() string {
+	if p.DestinationSocket != "" {
+		return UnixSocketAddress(p.DestinationSocket)
+	}
+	return net.JoinHostPort(p.DestinationHost, strconv.Itoa(p.DestinationPort))
+}
+
+func (p OnionPort) isValid() bool {
+	if !config.CheckPort(p.ServicePort) {
+		return false
+	}
+	return p.DestinationSocket != "" || config.CheckPort(p.DestinationPort)
+}
+
+// onionKeyType is the type of the keys of version 3 onion services
+const onionKeyType = "ED25519-V3"
+
 // TODO[OB] - It seems this would be nicer if there was just one interface
 // method, and then it could take variable number of arguments
 
-func (cntrl *controller) CreateNewOnionServiceWithMultiplePorts(ports []OnionPort) (serviceID string, err error) {
-	log.Debugf("CreateNewOnionServiceWithMultiplePorts(%v)", ports)
-	tc, err := cntrl.getTorController()
+func (cntrl *controller) CreateNewOnionServiceWithMultiplePorts(ports []OnionPort, options OnionOptions) (serviceID string, err error) {
+	log.Debugf("CreateNewOnionServiceWithMultiplePorts(%v, %v)", ports, options)
+	serviceID, _, err = cntrl.CreateOnionServiceWithKey(ports, options, "")
+	return
+}
+
+// CreateOnionServiceWithKey creates an onion service using the given private key,
+// so it gets the same address it had before. When no key is given a new one is
+// generated. The key of the onion service is returned to be able to restore it later
+func (cntrl *controller) CreateOnionServiceWithKey(ports []OnionPort, options OnionOptions, privateKey string) (serviceID string, key string, err error) {
+	log.Debug("CreateOnionServiceWithKey() - authenticating")
+	tc, err := cntrl.connect()
 	if err != nil {
 		return
 	}
 
-	log.Debug("CreateNewOnionServiceWithMultiplePorts() - authenticating")
-	if cntrl.authType != nil {
-		err = (*cntrl.authType)(tc)
-		if err != nil {
-			return
-		}
-	}
-
 	invalidPorts := []string{}
 	finalPorts := make(map[int]string)
 	for _, p := range ports {
-		host := net.JoinHostPort(p.DestinationHost, strconv.Itoa(p.DestinationPort))
+		host := p.target()
 
-		if !config.CheckPort(p.ServicePort) || !config.CheckPort(p.DestinationPort) {
+		if !p.isValid() {
 			invalidPorts = append(invalidPorts, host)
 			continue
 		}
@@ -108,26 +152,129 @@ func (cntrl *controller) CreateNewOnionServiceWithMultiplePorts(ports []OnionPor
 	}
 
 	if len(finalPorts) == 0 {
-		return "", errors.New("invalid source port")
+		return "", "", errors.New("invalid source port")
 	} else if len(invalidPorts) > 0 {
-		return "", fmt.Errorf("some ports are invalid: %v", invalidPorts)
+		return "", "", fmt.Errorf("some ports are invalid: %v", invalidPorts)
 	}
 
 	onion := &torgo.Onion{
 		Ports:          finalPorts,
 		PrivateKeyType: "NEW",
-		PrivateKey:     "ED25519-V3",
+		PrivateKey:     onionKeyType,
+	}
+
+	if len(privateKey) != 0 {
+		onion.PrivateKeyType = onionKeyType
+		onion.PrivateKey = privateKey
 	}
 
-	err = tc.AddOnion(onion)
+	err = cntrl.addOnion(tc, onion, options)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	serviceID = fmt.Sprintf("%s.onion", onion.ServiceID)
-	onions = append(onions, serviceID)
+	cntrl.rememberOnion(serviceID)
+
+	key = privateKey
+	if onion.PrivateKeyType == onionKeyType {
+		key = onion.PrivateKey
+	}
+
+	return serviceID, key, nil
+}
+
+// addOnion adds the onion service with the given options. The proof-of-work
+// defenses are only requested when the Tor version supports them, and
+// they are left out when Tor was built without them. When a filter in
+// front of the control port doesn't allow the options, the onion
+// service is added without them
+func (cntrl *controller) addOnion(tc torgoController, onion *torgo.Onion, options OnionOptions) error {
+	if cntrl.isFiltered(filteredOnionOptions) {
+		return tc.AddOnionWithArguments(onion, nil)
+	}
+
+	withProofOfWork := false
+	if options.ProofOfWork && !cntrl.isFiltered(filteredVersion) {
+		v, err := tc.GetVersion()
+		cntrl.checkFiltered(filteredVersion, err)
+		withProofOfWork = err == nil && supportsProofOfWork(v)
+	}
+
+	err := tc.AddOnionWithArguments(onion, options.arguments(withProofOfWork))
+	if err != nil && withProofOfWork {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("addOnion(): the onion service can't be added with the proof-of-work defenses")
+		err = tc.AddOnionWithArguments(onion, options.arguments(false))
+	}
+
+	if len(options.arguments(false)) > 0 && cntrl.checkFiltered(filteredOnionOptions, err) {
+		log.Warn("addOnion(): the control port filter doesn't allow the onion service options, adding it without them")
+		err = tc.AddOnionWithArguments(onion, nil)
+	}
+
+	return err
+}
+
+func (cntrl *controller) isFiltered(command string) bool {
+	return cntrl.filtered[command]
+}
+
+// checkFiltered returns a boolean indicating if the error is the refusal of
+// a filter in front of the control port, remembering it for the command
+func (cntrl *controller) checkFiltered(command string, err error) bool {
+	if !isCommandFiltered(err) {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"command": command,
+	}).Info("The Tor control port is filtered, the command won't be used again")
+
+	if cntrl.filtered == nil {
+		cntrl.filtered = make(map[string]bool)
+	}
+	cntrl.filtered[command] = true
+
+	return true
+}
+
+func (cntrl *controller) isKnownOnion(serviceID string) bool {
+	for _, o := range cntrl.onions {
+		if o == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (cntrl *controller) rememberOnion(serviceID string) {
+	if !cntrl.isKnownOnion(serviceID) {
+		cntrl.onions = append(cntrl.onions, serviceID)
+		cntrl.saveState()
+	}
+}
+
+func (cntrl *controller) forgetOnion(serviceID string) {
+	for ix, o := range cntrl.onions {
+		if o == serviceID {
+			cntrl.onions = append(cntrl.onions[:ix], cntrl.onions[ix+1:]...)
+			cntrl.saveState()
+			return
+		}
+	}
+}
 
-	return serviceID, nil
+func (cntrl *controller) forgetOnions() {
+	cntrl.onions = nil
+	cntrl.saveState()
+}
+
+func (cntrl *controller) saveState() {
+	if cntrl.state != nil {
+		cntrl.state.set(cntrl.torAddr, cntrl.onions)
+	}
 }
 
 func (cntrl *controller) CreateNewOnionService(destinationHost string, destinationPort int,
@@ -137,35 +284,119 @@ func (cntrl *controller) CreateNewOnionService(destinationHost string, destinati
 		DestinationPort: destinationPort,
 		DestinationHost: destinationHost,
 	}
-	return cntrl.CreateNewOnionServiceWithMultiplePorts([]OnionPort{p})
+	return cntrl.CreateNewOnionServiceWithMultiplePorts([]OnionPort{p}, OnionOptions{})
 }
 
 func (cntrl *controller) DeleteOnionService(serviceID string) error {
-	s := strings.TrimSuffix(serviceID, ".onion")
-	err := cntrl.c.DeleteOnion(s)
+	if cntrl.c == nil {
+		return errNoControlConnection
+	}
+
+	err := cntrl.deleteOnion(serviceID)
 	if err != nil {
 		return err
 	}
 
-	// TODO[OB] - In order to avoid this messy code, it might be
-	// easier to make the onions variable a map instead of a list.
+	cntrl.forgetOnion(serviceID)
+
+	return nil
+}
 
-	for i := range onions {
-		if onions[i] == serviceID {
-			onions[i] = onions[len(onions)-1]
-			onions[len(onions)-1] = ""
-			onions = onions[:len(onions)-1]
-			break
+// deleteOnion removes the onion service from Tor. When a filter in front of
+// the control port doesn't allow it, the connection is closed instead, since
+// Tor removes the onion services that are not detached from the connection
+// that added them. That can only be done for the last onion service
+func (cntrl *controller) deleteOnion(serviceID string) error {
+	if !cntrl.isFiltered(filteredDeleteOnion) {
+		err := cntrl.c.DeleteOnion(strings.TrimSuffix(serviceID, ".onion"))
+		if !cntrl.checkFiltered(filteredDeleteOnion, err) {
+			return err
 		}
 	}
 
-	return nil
+	if len(cntrl.onions) > 1 {
+		return errDeleteOnionFiltered
+	}
+
+	return cntrl.disconnect()
 }
 
+// DeleteOnionServices removes the onion services added through this controller
 func (cntrl *controller) DeleteOnionServices() {
-	for _, o := range onions {
+	for _, o := range append([]string{}, cntrl.onions...) {
 		_ = cntrl.DeleteOnionService(o)
 	}
+
+	if len(cntrl.onions) > 0 && cntrl.isFiltered(filteredDeleteOnion) {
+		_ = cntrl.disconnect()
+		cntrl.forgetOnions()
+	}
+}
+
+// removeLeftoverOnionServices removes the onion services that the state
+// records for this Tor instance, which were added by executions of Wahay
+// that are not running anymore. Only those are removed, so the onion
+// services of other applications, and of other executions of Wahay
+// using the same Tor instance, are left alone
+func (cntrl *controller) removeLeftoverOnionServices() {
+	if cntrl.state == nil {
+		return
+	}
+
+	leftovers := cntrl.state.leftoversOf(cntrl.torAddr)
+	if len(leftovers) == 0 {
+		return
+	}
+
+	tc, err := cntrl.connect()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("removeLeftoverOnionServices(): can't connect to the Tor control port")
+		return
+	}
+
+	for _, o := range leftovers {
+		// Tor already removed the onion services that were not detached
+		// from the connection that added them, so errors are expected
+		err := tc.DeleteOnion(strings.TrimSuffix(o, ".onion"))
+		log.WithFields(log.Fields{
+			"onion": o,
+			"error": err,
+		}).Debug("removeLeftoverOnionServices(): removing onion service left by a previous execution")
+	}
+
+	cntrl.state.forgetLeftoversOf(cntrl.torAddr)
+}
+
+// connect returns the connection to the control port, authenticated
+func (cntrl *controller) connect() (torgoController, error) {
+	tc, err := cntrl.getTorController()
+	if err != nil {
+		return nil, err
+	}
+
+	if cntrl.authType != nil {
+		err = (*cntrl.authType)(tc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tc, nil
+}
+
+// disconnect closes the connection to the control port,
+// a new one is opened when it's needed again
+func (cntrl *controller) disconnect() error {
+	if cntrl.c == nil {
+		return nil
+	}
+
+	err := cntrl.c.Close()
+	cntrl.c = nil
+
+	return err
 }
 
 func (cntrl *controller) getTorController() (torgoController, error) {
@@ -173,7 +404,7 @@ func (cntrl *controller) getTorController() (torgoController, error) {
 		return cntrl.c, nil
 	}
 
-	c, err := cntrl.tc(net.JoinHostPort(cntrl.torHost, strconv.Itoa(cntrl.torPort)))
+	c, err := cntrl.tc(cntrl.torAddr)
 	if err != nil {
 		return nil, err
 	}
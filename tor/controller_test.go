@@ -21,13 +21,15 @@ type controllerMock struct {
 	authenticatePasswordCalled bool
 	authenticatePasswordReturn error
 
-	authenticateCookieCalled bool
-	authenticateCookieReturn error
+	authenticateCookieCalled  bool
+	authenticateCookieReturn  error
+	authenticateCookieFileArg string
 
 	authenticateNoneCalled bool
 	authenticateNoneReturn error
 
 	addOnionArg1           *torgo.Onion
+	addOnionArguments      [][]string
 	addOnionCalled         bool
 	addOnionReturnError    error
 	addOnionAddServiceInfo string
@@ -38,6 +40,8 @@ type controllerMock struct {
 
 	getVersionReturn1 string
 	getVersionReturn2 error
+
+	closeCalled bool
 }
 
 func (m *controllerMock) AuthenticateNone() error {
@@ -50,6 +54,11 @@ func (m *controllerMock) AuthenticateCookie() error {
 	return m.authenticateCookieReturn
 }
 
+func (m *controllerMock) AuthenticateCookieFile(path string) error {
+	m.authenticateCookieFileArg = path
+	return m.AuthenticateCookie()
+}
+
 func (m *controllerMock) AuthenticatePassword(v1 string) error {
 	m.authenticatePasswordArg1 = v1
 	m.authenticatePasswordCalled = true
@@ -65,6 +74,11 @@ func (m *controllerMock) AddOnion(v1 *torgo.Onion) error {
 	return m.addOnionReturnError
 }
 
+func (m *controllerMock) AddOnionWithArguments(v1 *torgo.Onion, args []string) error {
+	m.addOnionArguments = append(m.addOnionArguments, args)
+	return m.AddOnion(v1)
+}
+
 func (m *controllerMock) GetVersion() (string, error) {
 	return m.getVersionReturn1, m.getVersionReturn2
 }
@@ -77,6 +91,11 @@ func (m *controllerMock) DeleteOnion(serviceID string) error {
 	return m.deleteOnionReturnError
 }
 
+func (m *controllerMock) Close() error {
+	m.closeCalled = true
+	return nil
+}
+
 func (m *controllerMock) createTestGotor(addr string) (torgoController, error) {
 	return m, nil
 }
@@ -87,8 +106,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_returnsErrorIfAuth
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -105,8 +123,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_authenticatesWithG
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -120,8 +137,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_authenticatesWithG
 
 func (s *WahayTorSuite) Test_controller_CreateNewOnionService_returnsErrorIfTorControllerCantBeCreated(c *C) {
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: "11112222",
 		tc: func(string) (torgoController, error) {
 			return nil, errors.New("couldn't create torgocontroller")
@@ -136,8 +152,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_returnsErrorIfTorC
 func (s *WahayTorSuite) Test_controller_CreateNewOnionService_triesToCreateTorControllerWithGivenAddress(c *C) {
 	var addrGiven *string
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: "11112222",
 		tc: func(v string) (torgoController, error) {
 			addrGiven = &v
@@ -157,8 +172,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_returnsErrorIfAddO
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -175,8 +189,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_createsOnionWithGi
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -199,8 +212,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_signalsErrorForInv
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -222,8 +234,7 @@ func (s *WahayTorSuite) Test_controller_CreateNewOnionService_returnsTheServiceI
 
 	var a authenticationMethod = authenticatePassword(passw)
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: passw,
 		authType: &a,
 		tc:       mock.createTestGotor,
@@ -240,8 +251,7 @@ func (s *WahayTorSuite) Test_controller_DeleteOnionService_returnsErrorIfService
 	mock.deleteOnionReturnError = errors.New("the service ID cannot be empty")
 
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: "11112223",
 		tc:       mock.createTestGotor,
 	}
@@ -258,8 +268,7 @@ func (s *WahayTorSuite) Test_controller_DeleteOnionService_returnsErrorIfFails(c
 	mock.deleteOnionReturnError = errors.New("service deletion error")
 
 	cntrl := &controller{
-		torHost:  "127.1.2.3",
-		torPort:  9052,
+		torAddr:  "127.1.2.3:9052",
 		password: "11112223",
 		tc:       mock.createTestGotor,
 	}
@@ -4,17 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
-	"net"
 	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
+	"runtime"
 
 	"github.com/digitalautonomy/wahay/config"
 	localExec "github.com/digitalautonomy/wahay/exec"
-	"github.com/wybiral/torgo"
 	"golang.org/x/net/proxy"
 )
 
@@ -36,6 +33,10 @@ type osFacade interface {
 	Stderr() *os.File
 	IsPortAvailable(port int) bool
 	GetRandomPort() int
+	Getpid() int
+	IsProcessRunning(pid int) bool
+	SupportsUnixSockets() bool
+	ShortPathName(string) (string, error)
 }
 
 type filepathFacade interface {
@@ -56,15 +57,19 @@ type filesystemFacade interface {
 	TempDir(suffix string) string
 	EnsureDir(string, os.FileMode)
 	WriteFile(string, []byte, os.FileMode) error
+	ReadFile(string) ([]byte, error)
+	AcquireTorCache() bool
+	ReleaseTorCache()
 }
 
 type torgoFacade interface {
 	NewController(string) (torgoController, error)
+	NewEventController(string) (torgoEventController, error)
 }
 
 type httpFacade interface {
-	CheckConnectionOverTor(host string, port int) bool
-	HTTPRequest(host string, port int, url string) (string, error)
+	CheckConnectionOverTor(proxyAddress string) bool
+	HTTPRequest(proxyAddress string, url string) (string, error)
 }
 
 var osf osFacade
@@ -89,6 +94,10 @@ func init() {
 
 type realOsImplementation struct{}
 
+func (*realOsImplementation) Getpid() int {
+	return os.Getpid()
+}
+
 func (*realOsImplementation) Getenv(key string) string {
 	return os.Getenv(key)
 }
@@ -129,6 +138,18 @@ func (*realOsImplementation) GetRandomPort() int {
 	return config.GetRandomPort()
 }
 
+func (*realOsImplementation) IsProcessRunning(pid int) bool {
+	return config.IsProcessRunning(pid)
+}
+
+func (*realOsImplementation) SupportsUnixSockets() bool {
+	return runtime.GOOS != "windows"
+}
+
+func (*realOsImplementation) ShortPathName(path string) (string, error) {
+	return shortPathName(path)
+}
+
 type realFilepathImplementation struct{}
 
 func (*realFilepathImplementation) Glob(p string) ([]string, error) {
@@ -193,28 +214,54 @@ func (*realFilesystemImplementation) WriteFile(name string, content []byte, mode
 	return ioutil.WriteFile(name, content, mode)
 }
 
+func (*realFilesystemImplementation) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Clean(name))
+}
+
+func (*realFilesystemImplementation) AcquireTorCache() bool {
+	return config.AcquireTorCache()
+}
+
+func (*realFilesystemImplementation) ReleaseTorCache() {
+	config.ReleaseTorCache()
+}
+
 type realTorgoImplementation struct{}
 
 func (*realTorgoImplementation) NewController(a string) (torgoController, error) {
-	return torgo.NewController(a)
+	c, err := newTorgoController(a)
+	if err != nil {
+		return nil, err
+	}
+	return &controlConnection{c}, nil
+}
+
+func (*realTorgoImplementation) NewEventController(a string) (torgoEventController, error) {
+	return newEventController(a)
 }
 
 type realHTTPImplementation struct{}
 
-func (*realHTTPImplementation) CheckConnectionOverTor(host string, port int) bool {
-	proxyURL, err := url.Parse("socks5://" + net.JoinHostPort(host, strconv.Itoa(port)))
+// httpClientOverTor returns an HTTP client that makes its
+// connections through the Tor SOCKS proxy at the given address
+func httpClientOverTor(proxyAddress string) (*http.Client, error) {
+	network, address := SplitAddress(proxyAddress)
+
+	dialer, err := proxy.SOCKS5(network, address, nil, proxy.Direct)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+	t := &http.Transport{Dial: dialer.Dial}
+	return &http.Client{Transport: t}, nil
+}
+
+func (*realHTTPImplementation) CheckConnectionOverTor(proxyAddress string) bool {
+	client, err := httpClientOverTor(proxyAddress)
 	if err != nil {
 		return false
 	}
 
-	t := &http.Transport{Dial: dialer.Dial}
-	client := &http.Client{Transport: t}
-
 	resp, err := client.Get("https://check.torproject.org/api/ip")
 	if err != nil {
 		return false
@@ -231,20 +278,12 @@ func (*realHTTPImplementation) CheckConnectionOverTor(host string, port int) boo
 	return v.IsTor
 }
 
-func (*realHTTPImplementation) HTTPRequest(host string, port int, u string) (string, error) {
-	proxyURL, err := url.Parse("socks5://" + net.JoinHostPort(host, strconv.Itoa(port)))
+func (*realHTTPImplementation) HTTPRequest(proxyAddress string, u string) (string, error) {
+	client, err := httpClientOverTor(proxyAddress)
 	if err != nil {
 		return "", err
 	}
 
-	dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
-	if err != nil {
-		return "", err
-	}
-
-	t := &http.Transport{Dial: dialer.Dial}
-	client := &http.Client{Transport: t}
-
 	resp, err := client.Get(u)
 	if err != nil {
 		return "", err
@@ -12,10 +12,12 @@ var _ = Suite(&torSuite{})
 func (s *torSuite) Test_getTorrc_returnsTheContentLikeAString(c *C) {
 	content := getTorrc()
 
-	c.Assert(content, HasLen, 558)
+	c.Assert(content, HasLen, 747)
 	c.Assert(content, Contains, "SOCKSPort __PORT__")
 	c.Assert(content, Contains, "DataDirectory __DATADIR__")
 	c.Assert(content, Contains, "CookieAuthentication __COOKIE__")
+	c.Assert(content, Contains, "CookieAuthFile __COOKIEFILE__")
+	c.Assert(content, Contains, "__OwningControllerProcess __PID__")
 }
 
 func (s *torSuite) Test_getTorrcLogConfig_returnsTheLogsContentLikeAString(c *C) {
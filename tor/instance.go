@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os/exec"
 	"path/filepath"
 	"strconv"
@@ -20,8 +21,9 @@ import (
 const (
 	torConfigName      = "torrc"
 	torConfigData      = "data"
-	defaultSocksPort   = 9050
-	defaultControlHost = "127.0.0.1"
+	torCookieFileName  = "control_auth_cookie"
+	defaultSocksPort   = config.DefaultRoutePort
+	defaultControlHost = config.DefaultHost
 )
 
 var defaultControlPorts = [2]int{9051, 951}
@@ -33,7 +35,11 @@ type Instance interface {
 	GetController() Control
 	HTTPrequest(url string) (string, error)
 	NewService(string, []string, ModifyCommand) (Service, error)
-	NewOnionServiceWithMultiplePorts([]OnionPort) (Onion, error)
+	NewOnionServiceWithMultiplePorts([]OnionPort, OnionOptions) (Onion, error)
+	SocksAddress() string
+	UsesUnixSockets() bool
+	OnRestart(func(Instance))
+	DiagnoseCircuits(meetingID string, onChange func()) (CircuitDiagnostics, error)
 }
 
 type instance struct {
@@ -43,15 +49,29 @@ type instance struct {
 	socksPort       int
 	controlHost     string
 	controlPort     int
+	controlSocket   string
+	socksSocket     string
 	dataDirectory   string
 	password        string
 	useCookie       bool
+	cookieFile      string
 	isLocal         bool
 	enableLogs      bool
+	bridges         []*Bridge
+	plugins         map[string]string
+	torrcLines      []string
+	proxy           *config.TorProxy
+	singleHop       bool
 	controller      Control
+	onionState      *onionState
+	ownerController torgoEventController
 	runningTor      *runningTor
 	binary          *binary
 	onInitCallbacks []func(Instance)
+
+	onions             []*onion
+	onRestartCallbacks []func(Instance)
+	destroyed          bool
 }
 
 func (i *instance) setBinary(b *binary) {
@@ -77,7 +97,43 @@ func (i *instance) onInit(f func(Instance)) {
 // could be done in the certificate package
 
 func (i *instance) HTTPrequest(u string) (string, error) {
-	return httpf.HTTPRequest(i.controlHost, i.socksPort, u)
+	return httpf.HTTPRequest(i.socksAddress(), u)
+}
+
+// SocksAddress returns the address where the Tor instance accepts SOCKS
+// connections, which can be the path to a Unix domain socket as described
+// in SplitAddress. It can change when our Tor instance is restarted. It's
+// empty for the single-hop instances, which can't be used as clients
+func (i *instance) SocksAddress() string {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.singleHop {
+		return ""
+	}
+
+	return i.socksAddress()
+}
+
+// UsesUnixSockets returns a boolean indicating if the Tor instance
+// can reach onion service targets listening in Unix domain sockets.
+// Only our own Tor instance can, since it runs as our own user
+func (i *instance) UsesUnixSockets() bool {
+	return !i.isLocal && i.controlSocket != ""
+}
+
+func (i *instance) socksAddress() string {
+	if i.socksSocket != "" {
+		return UnixSocketAddress(i.socksSocket)
+	}
+	return net.JoinHostPort(i.controlHost, strconv.Itoa(i.socksPort))
+}
+
+func (i *instance) controlAddress() string {
+	if i.controlSocket != "" {
+		return UnixSocketAddress(i.controlSocket)
+	}
+	return net.JoinHostPort(i.controlHost, strconv.Itoa(i.controlPort))
 }
 
 type runningTor struct {
@@ -87,6 +143,7 @@ type runningTor struct {
 	finished          bool
 	finishedWithError error
 	finishChannel     chan bool
+	exited            chan struct{}
 }
 
 // Onion is a representation of a Tor Onion Service
@@ -96,9 +153,11 @@ type Onion interface {
 }
 
 type onion struct {
-	id    string
-	ports []OnionPort
-	t     Instance
+	id         string
+	ports      []OnionPort
+	options    OnionOptions
+	privateKey string
+	t          *instance
 }
 
 func (s *onion) ID() string {
@@ -106,29 +165,51 @@ func (s *onion) ID() string {
 }
 
 func (s *onion) Delete() error {
+	s.t.forgetOnion(s)
 	c := s.t.GetController()
 	return c.DeleteOnionService(s.id)
 }
 
-// NewOnionServiceWithMultiplePorts creates a new Onion service for the current Tor controller
-func (i *instance) NewOnionServiceWithMultiplePorts(ports []OnionPort) (Onion, error) {
-	log.Debugf("NewOnionServiceWithMultiplePorts(%v)", ports)
+// NewOnionServiceWithMultiplePorts creates a new Onion service for the current
+// Tor controller, protected against connection floods with the given options
+func (i *instance) NewOnionServiceWithMultiplePorts(ports []OnionPort, options OnionOptions) (Onion, error) {
+	log.Debugf("NewOnionServiceWithMultiplePorts(%v, %v)", ports, options)
 	controller := i.GetController()
 
-	serviceID, err := controller.CreateNewOnionServiceWithMultiplePorts(ports)
+	options.NonAnonymous = i.singleHop
+
+	serviceID, key, err := controller.CreateOnionServiceWithKey(ports, options, "")
 	if err != nil {
 		return nil, err
 	}
 
 	s := &onion{
-		id:    serviceID,
-		ports: ports,
-		t:     i,
+		id:         serviceID,
+		ports:      ports,
+		options:    options,
+		privateKey: key,
+		t:          i,
 	}
 
+	i.Lock()
+	defer i.Unlock()
+	i.onions = append(i.onions, s)
+
 	return s, nil
 }
 
+func (i *instance) forgetOnion(o *onion) {
+	i.Lock()
+	defer i.Unlock()
+
+	for ix, s := range i.onions {
+		if s == o {
+			i.onions = append(i.onions[:ix], i.onions[ix+1:]...)
+			return
+		}
+	}
+}
+
 var (
 	// ErrTorBinaryNotFound is an error to be trown when wasn't
 	// possible to find any available or valid Tor binary
@@ -144,8 +225,16 @@ var (
 )
 
 // NewInstance initializes and returns the Instance for working with Tor.
-// This function should be called only once during the system initialization
-func NewInstance(conf *config.ApplicationConfig, onInit func(Instance)) (Instance, error) {
+// This function should be called only once during the system initialization.
+// The given listener will be notified of the progress while our own Tor instance
+// connects to the Tor network
+func NewInstance(conf *config.ApplicationConfig, onInit func(Instance), onBootstrap BootstrapListener) (Instance, error) {
+	// When the user tells us which Tor instance to use, no other is tried
+	if e := conf.TorEndpointToUse(); e != nil {
+		log.Infof("Using the configured Tor instance")
+		return endpointInstance(e)
+	}
+
 	// Checking if the system Tor can be used.
 	// This should work for system like Tails, where Tor is
 	// already available in the system.
@@ -165,7 +254,7 @@ func NewInstance(conf *config.ApplicationConfig, onInit func(Instance)) (Instanc
 
 	log.Infof("Using Tor binary found in: %s", b.path)
 
-	i, err = getOurInstance(b, conf, onInit)
+	i, err = getOurInstanceWithCache(b, conf, onInit, onBootstrap)
 	if err != nil {
 		log.Debugf("tor.NewInstance() error: %s", err)
 		return nil, err
@@ -216,12 +305,94 @@ func systemInstance() (Instance, error) {
 		i.password = *config.TorControlPassword
 	}
 
+	i.trackOnions()
+
+	return i, nil
+}
+
+// endpointInstance returns the Tor instance described by
+// the given endpoint, once it's checked that it can be used
+func endpointInstance(e *config.TorEndpoint) (Instance, error) {
+	i := &instance{
+		started:       true,
+		controlHost:   e.Host,
+		controlPort:   e.ControlPort,
+		controlSocket: e.ControlSocket,
+		socksPort:     e.SocksPort,
+		cookieFile:    e.CookieFile,
+		isLocal:       true,
+	}
+
+	if i.controlHost == "" {
+		i.controlHost = defaultControlHost
+	}
+
+	checker := newEndpointChecker(i.controlAddress(), i.socksAddress(), e)
+
+	authType, total, partial := checker.check()
+	if total != nil || partial != nil {
+		log.Debugf("the configured Tor instance can't be used, because: %v - %v", total, partial)
+		return nil, errorsAny(total, partial)
+	}
+
+	switch authType {
+	case config.TorAuthCookie:
+		i.useCookie = true
+	case config.TorAuthPassword:
+		i.password = e.Password
+	}
+
+	i.trackOnions()
+
 	return i, nil
 }
 
-func getOurInstance(b *binary, conf *config.ApplicationConfig, onInit func(Instance)) (*instance, error) {
-	i, _ := newInstance(conf.IsLogsEnabled())
+// getOurInstance starts a Tor instance with the given binary, keeping its data
+// in the given directory, or in a temporary one when it's empty
+func getOurInstance(b *binary, conf *config.ApplicationConfig, dataDirectory string, onInit func(Instance), onBootstrap BootstrapListener) (*instance, error) {
+	i, _ := newInstance(conf.IsLogsEnabled(), dataDirectory)
+	return startOurInstance(i, b, conf, onInit, onBootstrap)
+}
+
+// getOurInstanceWithCache starts our Tor instance with the Tor cache as its data
+// directory, when the user allows it. Tor doesn't allow two instances to use the
+// same data directory, so a temporary one is used when another execution of
+// Wahay is using the cache, or when Tor can't start with it
+func getOurInstanceWithCache(b *binary, conf *config.ApplicationConfig, onInit func(Instance), onBootstrap BootstrapListener) (*instance, error) {
+	dataDirectory := dataDirectoryFor(conf)
+	if dataDirectory != "" && !filesystemf.AcquireTorCache() {
+		log.Info("The Tor cache is used by another execution of Wahay, so it won't be used this time")
+		dataDirectory = ""
+	}
+
+	i, err := getOurInstance(b, conf, dataDirectory, onInit, onBootstrap)
+	if err == ErrTorInstanceCantStart && dataDirectory != "" {
+		log.Info("Tor can't start with the Tor cache, so it won't be used this time")
+		return getOurInstance(b, conf, "", onInit, onBootstrap)
+	}
+
+	return i, err
+}
+
+// getSingleHopInstance starts a Tor instance with the given binary that only
+// hosts single onion services. It doesn't hide the location of the host, so it
+// has no SOCKS port, and it can't be used to connect to anything through Tor
+func getSingleHopInstance(b *binary, conf *config.ApplicationConfig, onBootstrap BootstrapListener) (*instance, error) {
+	i, _ := newInstance(conf.IsLogsEnabled(), "")
 
+	i.singleHop = true
+	err := i.writeToFile()
+	if err != nil {
+		i.Destroy()
+		return nil, err
+	}
+
+	return startOurInstance(i, b, conf, nil, onBootstrap)
+}
+
+// startOurInstance configures the given instance as the user wants, starts it
+// and waits until it's connected to the Tor network
+func startOurInstance(i *instance, b *binary, conf *config.ApplicationConfig, onInit func(Instance), onBootstrap BootstrapListener) (*instance, error) {
 	if onInit != nil {
 		i.onInit(onInit)
 	}
@@ -229,38 +400,155 @@ func getOurInstance(b *binary, conf *config.ApplicationConfig, onInit func(Insta
 	i.setBinary(b)
 	i.init()
 
-	err := i.Start()
+	err := i.useProxy(conf)
+	if err == nil {
+		err = i.useBridges(conf)
+	}
+	if err == nil {
+		err = i.useTorrcLines(conf)
+	}
 	if err != nil {
+		i.Destroy()
 		return nil, err
 	}
 
-	checker := newCustomChecker(i.controlHost, i.socksPort, i.controlPort)
+	err = i.Start()
+	if err != nil {
+		i.Destroy()
+		return nil, err
+	}
 
-	timeout := time.Now().Add(torStartupTimeout)
-	for {
-		time.Sleep(3 * time.Second)
+	err = i.waitForBootstrap(onBootstrap, torStartupTimeout)
+	if err != nil {
+		i.Destroy()
+		return nil, err
+	}
 
-		_, errTotal, errPartial := checker.check()
-		if errTotal != nil {
-			return nil, errTotal
-		}
+	go i.supervise()
 
-		if time.Now().After(timeout) {
-			return nil, ErrTorConnectionTimeout
-		}
+	// Without a SOCKS port, the connection over Tor can't be checked,
+	// but the bootstrap has already shown that Tor is connected
+	if i.singleHop {
+		return i, nil
+	}
 
-		if errPartial == nil {
-			return i, nil
-		}
+	checker := newCustomChecker(i.controlAddress(), i.socksAddress())
 
+	_, errTotal, errPartial := checker.check()
+	if errTotal != nil {
+		i.Destroy()
+		return nil, errTotal
+	}
+
+	if errPartial != nil {
 		log.WithFields(log.Fields{
 			"time": time.Now(),
 		}).Error(fmt.Sprintf("The following error occurred while checking Tor connectivity: %s", errPartial.Error()))
+		i.Destroy()
+		return nil, errPartial
 	}
+
+	return i, nil
 }
 
-func newInstance(enableLogs bool) (*instance, error) {
-	i := createOurInstance(enableLogs)
+// useBridges configures the instance to connect to the Tor network through the
+// bridges configured by the user, and checks that Tor accepts the configuration
+func (i *instance) useBridges(conf *config.ApplicationConfig) error {
+	if !conf.AreBridgesEnabled() {
+		return nil
+	}
+
+	bridges, err := ParseBridgeLines(strings.Join(conf.GetBridgeLines(), "\n"))
+	if err != nil {
+		return err
+	}
+
+	if len(bridges) == 0 {
+		log.Warn("Bridges are enabled but no bridge has been configured")
+		return nil
+	}
+
+	plugins, err := findTransportPlugins(i.binary.path, bridges)
+	if err != nil {
+		return err
+	}
+
+	i.bridges = bridges
+	i.plugins = plugins
+
+	err = i.writeToFile()
+	if err != nil {
+		return err
+	}
+
+	return i.verifyConfigFile(ErrInvalidBridgesConfiguration)
+}
+
+// useTorrcLines adds the additional lines configured by the user to the
+// configuration file of the instance, and checks that Tor accepts them.
+// The instance is not started with a configuration the user didn't ask for,
+// so any problem with the lines is returned
+func (i *instance) useTorrcLines(conf *config.ApplicationConfig) error {
+	lines, err := ParseTorrcLines(strings.Join(conf.GetTorrcLines(), "\n"))
+	if err != nil {
+		return err
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	i.torrcLines = lines
+
+	err = i.writeToFile()
+	if err != nil {
+		return err
+	}
+
+	return i.verifyConfigFile(ErrInvalidTorrcConfiguration)
+}
+
+// verifyConfigFile checks the configuration file with the binary of the
+// instance, returning the given error when Tor doesn't accept it
+func (i *instance) verifyConfigFile(invalid error) error {
+	_, err := execTorCommand(i.binary.path, []string{"--verify-config", "-f", i.configFile}, func(cmd *exec.Cmd) {
+		if i.binary.isBundle {
+			cmd.Env = append(osf.Environ(), i.binary.env...)
+		}
+	})
+
+	if err != nil {
+		log.Errorf("verifyConfigFile(): Tor doesn't accept the configuration file %s", i.configFile)
+		return invalid
+	}
+
+	return nil
+}
+
+// singleHopConfiguration are the lines added to the Tor configuration of
+// the single-hop instances. Tor only accepts them without a SOCKS port
+const singleHopConfiguration = `# Single onion services, which don't hide the location of the host
+HiddenServiceNonAnonymousMode 1
+HiddenServiceSingleHopMode 1
+`
+
+// dataDirectoryFor returns the directory where our Tor instance keeps its
+// data when the user allows keeping it between executions, so Tor doesn't
+// need to download the network information again. Otherwise it's empty
+func dataDirectoryFor(conf *config.ApplicationConfig) string {
+	if conf.UseTorCache() {
+		return config.TorCacheDir()
+	}
+	return ""
+}
+
+// usesTorCache returns a boolean indicating if the Tor cache is the data directory of the instance
+func (i *instance) usesTorCache() bool {
+	return i.dataDirectory != "" && i.dataDirectory == config.TorCacheDir()
+}
+
+func newInstance(enableLogs bool, dataDirectory string) (*instance, error) {
+	i := createOurInstance(enableLogs, dataDirectory)
 
 	err := i.createConfigFile()
 
@@ -315,8 +603,10 @@ func (i *instance) Start() error {
 		return err
 	}
 
+	i.Lock()
 	i.started = true
 	i.runningTor = state
+	i.Unlock()
 
 	go state.waitForFinish()
 
@@ -325,31 +615,69 @@ func (i *instance) Start() error {
 
 // GetController returns a controller for the instance `i`
 func (i *instance) GetController() Control {
+	i.Lock()
+	defer i.Unlock()
+
 	log.Debugf("instance(%#v).GetController()", i)
 	if i.controller == nil {
-		i.controller = createController(i.controlHost, i.controlPort)
+		i.controller = i.newController()
+	}
+	return i.controller
+}
 
-		if len(i.password) != 0 {
-			i.controller.SetPassword(i.password)
-		}
+func (i *instance) newController() *controller {
+	c := createController(i.controlAddress())
+	c.state = i.onionState
 
-		if i.useCookie {
-			i.controller.UseCookieAuth()
-		}
+	if len(i.password) != 0 {
+		c.SetPassword(i.password)
 	}
-	return i.controller
+
+	if i.useCookie && i.cookieFile != "" {
+		c.UseCookieFile(i.cookieFile)
+	} else if i.useCookie {
+		c.UseCookieAuth()
+	}
+
+	return c
+}
+
+// trackOnions keeps a record of the onion services added to the Tor
+// instance, which doesn't belong to us, and removes the ones left
+// by a previous execution of Wahay that crashed
+func (i *instance) trackOnions() {
+	i.onionState = loadOnionState(defaultOnionStateFile())
+
+	c := i.newController()
+	c.removeLeftoverOnionServices()
+
+	i.Lock()
+	i.controller = c
+	i.Unlock()
 }
 
 // Destroy close our instance running
 func (i *instance) Destroy() {
-	if i.controller != nil {
-		i.controller.DeleteOnionServices()
-		i.controller = nil
+	i.Lock()
+	i.destroyed = true
+	i.Unlock()
+
+	controller, ownerController, runningTor := i.takeConnections()
+
+	if controller != nil {
+		controller.DeleteOnionServices()
 	}
 
-	if i.runningTor != nil {
-		i.runningTor.closeTorService()
-		i.runningTor = nil
+	if ownerController != nil {
+		_ = ownerController.Close()
+	}
+
+	if runningTor != nil {
+		runningTor.closeTorService()
+	}
+
+	if i.usesTorCache() {
+		filesystemf.ReleaseTorCache()
 	}
 
 	if i.configFile != "" {
@@ -364,18 +692,21 @@ func (i *instance) Destroy() {
 // ModifyCommand is a function that will potentially modify a command
 type ModifyCommand func(*exec.Cmd)
 
-func createOurInstance(enableLogs bool) *instance {
+// createOurInstance creates the instance of our own Tor, keeping its data in the
+// given directory. When none is given, it's kept in the temporary directory
+// of the instance, which is removed when the instance is destroyed
+func createOurInstance(enableLogs bool, dataDirectory string) *instance {
 	d := filesystemf.TempDir("tor")
 
-	controlPort, routePort := findAvailableTorPorts()
+	if dataDirectory == "" {
+		dataDirectory = filepath.Join(d, torConfigData)
+	}
 
 	i := &instance{
 		started:       false,
 		configFile:    filepath.Join(d, torConfigName),
 		controlHost:   defaultControlHost,
-		controlPort:   controlPort,
-		socksPort:     routePort,
-		dataDirectory: filepath.Join(d, torConfigData),
+		dataDirectory: dataDirectory,
 		enableLogs:    enableLogs,
 		password:      "", // our instance don't use authentication with password
 		useCookie:     true,
@@ -383,6 +714,18 @@ func createOurInstance(enableLogs bool) *instance {
 		controller:    nil,
 	}
 
+	// The temporary directory is only accessible by us, so the Unix domain
+	// sockets created there can't be used by other users of the system
+	controlSocket := filepath.Join(d, controlSocketName)
+	socksSocket := filepath.Join(d, socksSocketName)
+
+	if canUseUnixSocket(controlSocket) && canUseUnixSocket(socksSocket) {
+		i.controlSocket = controlSocket
+		i.socksSocket = socksSocket
+	} else {
+		i.controlPort, i.socksPort = findAvailableTorPorts()
+	}
+
 	return i
 }
 
@@ -413,10 +756,12 @@ func (i *instance) getConfigFileContents() []byte {
 	}
 
 	replacements := map[string]string{
-		"PORT":        strconv.Itoa(i.socksPort),
-		"CONTROLPORT": strconv.Itoa(i.controlPort),
+		"PORT":        i.socksConfiguration(),
+		"CONTROLPORT": i.controlConfiguration(),
 		"DATADIR":     i.dataDirectory,
 		"COOKIE":      strconv.Itoa(cookieFile),
+		"COOKIEFILE":  filepath.Join(filepath.Dir(i.configFile), torCookieFileName),
+		"PID":         strconv.Itoa(osf.Getpid()),
 	}
 
 	content := getTorrc()
@@ -440,9 +785,42 @@ func (i *instance) getConfigFileContents() []byte {
 		)
 	}
 
+	if i.singleHop {
+		content = fmt.Sprintf("%s\n%s", content, singleHopConfiguration)
+	}
+
+	if i.proxy != nil {
+		content = fmt.Sprintf("%s\n%s", content, proxyConfiguration(i.proxy))
+	}
+
+	if len(i.bridges) != 0 {
+		content = fmt.Sprintf("%s\n%s", content, bridgesConfiguration(i.bridges, i.plugins))
+	}
+
+	if len(i.torrcLines) != 0 {
+		content = fmt.Sprintf("%s\n%s", content, torrcLinesConfiguration(i.torrcLines))
+	}
+
 	return []byte(content)
 }
 
+func (i *instance) socksConfiguration() string {
+	if i.singleHop {
+		return "0"
+	}
+	if i.socksSocket != "" {
+		return unixSocketConfiguration(i.socksSocket)
+	}
+	return strconv.Itoa(i.socksPort)
+}
+
+func (i *instance) controlConfiguration() string {
+	if i.controlSocket != "" {
+		return unixSocketConfiguration(i.controlSocket)
+	}
+	return strconv.Itoa(i.controlPort)
+}
+
 func (i *instance) writeToFile() error {
 	return filesystemf.WriteFile(i.configFile, i.getConfigFileContents(), 0600)
 }
@@ -452,11 +830,20 @@ func (r *runningTor) closeTorService() {
 	<-r.finishChannel
 }
 
+// hasExited returns a boolean indicating if the Tor process has already exited
+func (r *runningTor) hasExited() bool {
+	select {
+	case <-r.exited:
+		return true
+	default:
+		return false
+	}
+}
+
 func (r *runningTor) waitForFinish() {
 	e := execf.WaitCommand(r.cmd)
 	r.finished = true
 	r.finishedWithError = e
-	// TODO: Maybe here, we should check if the failure was because
-	// of taken ports, regenerate the ports and try again?
 	r.finishChannel <- true
+	close(r.exited)
 }
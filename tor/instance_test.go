@@ -0,0 +1,91 @@
+package tor
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/digitalautonomy/wahay/config"
+	. "github.com/digitalautonomy/wahay/test"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_createOurInstance_keepsTheDataInTheGivenDirectory(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockfilesystemf.onTempDir = func(string) string {
+		return "/tmp/tor123"
+	}
+
+	i := createOurInstance(false, "/home/user/.local/share/wahay/tor-cache")
+
+	c.Assert(i.dataDirectory, Equals, "/home/user/.local/share/wahay/tor-cache")
+
+	content := string(i.getConfigFileContents())
+	c.Assert(content, Contains, "DataDirectory /home/user/.local/share/wahay/tor-cache\n")
+	c.Assert(content, Contains, "CookieAuthFile /tmp/tor123/control_auth_cookie\n")
+
+	i = createOurInstance(false, "")
+
+	c.Assert(i.dataDirectory, Equals, "/tmp/tor123/data")
+}
+
+func (s *WahayTorSuite) Test_dataDirectoryFor_onlyUsesTheTorCacheWhenAllowed(c *C) {
+	conf := config.New()
+	c.Assert(dataDirectoryFor(conf), Equals, "")
+
+	conf.EnableTorCache(true)
+	c.Assert(dataDirectoryFor(conf), Equals, config.TorCacheDir())
+}
+
+func (s *WahayTorSuite) Test_getConfigFileContents_onlyHostsSingleOnionServicesInSingleHopMode(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	i := createOurInstance(false, "")
+	i.singleHop = true
+
+	content := string(i.getConfigFileContents())
+	c.Assert(content, Contains, "SOCKSPort 0\n")
+	c.Assert(content, Contains, "HiddenServiceNonAnonymousMode 1\n")
+	c.Assert(content, Contains, "HiddenServiceSingleHopMode 1\n")
+	c.Assert(i.SocksAddress(), Equals, "")
+}
+
+func (s *WahayTorSuite) Test_getOurInstanceWithCache_usesATemporaryDataDirectoryWhenTheCacheCantBeUsed(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+	log.SetOutput(ioutil.Discard)
+
+	mockfilesystemf.onTempDir = func(string) string {
+		return "/tmp/tor123"
+	}
+	dataDirectories := []string{}
+	mockfilesystemf.onEnsureDir = func(name string, _ os.FileMode) {
+		dataDirectories = append(dataDirectories, name)
+	}
+
+	conf := config.New()
+	conf.EnableTorCache(true)
+
+	// Tor can't start with the cache
+	_, err := getOurInstanceWithCache(&binary{}, conf, nil, nil)
+
+	c.Assert(err, Equals, ErrTorInstanceCantStart)
+	c.Assert(dataDirectories, DeepEquals, []string{config.TorCacheDir(), "/tmp/tor123/data"})
+	c.Assert(mockfilesystemf.torCacheReleased, Equals, 1)
+
+	// Another execution of Wahay is using the cache
+	dataDirectories = []string{}
+	mockfilesystemf.torCacheReleased = 0
+	mockfilesystemf.onAcquireTorCache = func() bool {
+		return false
+	}
+
+	_, err = getOurInstanceWithCache(&binary{}, conf, nil, nil)
+
+	c.Assert(err, Equals, ErrTorInstanceCantStart)
+	c.Assert(dataDirectories, DeepEquals, []string{"/tmp/tor123/data"})
+	c.Assert(mockfilesystemf.torCacheReleased, Equals, 0)
+}
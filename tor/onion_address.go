@@ -0,0 +1,73 @@
+package tor
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	onionSuffix              = ".onion"
+	onionV3Version           = 0x03
+	onionV3PublicKeyLength   = 32
+	onionV3ChecksumLength    = 2
+	onionV3AddressLength     = 56
+	onionV3ChecksumConstant  = ".onion checksum"
+	onionV3DecodedAddressLen = onionV3PublicKeyLength + onionV3ChecksumLength + 1
+)
+
+// ErrInvalidOnionAddress is an error to return when a string is not a valid v3 onion address
+var ErrInvalidOnionAddress = errors.New("invalid onion address")
+
+var onionEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// OnionChecksum returns the checksum used in v3 onion addresses for the given public key,
+// as defined in the Tor rendezvous specification
+func OnionChecksum(publicKey []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte(onionV3ChecksumConstant))
+	h.Write(publicKey)
+	h.Write([]byte{onionV3Version})
+	return h.Sum(nil)[:onionV3ChecksumLength]
+}
+
+// OnionPublicKey returns the public key encoded in the given v3 onion address,
+// after validating its version and checksum. The ".onion" suffix is optional
+func OnionPublicKey(address string) ([]byte, error) {
+	a := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(address)), onionSuffix)
+	if len(a) != onionV3AddressLength {
+		return nil, ErrInvalidOnionAddress
+	}
+
+	decoded, err := onionEncoding.DecodeString(strings.ToUpper(a))
+	if err != nil || len(decoded) != onionV3DecodedAddressLen {
+		return nil, ErrInvalidOnionAddress
+	}
+
+	publicKey := decoded[:onionV3PublicKeyLength]
+	checksum := decoded[onionV3PublicKeyLength : onionV3PublicKeyLength+onionV3ChecksumLength]
+	version := decoded[onionV3DecodedAddressLen-1]
+
+	if version != onionV3Version || !bytes.Equal(checksum, OnionChecksum(publicKey)) {
+		return nil, ErrInvalidOnionAddress
+	}
+
+	return publicKey, nil
+}
+
+// OnionAddress returns the v3 onion address, including the ".onion" suffix, for the given public key
+func OnionAddress(publicKey []byte) (string, error) {
+	if len(publicKey) != onionV3PublicKeyLength {
+		return "", ErrInvalidOnionAddress
+	}
+
+	decoded := make([]byte, 0, onionV3DecodedAddressLen)
+	decoded = append(decoded, publicKey...)
+	decoded = append(decoded, OnionChecksum(publicKey)...)
+	decoded = append(decoded, onionV3Version)
+
+	return strings.ToLower(onionEncoding.EncodeToString(decoded)) + onionSuffix, nil
+}
@@ -0,0 +1,51 @@
+package tor
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+const testOnionAddress = "qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid.onion"
+
+func (s *WahayTorSuite) Test_OnionPublicKey_returnsThePublicKeyOfAValidAddress(c *C) {
+	pk, err := OnionPublicKey(testOnionAddress)
+
+	c.Assert(err, IsNil)
+	c.Assert(pk, HasLen, onionV3PublicKeyLength)
+	c.Assert(pk[:4], DeepEquals, []byte{133, 70, 151, 186})
+}
+
+func (s *WahayTorSuite) Test_OnionPublicKey_acceptsAddressesWithoutSuffixAndInUppercase(c *C) {
+	expected, _ := OnionPublicKey(testOnionAddress)
+
+	pk, err := OnionPublicKey("QVDJPOQCG572IBYLV673QR76IWASHLAZH6SPM47LY37W65IWWMKBMTID")
+
+	c.Assert(err, IsNil)
+	c.Assert(pk, DeepEquals, expected)
+}
+
+func (s *WahayTorSuite) Test_OnionPublicKey_returnsAnErrorWhenTheChecksumIsWrong(c *C) {
+	_, err := OnionPublicKey("rvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmtid.onion")
+	c.Assert(err, Equals, ErrInvalidOnionAddress)
+}
+
+func (s *WahayTorSuite) Test_OnionPublicKey_returnsAnErrorWhenTheAddressIsMalformed(c *C) {
+	_, err := OnionPublicKey("qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmti.onion")
+	c.Assert(err, Equals, ErrInvalidOnionAddress)
+
+	_, err = OnionPublicKey("qvdjpoqcg572ibylv673qr76iwashlazh6spm47ly37w65iwwmkbmt1d.onion")
+	c.Assert(err, Equals, ErrInvalidOnionAddress)
+}
+
+func (s *WahayTorSuite) Test_OnionAddress_returnsTheAddressForThePublicKey(c *C) {
+	pk, _ := OnionPublicKey(testOnionAddress)
+
+	address, err := OnionAddress(pk)
+
+	c.Assert(err, IsNil)
+	c.Assert(address, Equals, testOnionAddress)
+}
+
+func (s *WahayTorSuite) Test_OnionAddress_returnsAnErrorWhenThePublicKeyHasAnInvalidLength(c *C) {
+	_, err := OnionAddress([]byte{1, 2, 3})
+	c.Assert(err, Equals, ErrInvalidOnionAddress)
+}
@@ -0,0 +1,76 @@
+package tor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minProofOfWorkVersion is the first Tor version supporting
+// the proof-of-work defenses for onion services
+const minProofOfWorkVersion = "0.4.8"
+
+// OnionOptions contains the protections against connection floods
+// that Tor applies to an onion service. The zero value doesn't
+// enable any protection
+type OnionOptions struct {
+	// MaxStreams is the maximum number of streams that can be opened
+	// through a single rendezvous circuit. Zero means unlimited
+	MaxStreams int
+	// MaxStreamsCloseCircuit makes Tor close the rendezvous circuit when
+	// the MaxStreams limit is exceeded, instead of only refusing the stream
+	MaxStreamsCloseCircuit bool
+	// ProofOfWork enables the proof-of-work defenses, when the Tor
+	// version in use supports them
+	ProofOfWork bool
+	// PoWQueueRate and PoWQueueBurst limit the introduction requests
+	// handled when the proof-of-work defenses are enabled. Zero means
+	// the Tor defaults are used
+	PoWQueueRate  int
+	PoWQueueBurst int
+	// NonAnonymous adds a single onion service, which doesn't hide the
+	// location of the host. Tor only accepts it when it runs in the
+	// non-anonymous mode, so it's set by the instance itself
+	NonAnonymous bool
+}
+
+// arguments returns the ADD_ONION arguments for the options, as described in
+// the section 3.27 of the Tor control protocol specification
+func (o OnionOptions) arguments(withProofOfWork bool) []string {
+	result := []string{}
+
+	flags := []string{}
+	if o.NonAnonymous {
+		flags = append(flags, "NonAnonymous")
+	}
+	if o.MaxStreams > 0 && o.MaxStreamsCloseCircuit {
+		flags = append(flags, "MaxStreamsCloseCircuit")
+	}
+	if len(flags) > 0 {
+		result = append(result, "Flags="+strings.Join(flags, ","))
+	}
+
+	if o.MaxStreams > 0 {
+		result = append(result, fmt.Sprintf("MaxStreams=%d", o.MaxStreams))
+	}
+
+	if o.ProofOfWork && withProofOfWork {
+		result = append(result, "PoWDefensesEnabled=1")
+
+		if o.PoWQueueRate > 0 {
+			result = append(result, fmt.Sprintf("PoWQueueRate=%d", o.PoWQueueRate))
+		}
+
+		if o.PoWQueueBurst > 0 {
+			result = append(result, fmt.Sprintf("PoWQueueBurst=%d", o.PoWQueueBurst))
+		}
+	}
+
+	return result
+}
+
+// supportsProofOfWork returns a boolean indicating if the
+// Tor version given supports the proof-of-work defenses
+func supportsProofOfWork(version string) bool {
+	diff, err := compareVersions(version, minProofOfWorkVersion)
+	return err == nil && diff >= 0
+}
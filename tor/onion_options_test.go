@@ -0,0 +1,144 @@
+package tor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/wybiral/torgo"
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_OnionOptions_arguments_returnsTheADD_ONIONArguments(c *C) {
+	o := OnionOptions{
+		MaxStreams:             20,
+		MaxStreamsCloseCircuit: true,
+		ProofOfWork:            true,
+		PoWQueueRate:           100,
+		PoWQueueBurst:          200,
+	}
+
+	c.Assert(o.arguments(true), DeepEquals, []string{
+		"Flags=MaxStreamsCloseCircuit",
+		"MaxStreams=20",
+		"PoWDefensesEnabled=1",
+		"PoWQueueRate=100",
+		"PoWQueueBurst=200",
+	})
+	c.Assert(o.arguments(false), DeepEquals, []string{
+		"Flags=MaxStreamsCloseCircuit",
+		"MaxStreams=20",
+	})
+	c.Assert(OnionOptions{}.arguments(true), DeepEquals, []string{})
+}
+
+func (s *WahayTorSuite) Test_OnionOptions_arguments_marksSingleOnionServices(c *C) {
+	c.Assert(OnionOptions{NonAnonymous: true}.arguments(false), DeepEquals, []string{
+		"Flags=NonAnonymous",
+	})
+	c.Assert(OnionOptions{NonAnonymous: true, MaxStreams: 20, MaxStreamsCloseCircuit: true}.arguments(false), DeepEquals, []string{
+		"Flags=NonAnonymous,MaxStreamsCloseCircuit",
+		"MaxStreams=20",
+	})
+}
+
+func (s *WahayTorSuite) Test_supportsProofOfWork_checksTheTorVersion(c *C) {
+	c.Assert(supportsProofOfWork("0.4.8.9"), Equals, true)
+	c.Assert(supportsProofOfWork("0.4.9.1-alpha"), Equals, true)
+	c.Assert(supportsProofOfWork("0.4.7.16"), Equals, false)
+	c.Assert(supportsProofOfWork(""), Equals, false)
+}
+
+func (s *WahayTorSuite) Test_CreateNewOnionServiceWithMultiplePorts_onlyAsksForProofOfWorkWhenTorSupportsIt(c *C) {
+	ports := []OnionPort{{ServicePort: 64738, DestinationHost: "127.0.0.1", DestinationPort: 12345}}
+	options := OnionOptions{MaxStreams: 20, ProofOfWork: true}
+
+	cm := &controllerMock{getVersionReturn1: "0.4.8.9"}
+	cntrl := &controller{tc: cm.createTestGotor}
+
+	_, err := cntrl.CreateNewOnionServiceWithMultiplePorts(ports, options)
+
+	c.Assert(err, IsNil)
+	c.Assert(cm.addOnionArguments, DeepEquals, [][]string{{"MaxStreams=20", "PoWDefensesEnabled=1"}})
+
+	cm = &controllerMock{getVersionReturn1: "0.4.7.16"}
+	cntrl = &controller{tc: cm.createTestGotor}
+
+	_, err = cntrl.CreateNewOnionServiceWithMultiplePorts(ports, options)
+
+	c.Assert(err, IsNil)
+	c.Assert(cm.addOnionArguments, DeepEquals, [][]string{{"MaxStreams=20"}})
+}
+
+type failingProofOfWorkController struct {
+	controllerMock
+}
+
+func (m *failingProofOfWorkController) AddOnionWithArguments(o *torgo.Onion, args []string) error {
+	m.addOnionArguments = append(m.addOnionArguments, args)
+	for _, a := range args {
+		if strings.HasPrefix(a, "PoW") {
+			return errors.New("552 Unrecognized option")
+		}
+	}
+	return nil
+}
+
+func (s *WahayTorSuite) Test_CreateNewOnionServiceWithMultiplePorts_retriesWithoutProofOfWorkWhenTorDoesntHaveIt(c *C) {
+	cm := &failingProofOfWorkController{controllerMock{getVersionReturn1: "0.4.8.9"}}
+	cntrl := &controller{tc: func(string) (torgoController, error) { return cm, nil }}
+
+	_, err := cntrl.CreateNewOnionServiceWithMultiplePorts(
+		[]OnionPort{{ServicePort: 64738, DestinationHost: "127.0.0.1", DestinationPort: 12345}},
+		OnionOptions{ProofOfWork: true})
+
+	c.Assert(err, IsNil)
+	c.Assert(cm.addOnionArguments, DeepEquals, [][]string{{"PoWDefensesEnabled=1"}, {}})
+}
+
+func (s *WahayTorSuite) Test_controlConnection_AddOnionWithArguments_sendsTheArguments(c *C) {
+	path := filepath.Join(c.MkDir(), controlSocketName)
+
+	l, err := net.Listen("unix", path)
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- strings.TrimSpace(line)
+
+		fmt.Fprint(conn, "250-ServiceID=abcdef\r\n"+
+			"250-PrivateKey=ED25519-V3:a-private-key\r\n"+
+			"250 OK\r\n")
+	}()
+
+	text, err := textproto.Dial("unix", path)
+	c.Assert(err, IsNil)
+
+	cc := &controlConnection{&torgo.Controller{Text: text}}
+	o := &torgo.Onion{
+		Ports:          map[int]string{64738: "127.0.0.1:12345", 8181: "unix:/tmp/wahay123/certificate.sock"},
+		PrivateKeyType: "NEW",
+		PrivateKey:     onionKeyType,
+	}
+
+	err = cc.AddOnionWithArguments(o, []string{"Flags=MaxStreamsCloseCircuit", "MaxStreams=20"})
+
+	c.Assert(err, IsNil)
+	c.Assert(<-received, Equals, "ADD_ONION NEW:ED25519-V3 Flags=MaxStreamsCloseCircuit MaxStreams=20 "+
+		"Port=8181,unix:/tmp/wahay123/certificate.sock Port=64738,127.0.0.1:12345")
+	c.Assert(o.ServiceID, Equals, "abcdef")
+	c.Assert(o.PrivateKeyType, Equals, onionKeyType)
+	c.Assert(o.PrivateKey, Equals, "a-private-key")
+}
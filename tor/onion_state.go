@@ -0,0 +1,185 @@
+package tor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/digitalautonomy/wahay/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// onionStateFileName is the name of the file, in the configuration
+// directory, where the onion services added to Tor instances
+// that don't belong to us are recorded
+const onionStateFileName = "onions.json"
+
+// sessionStarted identifies this execution of Wahay, together with
+// its process ID, since the ID of a process that exited can be
+// given to a new one
+var sessionStarted = time.Now().UnixNano()
+
+// onionRecord contains the onion services added to a Tor
+// instance, by the address of its control port, in an
+// execution of Wahay
+type onionRecord struct {
+	ControlAddress string   `json:"control_address"`
+	PID            int      `json:"pid"`
+	Started        int64    `json:"started"`
+	Onions         []string `json:"onions"`
+}
+
+// onionState is the record of the onion services added to each Tor
+// instance by each execution of Wahay. The onion services left in it by
+// an execution of Wahay that isn't running anymore are removed the next
+// time a controller for the same Tor instance is created. The ones of
+// the executions still running are left alone, since several of them
+// can use the same Tor instance at the same time
+type onionState struct {
+	sync.Mutex
+	path    string
+	pid     int
+	started int64
+}
+
+func defaultOnionStateFile() string {
+	return filepath.Join(config.Dir(), onionStateFileName)
+}
+
+// loadOnionState returns the record of onion services kept in the given
+// file. The file is read every time, since other executions of Wahay
+// can change it
+func loadOnionState(path string) *onionState {
+	return &onionState{
+		path:    path,
+		pid:     osf.Getpid(),
+		started: sessionStarted,
+	}
+}
+
+func (s *onionState) isOurs(r onionRecord) bool {
+	return r.PID == s.pid && r.Started == s.started
+}
+
+// isAbandoned returns a boolean indicating if the execution of Wahay
+// that added the onion services of the record is not running anymore
+func (s *onionState) isAbandoned(r onionRecord) bool {
+	if s.isOurs(r) {
+		return false
+	}
+	return r.PID == s.pid || !osf.IsProcessRunning(r.PID)
+}
+
+// onionsOf returns the onion services this execution
+// added to the Tor instance at the given address
+func (s *onionState) onionsOf(controlAddress string) []string {
+	s.Lock()
+	defer s.Unlock()
+
+	result := []string{}
+	for _, r := range s.read() {
+		if r.ControlAddress == controlAddress && s.isOurs(r) {
+			result = append(result, r.Onions...)
+		}
+	}
+	return result
+}
+
+// leftoversOf returns the onion services added to the Tor instance at the
+// given address by the executions of Wahay that are not running anymore
+func (s *onionState) leftoversOf(controlAddress string) []string {
+	s.Lock()
+	defer s.Unlock()
+
+	result := []string{}
+	for _, r := range s.read() {
+		if r.ControlAddress == controlAddress && s.isAbandoned(r) {
+			result = append(result, r.Onions...)
+		}
+	}
+	return result
+}
+
+// forgetLeftoversOf removes the records of the executions of Wahay that
+// are not running anymore for the Tor instance at the given address
+func (s *onionState) forgetLeftoversOf(controlAddress string) {
+	s.update(func(r onionRecord) bool {
+		return r.ControlAddress == controlAddress && s.isAbandoned(r)
+	}, nil)
+}
+
+// set records the onion services this execution added
+// to the Tor instance at the given address
+func (s *onionState) set(controlAddress string, onions []string) {
+	var record *onionRecord
+	if len(onions) > 0 {
+		record = &onionRecord{
+			ControlAddress: controlAddress,
+			PID:            s.pid,
+			Started:        s.started,
+			Onions:         append([]string{}, onions...),
+		}
+	}
+
+	s.update(func(r onionRecord) bool {
+		return r.ControlAddress == controlAddress && s.isOurs(r)
+	}, record)
+}
+
+// update removes the records matching the given
+// function and adds the given one, if any
+func (s *onionState) update(remove func(onionRecord) bool, add *onionRecord) {
+	s.Lock()
+	defer s.Unlock()
+
+	records := []onionRecord{}
+	for _, r := range s.read() {
+		if !remove(r) {
+			records = append(records, r)
+		}
+	}
+
+	if add != nil {
+		records = append(records, *add)
+	}
+
+	s.save(records)
+}
+
+func (s *onionState) read() []onionRecord {
+	records := []onionRecord{}
+	if !filesystemf.FileExists(s.path) {
+		return records
+	}
+
+	content, err := filesystemf.ReadFile(s.path)
+	if err == nil {
+		err = json.Unmarshal(content, &records)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  s.path,
+			"error": err,
+		}).Warn("onionState.read(): the onion services of other executions can't be read")
+		return []onionRecord{}
+	}
+
+	return records
+}
+
+func (s *onionState) save(records []onionRecord) {
+	content, err := json.Marshal(records)
+	if err == nil {
+		filesystemf.EnsureDir(filepath.Dir(s.path), 0700)
+		err = filesystemf.WriteFile(s.path, content, 0600)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file":  s.path,
+			"error": err,
+		}).Warn("onionState.save(): the onion services can't be recorded")
+	}
+}
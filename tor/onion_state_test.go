@@ -0,0 +1,159 @@
+package tor
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_onionState_recordsTheOnionServicesOfEachTorInstanceInAFile(c *C) {
+	path := filepath.Join(c.MkDir(), "config", onionStateFileName)
+
+	st := loadOnionState(path)
+	c.Assert(st.onionsOf("127.0.0.1:9051"), HasLen, 0)
+
+	st.set("127.0.0.1:9051", []string{"one.onion", "two.onion"})
+	st.set("unix:/run/tor/control", []string{"three.onion"})
+
+	st = loadOnionState(path)
+	c.Assert(st.onionsOf("127.0.0.1:9051"), DeepEquals, []string{"one.onion", "two.onion"})
+	c.Assert(st.onionsOf("unix:/run/tor/control"), DeepEquals, []string{"three.onion"})
+
+	st.set("127.0.0.1:9051", nil)
+
+	st = loadOnionState(path)
+	c.Assert(st.onionsOf("127.0.0.1:9051"), HasLen, 0)
+	c.Assert(st.onionsOf("unix:/run/tor/control"), DeepEquals, []string{"three.onion"})
+}
+
+func (s *WahayTorSuite) Test_controller_onlyDeletesTheOnionServicesItAdded(c *C) {
+	path := filepath.Join(c.MkDir(), onionStateFileName)
+	st := loadOnionState(path)
+
+	first := &controllerMock{addOnionAddServiceInfo: "first"}
+	second := &controllerMock{addOnionAddServiceInfo: "second"}
+
+	c1 := &controller{torAddr: "127.0.0.1:9051", tc: first.createTestGotor, state: st}
+	c2 := &controller{torAddr: "127.0.0.1:9051", tc: second.createTestGotor}
+
+	_, err := c1.CreateNewOnionService("127.0.0.1", 12345, 64738)
+	c.Assert(err, IsNil)
+	_, err = c2.CreateNewOnionService("127.0.0.1", 12346, 64738)
+	c.Assert(err, IsNil)
+
+	c.Assert(c1.onions, DeepEquals, []string{"first.onion"})
+	c.Assert(c2.onions, DeepEquals, []string{"second.onion"})
+	c.Assert(loadOnionState(path).onionsOf("127.0.0.1:9051"), DeepEquals, []string{"first.onion"})
+
+	c1.DeleteOnionServices()
+
+	c.Assert(*first.deleteOnionArg, Equals, "first")
+	c.Assert(second.deleteOnionCalled, Equals, false)
+	c.Assert(c2.onions, DeepEquals, []string{"second.onion"})
+	c.Assert(loadOnionState(path).onionsOf("127.0.0.1:9051"), HasLen, 0)
+}
+
+type recordingDeletionsController struct {
+	controllerMock
+	deleted []string
+}
+
+func (m *recordingDeletionsController) DeleteOnion(serviceID string) error {
+	m.deleted = append(m.deleted, serviceID)
+	return errors.New("552 Unknown Onion Service id")
+}
+
+// previousExecution returns the record of the onion services added by an
+// execution of Wahay that had the same process ID as this one, which
+// means that it isn't running anymore
+func previousExecution(controlAddress string, onions ...string) onionRecord {
+	return onionRecord{
+		ControlAddress: controlAddress,
+		PID:            os.Getpid(),
+		Started:        sessionStarted - 1,
+		Onions:         onions,
+	}
+}
+
+// runningExecution returns the record of the onion services added
+// by an execution of Wahay that is still running
+func runningExecution(controlAddress string, onions ...string) onionRecord {
+	return onionRecord{
+		ControlAddress: controlAddress,
+		PID:            1,
+		Started:        sessionStarted,
+		Onions:         onions,
+	}
+}
+
+func writeOnionRecords(c *C, path string, records ...onionRecord) {
+	content, err := json.Marshal(records)
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(path, content, 0600), IsNil)
+}
+
+func readOnionRecords(c *C, path string) []onionRecord {
+	content, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+
+	records := []onionRecord{}
+	c.Assert(json.Unmarshal(content, &records), IsNil)
+
+	return records
+}
+
+func (s *WahayTorSuite) Test_onionState_keepsTheOnionServicesOfOtherExecutionsWhenRecordingItsOwn(c *C) {
+	path := filepath.Join(c.MkDir(), onionStateFileName)
+	other := runningExecution("127.0.0.1:9051", "other.onion")
+	writeOnionRecords(c, path, other)
+
+	st := loadOnionState(path)
+	st.set("127.0.0.1:9051", []string{"ours.onion"})
+
+	c.Assert(st.onionsOf("127.0.0.1:9051"), DeepEquals, []string{"ours.onion"})
+	c.Assert(readOnionRecords(c, path)[0], DeepEquals, other)
+
+	st.set("127.0.0.1:9051", nil)
+
+	c.Assert(readOnionRecords(c, path), DeepEquals, []onionRecord{other})
+}
+
+func (s *WahayTorSuite) Test_controller_removeLeftoverOnionServices_onlyRemovesTheOnesOfExecutionsThatAreNotRunning(c *C) {
+	path := filepath.Join(c.MkDir(), onionStateFileName)
+	running := runningExecution("127.0.0.1:9051", "running.onion")
+	otherTor := previousExecution("127.0.0.1:9151", "other.onion")
+	writeOnionRecords(c, path,
+		previousExecution("127.0.0.1:9051", "first.onion", "second.onion"),
+		running,
+		otherTor,
+	)
+
+	cm := &recordingDeletionsController{}
+	cntrl := createController("127.0.0.1:9051")
+	cntrl.tc = func(string) (torgoController, error) { return cm, nil }
+	cntrl.state = loadOnionState(path)
+
+	cntrl.removeLeftoverOnionServices()
+
+	c.Assert(cm.authenticateNoneCalled, Equals, true)
+	c.Assert(cm.deleted, DeepEquals, []string{"first", "second"})
+	c.Assert(readOnionRecords(c, path), DeepEquals, []onionRecord{running, otherTor})
+}
+
+func (s *WahayTorSuite) Test_controller_removeLeftoverOnionServices_keepsTheRecordWhenTorCantBeReached(c *C) {
+	path := filepath.Join(c.MkDir(), onionStateFileName)
+	leftover := previousExecution("127.0.0.1:9051", "first.onion")
+	writeOnionRecords(c, path, leftover)
+
+	cntrl := createController("127.0.0.1:9051")
+	cntrl.tc = func(string) (torgoController, error) { return nil, errors.New("connection refused") }
+	cntrl.state = loadOnionState(path)
+
+	cntrl.removeLeftoverOnionServices()
+
+	c.Assert(readOnionRecords(c, path), DeepEquals, []onionRecord{leftover})
+}
@@ -0,0 +1,151 @@
+package tor
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/digitalautonomy/wahay/config"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrMeetingIsolationUnavailable is an error to be trown when a Tor
+	// instance for a hosted meeting is requested, but Wahay is not running
+	// its own Tor instance, so it doesn't have a Tor binary to start others
+	ErrMeetingIsolationUnavailable = errors.New("Tor instances for the hosted meetings are not available")
+
+	// ErrTooManyTorInstances is an error to be trown when a Tor instance for
+	// a hosted meeting is requested, but the configured limit has been reached
+	ErrTooManyTorInstances = errors.New("too many Tor instances for the hosted meetings")
+)
+
+// Pool starts and keeps track of the Tor instances dedicated to the hosted
+// meetings. Each one has its own data directory, ports and guards, so the
+// meetings can't be correlated through the Tor instance they use
+type Pool interface {
+	// Acquire starts a new Tor instance, reporting the progress of its
+	// connection to the Tor network to the given listener
+	Acquire(onBootstrap BootstrapListener) (Instance, error)
+	// AcquireSingleHop starts a new Tor instance that only hosts single
+	// onion services, which don't hide the location of the host but avoid
+	// the latency of the three hops on its side of the rendezvous circuit
+	AcquireSingleHop(onBootstrap BootstrapListener) (Instance, error)
+	// Release destroys a Tor instance given by Acquire
+	Release(Instance)
+	// Close destroys all the Tor instances of the pool
+	Close()
+}
+
+type pool struct {
+	sync.Mutex
+	conf      *config.ApplicationConfig
+	binary    *binary
+	starting  int
+	instances []*instance
+	closed    bool
+}
+
+// NewPool returns the pool of Tor instances for the hosted meetings, limited to
+// the number currently configured. The instances use the same Tor binary found
+// for the given instance, so they can only be started when it's our own instance
+func NewPool(conf *config.ApplicationConfig, main Instance) Pool {
+	p := &pool{conf: conf}
+
+	if i, ok := main.(*instance); ok && !i.isLocal {
+		p.binary = i.binary
+	}
+
+	return p
+}
+
+func (p *pool) Acquire(onBootstrap BootstrapListener) (Instance, error) {
+	return p.acquire(func() (*instance, error) {
+		// Meeting instances never share the data directory, neither
+		// with the cache of our main Tor instance nor between them
+		return getOurInstance(p.binary, p.conf, "", nil, onBootstrap)
+	})
+}
+
+func (p *pool) AcquireSingleHop(onBootstrap BootstrapListener) (Instance, error) {
+	return p.acquire(func() (*instance, error) {
+		return getSingleHopInstance(p.binary, p.conf, onBootstrap)
+	})
+}
+
+func (p *pool) acquire(start func() (*instance, error)) (Instance, error) {
+	err := p.reserve()
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := start()
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.starting--
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("pool.acquire(): the Tor instance for the meeting can't be started")
+		return nil, err
+	}
+
+	if p.closed {
+		i.Destroy()
+		return nil, ErrTorInstanceCantStart
+	}
+
+	p.instances = append(p.instances, i)
+
+	return i, nil
+}
+
+// reserve takes one of the places available in the pool,
+// for the Tor instance that is going to be started
+func (p *pool) reserve() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.binary == nil || !p.binary.isValid {
+		return ErrMeetingIsolationUnavailable
+	}
+
+	if p.closed {
+		return ErrTorInstanceCantStart
+	}
+
+	if len(p.instances)+p.starting >= p.conf.GetMeetingIsolationLimit() {
+		return ErrTooManyTorInstances
+	}
+
+	p.starting++
+
+	return nil
+}
+
+func (p *pool) Release(t Instance) {
+	p.Lock()
+	defer p.Unlock()
+
+	for ix, i := range p.instances {
+		if i == t {
+			p.instances = append(p.instances[:ix], p.instances[ix+1:]...)
+			i.Destroy()
+			return
+		}
+	}
+}
+
+func (p *pool) Close() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.closed = true
+
+	for _, i := range p.instances {
+		i.Destroy()
+	}
+	p.instances = nil
+}
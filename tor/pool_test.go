@@ -0,0 +1,108 @@
+package tor
+
+import (
+	"io/ioutil"
+
+	"github.com/digitalautonomy/wahay/config"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_pool_isNotAvailableWithoutOurOwnTorInstance(c *C) {
+	conf := &config.ApplicationConfig{}
+	system := &instance{isLocal: true, binary: &binary{path: "/usr/sbin/tor", isValid: true}}
+
+	p := NewPool(conf, system)
+
+	_, err := p.Acquire(nil)
+	c.Assert(err, Equals, ErrMeetingIsolationUnavailable)
+}
+
+func (s *WahayTorSuite) Test_pool_usesTheBinaryOfOurOwnTorInstance(c *C) {
+	b := &binary{path: "/usr/sbin/tor", isValid: true}
+
+	p := NewPool(&config.ApplicationConfig{}, &instance{binary: b})
+
+	c.Assert(p.(*pool).binary, Equals, b)
+}
+
+func (s *WahayTorSuite) Test_pool_enforcesTheConfiguredLimit(c *C) {
+	conf := &config.ApplicationConfig{}
+	conf.SetMeetingIsolationLimit(2)
+
+	p := &pool{
+		conf:      conf,
+		binary:    &binary{path: "/usr/sbin/tor", isValid: true},
+		instances: []*instance{{}, {}},
+	}
+
+	_, err := p.Acquire(nil)
+	c.Assert(err, Equals, ErrTooManyTorInstances)
+
+	conf.SetMeetingIsolationLimit(3)
+
+	c.Assert(p.reserve(), IsNil)
+	c.Assert(p.reserve(), Equals, ErrTooManyTorInstances)
+}
+
+func (s *WahayTorSuite) Test_pool_freesThePlaceOfAnInstanceThatCantStart(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+	log.SetOutput(ioutil.Discard)
+
+	conf := &config.ApplicationConfig{}
+	conf.SetMeetingIsolationLimit(1)
+	conf.SetTorProxy(&config.TorProxy{Type: config.TorProxySocks5, Address: "not a proxy"})
+
+	p := &pool{
+		conf:   conf,
+		binary: &binary{path: "/usr/sbin/tor", isValid: true},
+	}
+
+	_, err := p.Acquire(nil)
+	c.Assert(err, Equals, ErrInvalidProxy)
+	c.Assert(p.starting, Equals, 0)
+	c.Assert(p.instances, HasLen, 0)
+}
+
+func (s *WahayTorSuite) Test_pool_Release_onlyDestroysTheGivenInstance(c *C) {
+	one, two := &instance{}, &instance{}
+	p := &pool{conf: &config.ApplicationConfig{}, instances: []*instance{one, two}}
+
+	p.Release(one)
+
+	c.Assert(one.destroyed, Equals, true)
+	c.Assert(two.destroyed, Equals, false)
+	c.Assert(p.instances, DeepEquals, []*instance{two})
+
+	p.Release(one)
+
+	c.Assert(p.instances, HasLen, 1)
+}
+
+func (s *WahayTorSuite) Test_pool_Close_destroysEveryInstance(c *C) {
+	one, two := &instance{}, &instance{}
+	p := &pool{
+		conf:      &config.ApplicationConfig{},
+		binary:    &binary{path: "/usr/sbin/tor", isValid: true},
+		instances: []*instance{one, two},
+	}
+
+	p.Close()
+
+	c.Assert(one.destroyed, Equals, true)
+	c.Assert(two.destroyed, Equals, true)
+	c.Assert(p.instances, HasLen, 0)
+
+	_, err := p.Acquire(nil)
+	c.Assert(err, Equals, ErrTorInstanceCantStart)
+}
+
+func (s *WahayTorSuite) Test_pool_AcquireSingleHop_isNotAvailableWithoutOurOwnTorInstance(c *C) {
+	system := &instance{isLocal: true, binary: &binary{path: "/usr/sbin/tor", isValid: true}}
+
+	p := NewPool(&config.ApplicationConfig{}, system)
+
+	_, err := p.AcquireSingleHop(nil)
+	c.Assert(err, Equals, ErrMeetingIsolationUnavailable)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package tor
+
+// shortPathName returns the given path, since only
+// Windows generates short names for the paths
+func shortPathName(path string) (string, error) {
+	return path, nil
+}
@@ -0,0 +1,20 @@
+package tor
+
+import "golang.org/x/sys/windows"
+
+// shortPathName returns the short name of the given path, as
+// generated by Windows for the programs that can't use long names
+func shortPathName(path string) (string, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	n, err := windows.GetShortPathName(p, &buf[0], uint32(len(buf)))
+	if err != nil {
+		return "", err
+	}
+
+	return windows.UTF16ToString(buf[:n]), nil
+}
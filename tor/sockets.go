@@ -0,0 +1,107 @@
+package tor
+
+import (
+	"net/textproto"
+	"strings"
+
+	"github.com/wybiral/torgo"
+)
+
+const (
+	// unixSocketPrefix is used by Tor, and by us, to indicate that an address
+	// is the path to a Unix domain socket instead of a TCP address
+	unixSocketPrefix = "unix:"
+
+	// maxUnixSocketPathLength is the shortest limit for the
+	// path of a Unix domain socket among the supported platforms
+	maxUnixSocketPathLength = 100
+
+	controlSocketName = "control.sock"
+	socksSocketName   = "socks.sock"
+)
+
+// SplitAddress returns the network and the address to use with net.Dial
+// for the given address, which can be host:port or unix:/path/to/socket
+func SplitAddress(addr string) (network, address string) {
+	if strings.HasPrefix(addr, unixSocketPrefix) {
+		return "unix", strings.TrimPrefix(addr, unixSocketPrefix)
+	}
+	return "tcp", addr
+}
+
+// UnixSocketAddress returns the address of the Unix domain socket at the
+// given path, in the format used by Tor and understood by SplitAddress
+func UnixSocketAddress(path string) string {
+	return unixSocketPrefix + path
+}
+
+// canUseUnixSocket returns a boolean indicating if a Unix
+// domain socket can be created in the given path
+func canUseUnixSocket(path string) bool {
+	return osf.SupportsUnixSockets() && len(path) <= maxUnixSocketPathLength
+}
+
+// unixSocketConfiguration returns the value used in the Tor configuration
+// file for a port listening in the Unix domain socket at the given path
+func unixSocketConfiguration(path string) string {
+	return unixSocketPrefix + quoteTorrcValue(path)
+}
+
+// quoteTorrcValue returns the given value as a quoted string of the
+// Tor configuration file, so characters like spaces or # are kept
+func quoteTorrcValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// newTorgoController connects to the control port at the given address.
+// torgo only supports TCP, so the connections through Unix domain sockets are
+// created here, asking Tor for the authentication information as torgo does
+func newTorgoController(addr string) (*torgo.Controller, error) {
+	network, address := SplitAddress(addr)
+	if network != "unix" {
+		return torgo.NewController(addr)
+	}
+
+	text, err := textproto.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &torgo.Controller{Text: text}
+
+	err = readProtocolInfo(c)
+	if err != nil {
+		_ = text.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func readProtocolInfo(c *torgo.Controller) error {
+	id, err := c.Text.Cmd("PROTOCOLINFO")
+	if err != nil {
+		return err
+	}
+
+	c.Text.StartResponse(id)
+	defer c.Text.EndResponse(id)
+
+	_, msg, err := c.Text.ReadResponse(250)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(msg, "\n") {
+		fields := splitStatusArguments(line)
+		if len(fields) == 0 || fields[0] != "AUTH" {
+			continue
+		}
+
+		args := argumentsOf(fields[1:])
+		c.AuthMethods = strings.Split(args["METHODS"], ",")
+		c.CookieFile = args["COOKIEFILE"]
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package tor
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	. "github.com/digitalautonomy/wahay/test"
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_SplitAddress_returnsTheNetworkOfTheAddress(c *C) {
+	network, address := SplitAddress("127.0.0.1:9050")
+	c.Assert(network, Equals, "tcp")
+	c.Assert(address, Equals, "127.0.0.1:9050")
+
+	network, address = SplitAddress("unix:/tmp/tor123/socks.sock")
+	c.Assert(network, Equals, "unix")
+	c.Assert(address, Equals, "/tmp/tor123/socks.sock")
+}
+
+func (s *WahayTorSuite) Test_createOurInstance_usesUnixSocketsWhenThePlatformSupportsThem(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockosf.supportsUnixSockets = true
+	mockfilesystemf.onTempDir = func(string) string {
+		return "/tmp/tor123"
+	}
+	mockosf.onIsPortAvailable = func(int) bool {
+		c.Error("no port should be needed")
+		return true
+	}
+
+	i := createOurInstance(false, "")
+
+	c.Assert(i.UsesUnixSockets(), Equals, true)
+	c.Assert(i.controlAddress(), Equals, "unix:/tmp/tor123/control.sock")
+	c.Assert(i.SocksAddress(), Equals, "unix:/tmp/tor123/socks.sock")
+
+	content := string(i.getConfigFileContents())
+	c.Assert(content, Contains, `SOCKSPort unix:"/tmp/tor123/socks.sock"`)
+	c.Assert(content, Contains, `ControlPort unix:"/tmp/tor123/control.sock"`)
+}
+
+func (s *WahayTorSuite) Test_createOurInstance_usesTCPPortsWhenTheSocketPathIsTooLong(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockosf.supportsUnixSockets = true
+	mockfilesystemf.onTempDir = func(string) string {
+		return "/tmp/" + strings.Repeat("a", maxUnixSocketPathLength)
+	}
+
+	i := createOurInstance(false, "")
+
+	c.Assert(i.UsesUnixSockets(), Equals, false)
+	c.Assert(i.controlAddress(), Equals, "127.0.0.1:9051")
+	c.Assert(i.SocksAddress(), Equals, "127.0.0.1:9050")
+}
+
+func (s *WahayTorSuite) Test_newTorgoController_readsTheAuthenticationMethodsThroughAUnixSocket(c *C) {
+	path := filepath.Join(c.MkDir(), controlSocketName)
+
+	l, err := net.Listen("unix", path)
+	c.Assert(err, IsNil)
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		if strings.TrimSpace(line) != "PROTOCOLINFO" {
+			return
+		}
+
+		fmt.Fprint(conn, "250-PROTOCOLINFO 1\r\n"+
+			"250-AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE=\"/tmp/tor123/data/control_auth_cookie\"\r\n"+
+			"250-VERSION Tor=\"0.4.8.9\"\r\n"+
+			"250 OK\r\n")
+	}()
+
+	tc, err := newTorgoController(UnixSocketAddress(path))
+
+	c.Assert(err, IsNil)
+	c.Assert(tc.AuthMethods, DeepEquals, []string{"COOKIE", "SAFECOOKIE"})
+	c.Assert(tc.CookieFile, Equals, "/tmp/tor123/data/control_auth_cookie")
+}
+
+func (s *WahayTorSuite) Test_CreateNewOnionServiceWithMultiplePorts_sendsConnectionsToUnixSockets(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	cm := &controllerMock{addOnionAddServiceInfo: "abcdef"}
+	mocktorgof.onNewController = func(string) (torgoController, error) {
+		return cm, nil
+	}
+
+	cntrl := createController("unix:/tmp/tor123/control.sock")
+
+	_, err := cntrl.CreateNewOnionServiceWithMultiplePorts([]OnionPort{
+		{ServicePort: 8181, DestinationSocket: "/tmp/wahay123/certificate.sock"},
+		{ServicePort: 64738, DestinationHost: "127.0.0.1", DestinationPort: 12345},
+	}, OnionOptions{})
+
+	c.Assert(err, IsNil)
+	c.Assert(cm.addOnionArg1.Ports, DeepEquals, map[int]string{
+		8181:  "unix:/tmp/wahay123/certificate.sock",
+		64738: "127.0.0.1:12345",
+	})
+}
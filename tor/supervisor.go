@@ -0,0 +1,196 @@
+package tor
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const maxRestartAttempts = 3
+
+// restartRetryInterval is how long we wait before trying
+// again to restart our Tor instance after a failed attempt
+var restartRetryInterval = 5 * time.Second
+
+var errOnionNotRestored = errors.New("the onion service can't be restored")
+
+// OnRestart adds a function to be called every time our Tor instance
+// is restarted after the Tor process exited unexpectedly
+func (i *instance) OnRestart(f func(Instance)) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.onRestartCallbacks = append(i.onRestartCallbacks, f)
+}
+
+func (i *instance) isDestroyed() bool {
+	i.Lock()
+	defer i.Unlock()
+
+	return i.destroyed
+}
+
+// supervise restarts our Tor instance every time the Tor process
+// exits unexpectedly, until the instance is destroyed
+func (i *instance) supervise() {
+	for {
+		r := i.currentRunningTor()
+		if r == nil {
+			return
+		}
+
+		<-r.exited
+
+		if i.isDestroyed() {
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"error": r.finishedWithError,
+		}).Error("The Tor process exited unexpectedly")
+
+		if !i.restartAfterCrash() {
+			log.Error("Our Tor instance can't be restarted")
+			return
+		}
+	}
+}
+
+func (i *instance) restartAfterCrash() bool {
+	for attempt := 1; attempt <= maxRestartAttempts; attempt++ {
+		if i.isDestroyed() {
+			return false
+		}
+
+		log.Infof("Restarting our Tor instance (attempt %d of %d)", attempt, maxRestartAttempts)
+
+		err := i.restart()
+		if err == nil {
+			i.notifyRestart()
+			return true
+		}
+
+		log.Errorf("restartAfterCrash(): %s", err)
+		time.Sleep(restartRetryInterval)
+	}
+
+	return false
+}
+
+// restart starts the Tor process again, with new ports when the previous ones
+// have been taken in the meantime, and restores the onion services we had
+func (i *instance) restart() error {
+	i.forgetConnections()
+
+	i.Lock()
+	if i.controlSocket != "" {
+		i.removeSockets()
+	} else {
+		i.controlPort = findAvailablePort(i.controlPort)
+		i.socksPort = findAvailablePort(i.socksPort)
+	}
+	i.Unlock()
+
+	err := i.writeToFile()
+	if err != nil {
+		return err
+	}
+
+	err = i.Start()
+	if err != nil {
+		return err
+	}
+
+	err = i.waitForBootstrap(nil, torStartupTimeout)
+	if err == nil {
+		err = i.restoreOnions()
+	}
+
+	if err != nil {
+		i.forgetConnections()
+		return err
+	}
+
+	return nil
+}
+
+// forgetConnections closes everything related to the Tor process that exited
+func (i *instance) forgetConnections() {
+	_, ownerController, runningTor := i.takeConnections()
+
+	if ownerController != nil {
+		_ = ownerController.Close()
+	}
+
+	if runningTor != nil {
+		runningTor.closeTorService()
+	}
+}
+
+// takeConnections removes from the instance the connections to the Tor process
+// and returns them, so they can be closed without keeping the instance locked
+func (i *instance) takeConnections() (Control, torgoEventController, *runningTor) {
+	i.Lock()
+	defer i.Unlock()
+
+	controller, ownerController, runningTor := i.controller, i.ownerController, i.runningTor
+	i.controller, i.ownerController, i.runningTor = nil, nil, nil
+
+	return controller, ownerController, runningTor
+}
+
+func (i *instance) currentRunningTor() *runningTor {
+	i.Lock()
+	defer i.Unlock()
+
+	return i.runningTor
+}
+
+// removeSockets removes the Unix domain sockets left
+// behind by the Tor process that exited unexpectedly
+func (i *instance) removeSockets() {
+	for _, s := range []string{i.controlSocket, i.socksSocket} {
+		err := osf.RemoveAll(s)
+		if err != nil {
+			log.Debugf("removeSockets(): %s", err)
+		}
+	}
+}
+
+// restoreOnions creates again the onion services we had, using their
+// private keys so they can still be reached at the same address
+func (i *instance) restoreOnions() error {
+	i.Lock()
+	onions := append([]*onion{}, i.onions...)
+	i.Unlock()
+
+	c := i.GetController()
+
+	for _, o := range onions {
+		serviceID, _, err := c.CreateOnionServiceWithKey(o.ports, o.options, o.privateKey)
+		if err != nil {
+			return err
+		}
+
+		if serviceID != o.id {
+			log.WithFields(log.Fields{
+				"previous": o.id,
+				"current":  serviceID,
+			}).Error("restoreOnions(): the onion service has a different address")
+			return errOnionNotRestored
+		}
+	}
+
+	return nil
+}
+
+func (i *instance) notifyRestart() {
+	i.Lock()
+	callbacks := append([]func(Instance){}, i.onRestartCallbacks...)
+	i.Unlock()
+
+	for _, f := range callbacks {
+		f(i)
+	}
+}
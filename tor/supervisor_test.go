@@ -0,0 +1,142 @@
+package tor
+
+import (
+	"os/exec"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_restartAfterCrash_restoresTheOnionsAndNotifiesTheDependents(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	exit := make(chan bool)
+	mockexecf.onWaitCommand = func(*exec.Cmd) error {
+		<-exit
+		return nil
+	}
+
+	mockosf.onIsPortAvailable = func(p int) bool {
+		return p != 9051
+	}
+	mockosf.onGetRandomPort = func() int {
+		return 9999
+	}
+
+	cm := &controllerMock{addOnionAddServiceInfo: "abcdef"}
+	mocktorgof.onNewController = func(string) (torgoController, error) {
+		return cm, nil
+	}
+
+	i := &instance{
+		controlHost: "127.0.0.1",
+		controlPort: 9051,
+		socksPort:   9050,
+		useCookie:   true,
+		configFile:  "/tmp/wahay-tor/torrc",
+		binary:      &binary{path: "/usr/bin/tor", isValid: true},
+	}
+	i.onions = []*onion{{
+		id:         "abcdef.onion",
+		ports:      []OnionPort{{ServicePort: 64738, DestinationPort: 12345, DestinationHost: "127.0.0.1"}},
+		privateKey: "a-private-key",
+		t:          i,
+	}}
+
+	var restarted Instance
+	i.OnRestart(func(ix Instance) {
+		restarted = ix
+	})
+
+	c.Assert(i.restartAfterCrash(), Equals, true)
+
+	c.Assert(i.controlPort, Equals, 9999)
+	c.Assert(i.SocksAddress(), Equals, "127.0.0.1:9050")
+	c.Assert(cm.authenticateCookieCalled, Equals, true)
+	c.Assert(cm.addOnionArg1.PrivateKeyType, Equals, "ED25519-V3")
+	c.Assert(cm.addOnionArg1.PrivateKey, Equals, "a-private-key")
+	c.Assert(cm.addOnionArg1.Ports, DeepEquals, map[int]string{64738: "127.0.0.1:12345"})
+	c.Assert(restarted, Equals, i)
+
+	close(exit)
+	i.Destroy()
+}
+
+func (s *WahayTorSuite) Test_supervise_doesntRestartADestroyedInstance(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	started := false
+	mockexecf.onStartCommand = func(*exec.Cmd) error {
+		started = true
+		return nil
+	}
+
+	r := &runningTor{exited: make(chan struct{})}
+	close(r.exited)
+
+	i := &instance{
+		runningTor: r,
+		destroyed:  true,
+		binary:     &binary{path: "/usr/bin/tor", isValid: true},
+	}
+
+	i.supervise()
+
+	c.Assert(started, Equals, false)
+}
+
+func (s *WahayTorSuite) Test_restartAfterCrash_canBeUsedWhileTheControllerIsRequested(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	exit := make(chan bool)
+	mockexecf.onWaitCommand = func(*exec.Cmd) error {
+		<-exit
+		return nil
+	}
+
+	cm := &controllerMock{}
+	mocktorgof.onNewController = func(string) (torgoController, error) {
+		return cm, nil
+	}
+
+	i := &instance{
+		controlHost: "127.0.0.1",
+		controlPort: 9051,
+		socksPort:   9050,
+		useCookie:   true,
+		configFile:  "/tmp/wahay-tor/torrc",
+		binary:      &binary{path: "/usr/bin/tor", isValid: true},
+	}
+
+	stop := make(chan bool)
+	running := make(chan bool)
+	done := make(chan bool)
+
+	go func() {
+		defer close(done)
+		c.Check(i.GetController(), NotNil)
+		close(running)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				// Checking the result here would synchronize both
+				// goroutines, hiding the race from the race detector
+				i.GetController()
+			}
+		}
+	}()
+
+	<-running
+
+	c.Assert(i.restartAfterCrash(), Equals, true)
+
+	close(stop)
+	<-done
+
+	close(exit)
+	i.Destroy()
+}
@@ -5,8 +5,20 @@ import "github.com/wybiral/torgo"
 type torgoController interface {
 	AuthenticatePassword(string) error
 	AuthenticateCookie() error
+	AuthenticateCookieFile(string) error
 	AuthenticateNone() error
 	AddOnion(*torgo.Onion) error
+	AddOnionWithArguments(*torgo.Onion, []string) error
 	GetVersion() (string, error)
 	DeleteOnion(string) error
+	Close() error
+}
+
+type torgoEventController interface {
+	torgoController
+	GetInfo(string) (string, error)
+	SetEvents(...string) error
+	CloseCircuit(string) error
+	TakeOwnership() error
+	ReadEvent() (string, error)
 }
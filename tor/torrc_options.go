@@ -0,0 +1,129 @@
+package tor
+
+import (
+	"bufio"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrInvalidTorrcLine is an error to be trown when one of the additional
+	// lines for the Tor configuration file can't be understood
+	ErrInvalidTorrcLine = errors.New("invalid Tor configuration line")
+
+	// ErrTorrcOptionNotAllowed is an error to be trown when one of the
+	// additional lines for the Tor configuration file tries to change an
+	// option that Wahay depends on
+	ErrTorrcOptionNotAllowed = errors.New("Tor configuration option not allowed")
+
+	// ErrInvalidTorrcConfiguration is an error to be trown when Tor
+	// doesn't accept the additional lines for its configuration file
+	ErrInvalidTorrcConfiguration = errors.New("invalid additional Tor configuration")
+)
+
+// torrcOptionName matches the name of an option in a Tor configuration
+// file, with the "+" or "/" prefixes used to append to or clear a list
+var torrcOptionName = regexp.MustCompile(`^[+/]?([A-Za-z][A-Za-z0-9]*)$`)
+
+// deniedTorrcOptions are the options, in lower case, that the additional
+// lines can't contain because Wahay needs to control them to start, talk
+// to and stop its Tor instance. The bridges are configured in their own
+// settings, so they are also not allowed here
+var deniedTorrcOptions = map[string]bool{
+	"socksport":                   true,
+	"controlport":                 true,
+	"controlsocket":               true,
+	"controlportwritetofile":      true,
+	"datadirectory":               true,
+	"cachedirectory":              true,
+	"cookieauthentication":        true,
+	"cookieauthfile":              true,
+	"cookieauthfilegroupreadable": true,
+	"hashedcontrolpassword":       true,
+	"owningcontrollerprocess":     true,
+	"disablenetwork":              true,
+	"runasdaemon":                 true,
+	"user":                        true,
+	"pidfile":                     true,
+	"log":                         true,
+	"usebridges":                  true,
+	"bridge":                      true,
+	"clienttransportplugin":       true,
+}
+
+// isDeniedTorrcOption returns a boolean indicating if the option with the given
+// name could be one of the denied options. Tor doesn't only use the exact name,
+// it also uses any abbreviation of it, like "DataDir", "SocksP" or "l" for "Log",
+// and the only warning is a message in its log
+func isDeniedTorrcOption(name string) bool {
+	name = strings.ToLower(name)
+
+	for denied := range deniedTorrcOptions {
+		if strings.HasPrefix(denied, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTorrcLines returns the lines for the Tor configuration file contained
+// in the given text. Empty lines and comments are ignored. The options that
+// Wahay depends on can't be changed, and neither the lines that include
+// other files nor the internal options of Tor are allowed
+func ParseTorrcLines(text string) ([]string, error) {
+	result := []string{}
+
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		err := checkTorrcLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, line)
+	}
+
+	return result, nil
+}
+
+func checkTorrcLine(line string) error {
+	fields := strings.Fields(line)
+
+	if strings.HasPrefix(fields[0], "%") || strings.HasPrefix(fields[0], "__") {
+		return ErrTorrcOptionNotAllowed
+	}
+
+	m := torrcOptionName.FindStringSubmatch(fields[0])
+	if m == nil {
+		return ErrInvalidTorrcLine
+	}
+
+	if isDeniedTorrcOption(m[1]) {
+		return ErrTorrcOptionNotAllowed
+	}
+
+	// Only the lines clearing an option can go without a value
+	hasValue := len(fields) > 1 || strings.HasPrefix(fields[0], "/")
+	if !hasValue || strings.HasSuffix(line, "\\") {
+		return ErrInvalidTorrcLine
+	}
+
+	return nil
+}
+
+// torrcLinesConfiguration returns the lines to add to the Tor configuration
+// file for the additional options configured by the user
+func torrcLinesConfiguration(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "# Additional options configured by the user\n" + strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,114 @@
+package tor
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/digitalautonomy/wahay/config"
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_ParseTorrcLines_ignoresEmptyLinesAndComments(c *C) {
+	lines, err := ParseTorrcLines("\n# Only exit in these countries\n  ExitNodes {de},{nl}  \n\n" +
+		"StrictNodes 1\nReachableAddresses *:80,*:443\nHTTPSProxy 10.0.0.1:3128\nConnectionPadding 1\n")
+
+	c.Assert(err, IsNil)
+	c.Assert(lines, DeepEquals, []string{
+		"ExitNodes {de},{nl}",
+		"StrictNodes 1",
+		"ReachableAddresses *:80,*:443",
+		"HTTPSProxy 10.0.0.1:3128",
+		"ConnectionPadding 1",
+	})
+}
+
+func (s *WahayTorSuite) Test_ParseTorrcLines_rejectsTheOptionsWahayDependsOn(c *C) {
+	for _, line := range []string{
+		"SocksPort 9999",
+		"socksport 9999",
+		"+ControlPort 9998",
+		"/DataDirectory",
+		"DataDirectory /tmp/tor",
+		"CookieAuthentication 0",
+		"HashedControlPassword 16:ABC",
+		"__OwningControllerProcess 1",
+		"UseBridges 1",
+		"%include /etc/tor/torrc.d",
+		"DataDir /tmp/tor",
+		"SocksP 9999",
+		"ControlP 1",
+		"cookieauth 0",
+		"l notice file /tmp/tor.log",
+		"Lo notice stdout",
+	} {
+		_, err := ParseTorrcLines("ExitNodes {de}\n" + line)
+		c.Assert(err, Equals, ErrTorrcOptionNotAllowed, Commentf("line: %s", line))
+	}
+}
+
+func (s *WahayTorSuite) Test_ParseTorrcLines_rejectsLinesThatCantBeUnderstood(c *C) {
+	for _, line := range []string{
+		"ExitNodes",
+		"Exit-Nodes {de}",
+		"ExitNodes {de},\\",
+	} {
+		_, err := ParseTorrcLines(line)
+		c.Assert(err, Equals, ErrInvalidTorrcLine, Commentf("line: %s", line))
+	}
+}
+
+func (s *WahayTorSuite) Test_instance_useTorrcLines_writesTheLinesAndVerifiesTheConfiguration(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	var written []byte
+	mockfilesystemf.onWriteFile = func(name string, content []byte, _ os.FileMode) error {
+		written = content
+		return nil
+	}
+
+	var verifyArgs []string
+	mockexecf.onExecWithModify = func(bin string, args []string, _ ModifyCommand) ([]byte, error) {
+		verifyArgs = args
+		return []byte("Configuration was valid"), nil
+	}
+
+	conf := &config.ApplicationConfig{}
+	conf.SetTorrcLines([]string{"ExitNodes {de}", "ConnectionPadding 1"})
+
+	i := &instance{
+		configFile: "/tmp/wahay-tor/torrc",
+		binary:     &binary{path: "/usr/sbin/tor", isValid: true},
+	}
+
+	c.Assert(i.useTorrcLines(conf), IsNil)
+	c.Assert(strings.HasSuffix(string(written), "\nExitNodes {de}\nConnectionPadding 1\n"), Equals, true)
+	c.Assert(verifyArgs, DeepEquals, []string{"--verify-config", "-f", "/tmp/wahay-tor/torrc"})
+}
+
+func (s *WahayTorSuite) Test_instance_useTorrcLines_returnsAnErrorWhenTorRejectsTheConfiguration(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mockexecf.onExecWithModify = func(string, []string, ModifyCommand) ([]byte, error) {
+		return nil, &exec.ExitError{}
+	}
+
+	conf := &config.ApplicationConfig{}
+	conf.SetTorrcLines([]string{"ExitNodes {de}"})
+
+	i := &instance{
+		configFile: "/tmp/wahay-tor/torrc",
+		binary:     &binary{path: "/usr/sbin/tor", isValid: true},
+	}
+
+	c.Assert(i.useTorrcLines(conf), Equals, ErrInvalidTorrcConfiguration)
+}
+
+func (s *WahayTorSuite) Test_instance_useTorrcLines_doesNothingWithoutLines(c *C) {
+	i := &instance{}
+
+	c.Assert(i.useTorrcLines(&config.ApplicationConfig{}), IsNil)
+	c.Assert(i.torrcLines, IsNil)
+}
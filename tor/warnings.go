@@ -0,0 +1,230 @@
+package tor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrClockSkew is an error to be trown when Tor can't connect
+	// to the Tor network because the system clock is wrong
+	ErrClockSkew = errors.New("the system clock is wrong")
+
+	// ErrNetworkUnreachable is an error to be trown when Tor can't
+	// reach the Tor network at all
+	ErrNetworkUnreachable = errors.New("the network is unreachable")
+
+	// ErrDirectoryFetchFailed is an error to be trown when Tor can't
+	// download the information about the relays of the Tor network
+	ErrDirectoryFetchFailed = errors.New("the Tor network directory can't be downloaded")
+
+	// ErrDangerousTorVersion is an error to be trown when the Tor network
+	// reports that the version of the Tor binary is obsolete or not recommended
+	ErrDangerousTorVersion = errors.New("the Tor version is obsolete or not recommended")
+
+	// ErrBridgeConnectionFailed is an error to be trown when Tor can't
+	// connect to the Tor network through the configured bridges
+	ErrBridgeConnectionFailed = errors.New("the configured bridges can't be reached")
+)
+
+// ClockSkewError is the error returned when Tor detects that the system clock is
+// wrong. It contains the skew measured by Tor, and it can be compared with ErrClockSkew
+type ClockSkewError struct {
+	Skew time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	return fmt.Sprintf("%s by %s", ErrClockSkew, e.Skew)
+}
+
+// Is returns true when the target is ErrClockSkew
+func (e *ClockSkewError) Is(target error) bool {
+	return target == ErrClockSkew
+}
+
+const (
+	statusGeneralEvent = "STATUS_GENERAL"
+	statusClientEvent  = "STATUS_CLIENT"
+	warnEvent          = "WARN"
+)
+
+// bootstrapNetworkReasons are the reasons given by Tor for the bootstrap
+// problems caused by not being able to connect to the relays
+var bootstrapNetworkReasons = map[string]bool{
+	"CONNECTREFUSED": true,
+	"CONNECTRESET":   true,
+	"NOROUTE":        true,
+	"TIMEOUT":        true,
+	"IOERROR":        true,
+}
+
+// bootstrapDirectoryTags are the bootstrap phases
+// in which Tor downloads the directory information
+var bootstrapDirectoryTags = map[string]bool{
+	"requesting_status":      true,
+	"loading_status":         true,
+	"requesting_keys":        true,
+	"loading_keys":           true,
+	"requesting_descriptors": true,
+	"loading_descriptors":    true,
+	"enough_dirinfo":         true,
+}
+
+// dangerousVersionReasons are the reasons given by Tor for a DANGEROUS_VERSION
+// status that mean the running version is insecure. A version newer than the
+// recommended ones is reported too, with the NEW reason, but it isn't a problem
+var dangerousVersionReasons = map[string]bool{
+	"OBSOLETE":      true,
+	"UNRECOMMENDED": true,
+}
+
+// torDiagnosis collects the warnings sent by Tor while it connects to the
+// Tor network, to explain to the user what went wrong when it can't connect
+type torDiagnosis struct {
+	sync.Mutex
+	usingBridges bool
+	usingProxy   bool
+
+	clockSkew          *ClockSkewError
+	dangerousVersion   bool
+	networkUnreachable bool
+	directoryFailed    bool
+	bridgeFailed       bool
+	proxyRefused       bool
+	proxyAuthFailed    bool
+}
+
+func newTorDiagnosis(usingBridges, usingProxy bool) *torDiagnosis {
+	return &torDiagnosis{usingBridges: usingBridges, usingProxy: usingProxy}
+}
+
+// analyze takes note of the problems reported in the given
+// STATUS_GENERAL, STATUS_CLIENT or WARN event
+func (d *torDiagnosis) analyze(event string) {
+	fields := splitStatusArguments(event)
+	if len(fields) < 2 {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	switch {
+	case fields[0] == warnEvent:
+		d.analyzeWarning(strings.TrimPrefix(event, warnEvent+" "))
+	case len(fields) < 3:
+		return
+	case fields[0] == statusGeneralEvent:
+		d.analyzeGeneralStatus(fields[2], argumentsOf(fields[3:]))
+	case fields[0] == statusClientEvent:
+		d.analyzeClientStatus(fields[2], argumentsOf(fields[3:]))
+	}
+}
+
+func (d *torDiagnosis) analyzeGeneralStatus(action string, args map[string]string) {
+	switch action {
+	case "CLOCK_SKEW":
+		skew, err := strconv.ParseInt(args["SKEW"], 10, 64)
+		if err != nil {
+			return
+		}
+		d.clockSkew = &ClockSkewError{Skew: time.Duration(skew) * time.Second}
+	case "DANGEROUS_VERSION":
+		if dangerousVersionReasons[args["REASON"]] {
+			d.dangerousVersion = true
+		}
+	case "DIR_ALL_UNREACHABLE":
+		d.failedToReachTheNetwork()
+	}
+}
+
+func (d *torDiagnosis) analyzeClientStatus(action string, args map[string]string) {
+	if action != bootstrapStatusKeyword || len(args["WARNING"]) == 0 {
+		return
+	}
+
+	switch {
+	case bootstrapNetworkReasons[args["REASON"]]:
+		d.failedToReachTheNetwork()
+	case bootstrapDirectoryTags[args["TAG"]]:
+		d.directoryFailed = true
+	}
+}
+
+func (d *torDiagnosis) analyzeWarning(message string) {
+	msg := strings.ToLower(message)
+
+	switch {
+	case strings.Contains(msg, "network is unreachable"):
+		d.failedToReachTheNetwork()
+	case strings.Contains(msg, "managed proxy") || strings.Contains(msg, "pluggable transport"):
+		d.bridgeFailed = true
+	case d.usingProxy && strings.Contains(msg, "proxy") && strings.Contains(msg, "authentication"):
+		d.proxyAuthFailed = true
+	case d.usingProxy && (strings.Contains(msg, "proxy client") || strings.Contains(msg, "proxy sent back")):
+		d.proxyRefused = true
+	}
+}
+
+func (d *torDiagnosis) failedToReachTheNetwork() {
+	if d.usingBridges {
+		d.bridgeFailed = true
+		return
+	}
+	if d.usingProxy {
+		d.proxyRefused = true
+		return
+	}
+	d.networkUnreachable = true
+}
+
+// errorOr returns the error that best explains the problems reported
+// by Tor, or the given error when Tor hasn't reported anything useful
+func (d *torDiagnosis) errorOr(def error) error {
+	d.Lock()
+	defer d.Unlock()
+
+	var err error
+
+	switch {
+	case d.clockSkew != nil:
+		err = d.clockSkew
+	case d.proxyAuthFailed:
+		err = ErrProxyAuthenticationFailed
+	case d.bridgeFailed:
+		err = ErrBridgeConnectionFailed
+	case d.proxyRefused:
+		err = ErrProxyRefused
+	case d.networkUnreachable:
+		err = ErrNetworkUnreachable
+	case d.directoryFailed:
+		err = ErrDirectoryFetchFailed
+	case d.dangerousVersion:
+		err = ErrDangerousTorVersion
+	default:
+		return def
+	}
+
+	log.WithFields(log.Fields{
+		"cause": err,
+	}).Debug("Tor couldn't connect to the Tor network")
+
+	return err
+}
+
+func argumentsOf(fields []string) map[string]string {
+	result := map[string]string{}
+	for _, f := range fields {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
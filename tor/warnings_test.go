@@ -0,0 +1,117 @@
+package tor
+
+import (
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *WahayTorSuite) Test_torDiagnosis_returnsTheDefaultErrorWhenNothingHasBeenReported(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_CLIENT NOTICE BOOTSTRAP PROGRESS=10 TAG=conn_done SUMMARY="Connected to a relay"`)
+	d.analyze(`STATUS_GENERAL NOTICE CLOCK_JUMPED TIME=120`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrTorConnectionTimeout)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsTheMeasuredClockSkew(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=10 TAG=conn_done SUMMARY="Connected to a relay" WARNING="Connection refused" REASON=CONNECTREFUSED`)
+	d.analyze(`STATUS_GENERAL WARN CLOCK_SKEW SKEW=-7200 SOURCE=OR:192.0.2.1:443`)
+
+	err := d.errorOr(ErrTorConnectionTimeout)
+
+	c.Assert(errors.Is(err, ErrClockSkew), Equals, true)
+
+	var skew *ClockSkewError
+	c.Assert(errors.As(err, &skew), Equals, true)
+	c.Assert(skew.Skew, Equals, -2*time.Hour)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsAnUnreachableNetwork(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=5 TAG=conn SUMMARY="Connecting to a relay" WARNING="No route to host" REASON=NOROUTE COUNT=3 RECOMMENDATION=warn`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrNetworkUnreachable)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_blamesTheBridgesWhenTheyAreUsed(c *C) {
+	d := newTorDiagnosis(true, false)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=5 TAG=conn SUMMARY="Connecting to a relay" WARNING="No route to host" REASON=NOROUTE COUNT=3 RECOMMENDATION=warn`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrBridgeConnectionFailed)
+
+	d = newTorDiagnosis(true, false)
+	d.analyze(`WARN Managed proxy "/usr/bin/lyrebird" process terminated with status code 1`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrBridgeConnectionFailed)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsDirectoryFetchFailures(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=50 TAG=loading_descriptors SUMMARY="Loading relay descriptors" WARNING="Directory unavailable" REASON=MISC`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrDirectoryFetchFailed)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsADangerousVersion(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_GENERAL WARN DANGEROUS_VERSION CURRENT=0.3.5.1 REASON=OBSOLETE RECOMMENDED="0.4.8.9"`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrDangerousTorVersion)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsAnUnrecommendedVersion(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_GENERAL WARN DANGEROUS_VERSION CURRENT=0.4.7.1 REASON=UNRECOMMENDED RECOMMENDED="0.4.8.9"`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrDangerousTorVersion)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_doesntReportAVersionNewerThanTheRecommendedOnes(c *C) {
+	d := newTorDiagnosis(false, false)
+	d.analyze(`STATUS_GENERAL WARN DANGEROUS_VERSION CURRENT=0.4.9.1-alpha REASON=NEW RECOMMENDED="0.4.8.9"`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrTorConnectionTimeout)
+}
+
+func (s *WahayTorSuite) Test_waitForBootstrap_explainsWhyTorStoppedBeforeConnecting(c *C) {
+	mockAll()
+	defer setDefaultFacades()
+
+	mocktorgof.onNewEventController = func(a string) (torgoEventController, error) {
+		return &mockTorgoEventController{
+			bootstrapPhase: `NOTICE BOOTSTRAP PROGRESS=0 TAG=starting SUMMARY="Starting"`,
+			events: []string{
+				`STATUS_GENERAL WARN CLOCK_SKEW SKEW=3600 SOURCE=CONSENSUS`,
+			},
+		}, nil
+	}
+
+	i := &instance{controlHost: "127.0.0.1", controlPort: 9051}
+
+	err := i.waitForBootstrap(nil, time.Second)
+
+	c.Assert(errors.Is(err, ErrClockSkew), Equals, true)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_blamesTheProxyWhenItIsUsed(c *C) {
+	d := newTorDiagnosis(false, true)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=1 TAG=conn_proxy SUMMARY="Connecting to proxy" WARNING="Connection refused" REASON=CONNECTREFUSED COUNT=1 RECOMMENDATION=warn`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrProxyRefused)
+
+	d = newTorDiagnosis(false, true)
+	d.analyze(`WARN Proxy Client: unable to connect OR connection (handshaking (proxy)) with 192.0.2.1:443 ID=<none> RSA_ID=AAAA ("general SOCKS server failure")`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrProxyRefused)
+}
+
+func (s *WahayTorSuite) Test_torDiagnosis_reportsTheProxyRejectingTheCredentials(c *C) {
+	d := newTorDiagnosis(false, true)
+	d.analyze(`WARN The https proxy sent back an unexpected status code 407 ("Proxy Authentication Required"). Closing.`)
+	d.analyze(`STATUS_CLIENT WARN BOOTSTRAP PROGRESS=1 TAG=conn_proxy SUMMARY="Connecting to proxy" WARNING="Connection reset" REASON=CONNECTRESET COUNT=1 RECOMMENDATION=warn`)
+
+	c.Assert(d.errorOr(ErrTorConnectionTimeout), Equals, ErrProxyAuthenticationFailed)
+}
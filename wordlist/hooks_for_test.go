@@ -0,0 +1,13 @@
+package wordlist
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type WordlistSuite struct{}
+
+var _ = Suite(&WordlistSuite{})
@@ -0,0 +1,42 @@
+// Package wordlist encodes bytes as human readable words, which are
+// easier to read aloud and to compare than hexadecimal strings
+package wordlist
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownWord is an error to return when a word is not part of the wordlist
+var ErrUnknownWord = errors.New("unknown word")
+
+var indexes = map[string]byte{}
+
+func init() {
+	for i, w := range words {
+		indexes[w] = byte(i)
+	}
+}
+
+// Encode returns one word for each one of the given bytes
+func Encode(bs []byte) []string {
+	result := make([]string, 0, len(bs))
+	for _, b := range bs {
+		result = append(result, words[b])
+	}
+	return result
+}
+
+// Decode returns the bytes represented by the given words.
+// The comparison of the words is case insensitive
+func Decode(ws []string) ([]byte, error) {
+	result := make([]byte, 0, len(ws))
+	for _, w := range ws {
+		b, ok := indexes[strings.ToLower(strings.TrimSpace(w))]
+		if !ok {
+			return nil, ErrUnknownWord
+		}
+		result = append(result, b)
+	}
+	return result, nil
+}
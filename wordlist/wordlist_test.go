@@ -0,0 +1,48 @@
+package wordlist
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *WordlistSuite) Test_words_areDistinctAndSimple(c *C) {
+	seen := map[string]bool{}
+	for _, w := range words {
+		c.Assert(seen[w], Equals, false, Commentf("duplicated word: %s", w))
+		c.Assert(w, Matches, "[a-z]+")
+		seen[w] = true
+	}
+	c.Assert(seen, HasLen, 256)
+}
+
+func (s *WordlistSuite) Test_Encode_returnsOneWordForEachByte(c *C) {
+	c.Assert(Encode([]byte{0, 1, 255}), DeepEquals, []string{"acorn", "agent", "violin"})
+	c.Assert(Encode(nil), HasLen, 0)
+}
+
+func (s *WordlistSuite) Test_Decode_returnsTheEncodedBytes(c *C) {
+	bs := make([]byte, 256)
+	for i := range bs {
+		bs[i] = byte(i)
+	}
+
+	result, err := Decode(Encode(bs))
+
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, bs)
+}
+
+func (s *WordlistSuite) Test_Decode_ignoresTheCaseOfTheWords(c *C) {
+	result, err := Decode(strings.Fields("Acorn AGENT violin"))
+
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, []byte{0, 1, 255})
+}
+
+func (s *WordlistSuite) Test_Decode_returnsAnErrorWhenAWordIsUnknown(c *C) {
+	result, err := Decode([]string{"acorn", "wahay"})
+
+	c.Assert(err, Equals, ErrUnknownWord)
+	c.Assert(result, IsNil)
+}
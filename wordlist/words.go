@@ -0,0 +1,38 @@
+package wordlist
+
+// words contains one word for every possible byte value. The order of the words
+// must never change, since it defines how the bytes are encoded
+var words = [256]string{
+	"acorn", "agent", "alarm", "album", "amber", "angle", "ankle", "apple",
+	"apron", "arena", "armor", "arrow", "atlas", "attic", "audio", "avoid",
+	"badge", "bagel", "baker", "bamboo", "banjo", "barn", "basil", "basin",
+	"beach", "beard", "bench", "berry", "bingo", "birch", "bison", "blade",
+	"blaze", "bloom", "board", "boat", "bonus", "boost", "brave", "bread",
+	"brick", "broom", "brush", "bucket", "buddy", "bugle", "cabin", "cable",
+	"cactus", "camel", "candy", "canoe", "canyon", "cargo", "carrot", "castle",
+	"cedar", "chair", "chalk", "charm", "cheese", "cherry", "chess", "chimney",
+	"cider", "cinema", "circus", "citrus", "clay", "cliff", "clock", "cloud",
+	"clover", "coach", "cobra", "cocoa", "comet", "coral", "cotton", "couch",
+	"crane", "crater", "crown", "cube", "curry", "daisy", "dance", "delta",
+	"denim", "desert", "dingo", "dolphin", "donkey", "dragon", "drama", "dream",
+	"drum", "eagle", "earth", "easel", "echo", "elbow", "ember", "engine",
+	"fabric", "falcon", "feast", "fence", "ferry", "fiber", "field", "flag",
+	"flame", "flute", "forest", "fossil", "frame", "frog", "fruit", "galaxy",
+	"garden", "garlic", "gecko", "giant", "ginger", "glass", "globe", "glove",
+	"goat", "grape", "gravel", "guitar", "hammer", "harbor", "harp", "hazel",
+	"heart", "hedge", "helmet", "hero", "hiking", "honey", "horse", "hotel",
+	"humor", "igloo", "ink", "island", "ivory", "jacket", "jaguar", "jelly",
+	"jewel", "judge", "juice", "jungle", "kayak", "kettle", "kiwi", "koala",
+	"ladder", "lagoon", "lamp", "laser", "lemon", "lily", "lion", "lizard",
+	"lobster", "locket", "lotus", "magnet", "mango", "maple", "marble", "meadow",
+	"melon", "mint", "mirror", "monkey", "moss", "motor", "muffin", "music",
+	"napkin", "nectar", "needle", "nest", "noodle", "nugget", "oasis", "ocean",
+	"olive", "onion", "opera", "orbit", "orange", "otter", "owl", "oyster",
+	"paddle", "panda", "paper", "parrot", "pastry", "peach", "pebble", "pencil",
+	"pepper", "piano", "pillow", "pilot", "planet", "plum", "pocket", "polar",
+	"pony", "puzzle", "quartz", "quilt", "rabbit", "radar", "radio", "raven",
+	"ribbon", "river", "robot", "rocket", "ruby", "saddle", "salad", "salmon",
+	"sand", "scarf", "shadow", "shell", "silver", "sketch", "spice", "spider",
+	"spoon", "squid", "stamp", "statue", "storm", "sugar", "summit", "sunset",
+	"swan", "tango", "tiger", "tomato", "tulip", "turtle", "velvet", "violin",
+}